@@ -15,13 +15,17 @@
 package cmd
 
 import (
+	"context"
 	"os"
+	"path/filepath"
 
 	fsutil "github.com/couchbase/tools-common/fs/util"
+	"github.com/couchbase/tools-common/sync/v2/hofp"
 	"github.com/jamesl33/cbtools-autobench/nodes"
 	"github.com/jamesl33/cbtools-autobench/report"
 	"github.com/jamesl33/cbtools-autobench/value"
 
+	"github.com/apex/log"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -29,19 +33,22 @@ import (
 // benchmarkOptions encapsulates the possible options which can be used to change the behavior of the 'benchmark'
 // sub-command.
 var benchmarkOptions = struct {
-	configPath string
-	logsPath   string
-	jsonOut    bool
+	configPath      string
+	logsPath        string
+	jsonOut         bool
+	statePath       string
+	resume          bool
+	existingArchive bool
 }{}
 
 // benchmarkCommand is the benchmark sub-command, used to benchmark the 'cbbackupmgr' tool by running multiple
 // backups/restores against an already provisioned cluster.
 var benchmarkCommand = &cobra.Command{
 	RunE:      benchmark,
-	Short:     "benchmark the cbbackupmgr tool performing either a backup or restore",
-	Use:       "benchmark {backup|restore}",
+	Short:     "benchmark the cbbackupmgr tool performing a backup, restore, info or remove",
+	Use:       "benchmark {backup|restore|restore-range|info|remove}",
 	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
-	ValidArgs: []string{"backup", "restore"},
+	ValidArgs: value.BenchmarkTypes,
 }
 
 // init the flags/arguments for the benchmark sub-command.
@@ -70,6 +77,30 @@ func init() {
 		"JSON format benchmarking report",
 	)
 
+	benchmarkCommand.Flags().StringVarP(
+		&benchmarkOptions.statePath,
+		"state",
+		"",
+		"",
+		"persist the results of each completed iteration to this file, allowing the run to be resumed",
+	)
+
+	benchmarkCommand.Flags().BoolVarP(
+		&benchmarkOptions.resume,
+		"resume",
+		"",
+		false,
+		"resume the run from the state file, skipping any completed iterations",
+	)
+
+	benchmarkCommand.Flags().BoolVarP(
+		&benchmarkOptions.existingArchive,
+		"existing-archive",
+		"",
+		false,
+		"restore the backups in the configured archive as is, rather than purging it and creating new backups",
+	)
+
 	markFlagRequired(benchmarkCommand, "config")
 }
 
@@ -84,58 +115,347 @@ func benchmark(_ *cobra.Command, args []string) error {
 		return errors.Wrap(err, "failed to read autobench config")
 	}
 
+	if benchmarkOptions.existingArchive {
+		config.BenchmarkConfig.ExistingArchive = true
+	}
+
+	state, err := openState(benchmarkOptions.statePath, benchmarkOptions.resume, config, args[0])
+	if err != nil {
+		return errors.Wrap(err, "failed to open state file")
+	}
+
+	report, err := runBenchmark(signalHandler(), config, args[0], benchmarkOptions.logsPath, state)
+	if err != nil {
+		return err
+	}
+
+	sinks, err := reportSinks(config.Sinks, benchmarkOptions.jsonOut)
+	if err != nil {
+		return errors.Wrap(err, "failed to create report sinks")
+	}
+
+	for _, sink := range sinks {
+		err = sink.Write(report)
+		if err != nil {
+			return errors.Wrap(err, "failed to write report")
+		}
+	}
+
+	return nil
+}
+
+// runBenchmark connects to the cluster/backup client described by the given config then runs one or more benchmarks
+// of the given type, returning the generated report. If the provided context is cancelled, the benchmarks will be
+// gracefully terminated after the current iteration. When a state file is provided, the result of each iteration is
+// persisted as it completes and any iterations which have already completed are skipped.
+func runBenchmark(ctx context.Context, config *value.AutobenchConfig, mode, logsPath string,
+	state *stateFile,
+) (*report.Report, error) {
 	cluster, err := nodes.NewCluster(config.SSHConfig, config.Blueprint.Cluster)
 	if err != nil {
-		return errors.Wrap(err, "failed to connect to cluster")
+		return nil, errors.Wrap(err, "failed to connect to cluster")
 	}
 	defer cluster.Close()
 
-	client, err := nodes.NewBackupClient(config.SSHConfig, config.Blueprint.BackupClient)
+	client, err := nodes.NewColocatedBackupClient(config.SSHConfig, config.Blueprint.BackupClient, cluster)
 	if err != nil {
-		return errors.Wrap(err, "failed to connect to backup client")
+		return nil, errors.Wrap(err, "failed to connect to backup client")
 	}
 	defer client.Close()
 
-	ctx := signalHandler()
+	var bandwidth []*value.Bandwidth
 
-	var results value.BenchmarkResults
+	if config.BenchmarkConfig.NetworkPreflight {
+		bandwidth, err = client.MeasureBandwidth(cluster)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to measure network bandwidth")
+		}
+	}
+
+	var latency []*value.Latency
+
+	if config.BenchmarkConfig.LatencyPreflight {
+		latency, err = client.MeasureLatency(cluster)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to measure network latency")
+		}
+	}
+
+	err = client.ValidateInstanceProfile(config.BenchmarkConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "instance profile preflight failed")
+	}
+
+	err = cluster.ApplyCompactionConcurrency()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to apply compaction concurrency")
+	}
+
+	caCert, err := client.PrepareClusterCA(config.BenchmarkConfig, cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare cluster CA")
+	}
 
-	switch args[0] {
-	case "backup":
-		results, err = client.BenchmarkBackup(ctx, config.BenchmarkConfig, cluster)
-	case "restore":
-		results, err = client.BenchmarkRestore(ctx, config.BenchmarkConfig, cluster)
+	// The uploaded CA is only used for this run, so is set on a copy of the config which isn't included in the report
+	benchmarkConfig := config.BenchmarkConfig
+	if caCert != "" {
+		copied := *benchmarkConfig
+		copied.CBMConfig = copied.CBMConfig.WithCACert(caCert)
+		benchmarkConfig = &copied
 	}
 
+	unmount, err := client.MountStagingTmpfs(benchmarkConfig)
 	if err != nil {
-		return errors.Wrap(err, "failed to run benchmark(s)")
+		return nil, errors.Wrap(err, "failed to mount staging tmpfs")
+	}
+	defer unmount()
+
+	compaction, restoreCompaction, err := cluster.ConfigureAutoCompaction()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to configure auto-compaction")
+	}
+	defer restoreCompaction()
+
+	err = deleteData(cluster, benchmarkConfig, mode, state)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete data")
+	}
+
+	// Each variant changes the compression mode when comparing compression modes, so it's reset to the blueprint's
+	if len(benchmarkConfig.CompareCompression) != 0 {
+		defer func() {
+			if err := cluster.ResetCompressionMode(); err != nil {
+				log.Warnf("Failed to reset bucket compression mode: %s", err)
+			}
+		}()
+	}
+
+	var results value.BenchmarkResults
+
+	for _, variant := range benchmarkConfig.Variants() {
+		variantResults, err := runVariant(ctx, client, cluster, variant, mode, logsPath, state)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to run benchmark(s)")
+		}
+
+		results = append(results, variantResults...)
+
+		// If the context has been cancelled, don't run any more variants; the user wants to gracefully terminate
+		if ctx.Err() != nil {
+			break
+		}
 	}
 
 	stats, err := cluster.Stats()
 	if err != nil {
-		return errors.Wrap(err, "failed to get cluster stats")
+		return nil, errors.Wrap(err, "failed to get cluster stats")
+	}
+
+	loadSummary, err := cluster.LoadSummary()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get data load summary")
 	}
 
-	clusterLogs, backupLogs, err := collectLogs(cluster, client, config.BenchmarkConfig, benchmarkOptions.logsPath)
+	volumes, err := describeVolumes(cluster, client)
 	if err != nil {
-		return errors.Wrap(err, "failed to collect logs")
+		return nil, errors.Wrap(err, "failed to describe volumes")
 	}
 
-	report := report.NewReport(report.Options{
-		Blueprint:   config.Blueprint,
-		Stats:       stats,
-		CBMConfig:   config.BenchmarkConfig.CBMConfig,
-		Results:     results,
-		ClusterLogs: clusterLogs,
-		BackupLogs:  backupLogs,
+	hardware := cluster.Hardware()
+	if described := client.Hardware(); described != nil {
+		hardware = append(hardware, described)
+	}
+
+	clusterLogs, backupLogs, err := collectLogs(cluster, client, benchmarkConfig, logsPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to collect logs")
+	}
+
+	return report.NewReport(report.Options{
+		Blueprint:      config.Blueprint,
+		Stats:          stats,
+		Load:           loadSummary,
+		CBMConfig:      config.BenchmarkConfig.CBMConfig,
+		Results:        results,
+		ClusterLogs:    clusterLogs,
+		BackupLogs:     backupLogs,
+		Bandwidth:      bandwidth,
+		Latency:        latency,
+		Volumes:        volumes,
+		AutoCompaction: compaction,
+		Hardware:       hardware,
+		Modes:          []string{mode},
+		Config:         config,
+	}), nil
+}
+
+// deleteData deletes the configured fraction of the dataset prior to running backup benchmarks. This is done once per
+// run (rather than for each variant) and recorded in the state file, so that resuming doesn't delete any more data.
+func deleteData(cluster *nodes.Cluster, config *value.BenchmarkConfig, mode string, state *stateFile) error {
+	if mode != value.BenchmarkBackup || config.Deletions == nil {
+		return nil
+	}
+
+	if state.deleted() {
+		log.Info("Data has already been deleted, skipping")
+		return nil
+	}
+
+	err := cluster.DeleteData(config.Deletions)
+	if err != nil {
+		return err
+	}
+
+	return state.markDeleted()
+}
+
+// runVariant runs one or more benchmarks of the given type using the config for the provided variant, the returned
+// results will be labelled with the name of the variant.
+func runVariant(ctx context.Context, client *nodes.BackupClient, cluster *nodes.Cluster,
+	variant *value.BenchmarkVariant, mode, logsPath string, state *stateFile,
+) (value.BenchmarkResults, error) {
+	completed, config, err := resumeVariant(variant, mode, state)
+	if err != nil {
+		return nil, err
+	}
+
+	if config == nil {
+		log.WithField("variant", variant.Name).Info("Benchmark variant already completed, skipping")
+		return completed, nil
+	}
+
+	if variant.Name != "" {
+		log.WithField("variant", variant.Name).Info("Beginning benchmark variant")
+	}
+
+	client.SetCheckpoint(func(result *value.BenchmarkResult) error {
+		result.Variant = variant.Name
+		result.Threads = variant.Config.CBMConfig.Threads
+
+		err := collectIterationLogs(client, variant.Config, logsPath, result)
+		if err != nil {
+			return errors.Wrap(err, "failed to collect iteration logs")
+		}
+
+		return state.record(result)
 	})
+	defer client.SetCheckpoint(nil)
+
+	var results value.BenchmarkResults
+
+	if variant.CompressionMode != "" {
+		err = cluster.SetCompressionMode(variant.CompressionMode)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to set bucket compression mode")
+		}
+	}
+
+	switch mode {
+	case value.BenchmarkBackup:
+		results, err = client.BenchmarkBackup(ctx, config, cluster)
+	case value.BenchmarkRestore:
+		results, err = client.BenchmarkRestore(ctx, config, cluster)
+	case value.BenchmarkRestoreRange:
+		results, err = client.BenchmarkRestoreRange(ctx, config, cluster)
+	case value.BenchmarkInfo:
+		results, err = client.BenchmarkInfo(ctx, config, cluster)
+	case value.BenchmarkRemove:
+		results, err = client.BenchmarkRemove(ctx, config, cluster)
+	default:
+		return nil, errors.Errorf("unknown/unsupported benchmark '%s'", mode)
+	}
 
-	err = report.Print(benchmarkOptions.jsonOut)
 	if err != nil {
-		return errors.Wrap(err, "failed to display report")
+		return nil, err
 	}
 
-	return nil
+	return append(completed, results...), nil
+}
+
+// resumeVariant returns the results of any iterations of the given variant which have already completed, along with
+// the config which should be used to run the remaining iterations; a nil config indicates that there's nothing left to
+// run.
+//
+// NOTE: Range restores can't be resumed part way through a variant, so any partial results are discarded and the
+// variant is run again from the beginning.
+func resumeVariant(variant *value.BenchmarkVariant, mode string,
+	state *stateFile,
+) (value.BenchmarkResults, *value.BenchmarkConfig, error) {
+	completed := state.completed(variant.Name)
+	if len(completed) == 0 {
+		return nil, variant.Config, nil
+	}
+
+	iterations := max(1, variant.Config.Iterations)
+
+	if mode == value.BenchmarkRestoreRange {
+		if len(completed) >= iterations*len(variant.Config.RestoreRange.Lengths) {
+			return completed, nil, nil
+		}
+
+		err := state.discard(variant.Name)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to update state file")
+		}
+
+		return nil, variant.Config, nil
+	}
+
+	if len(completed) >= iterations {
+		return completed, nil, nil
+	}
+
+	log.WithFields(log.Fields{"variant": variant.Name, "completed": len(completed)}).
+		Info("Resuming partially completed benchmark variant")
+
+	config := *variant.Config
+	config.Iterations = iterations - len(completed)
+
+	return completed, &config, nil
+}
+
+// describeVolumes returns the characteristics of the AWS EBS volumes described by the cluster/backup client blueprints.
+func describeVolumes(cluster *nodes.Cluster, client *nodes.BackupClient) ([]*value.Volume, error) {
+	clusterVolumes, err := cluster.Volumes()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe cluster volumes")
+	}
+
+	clientVolumes, err := client.Volumes()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe backup client volumes")
+	}
+
+	return append(clusterVolumes, clientVolumes...), nil
+}
+
+// reportSinks returns the sinks which the report should be written to, if none are configured the report will be
+// written to stdout.
+func reportSinks(configs []*value.SinkConfig, jsonOut bool) ([]report.Sink, error) {
+	if len(configs) == 0 {
+		return []report.Sink{&report.StdoutSink{JSON: jsonOut}}, nil
+	}
+
+	return report.NewSinks(configs)
+}
+
+// collectIterationLogs downloads the 'cbbackupmgr' logs for the given iteration into the 'iterations' directory within
+// the logs directory, nothing is collected unless enabled and a logs directory has been provided.
+func collectIterationLogs(client *nodes.BackupClient, config *value.BenchmarkConfig, path string,
+	result *value.BenchmarkResult,
+) error {
+	if path == "" || !config.IterationLogs {
+		return nil
+	}
+
+	path = filepath.Join(path, "iterations")
+
+	err := fsutil.Mkdir(path, 0, true, true)
+	if err != nil {
+		return errors.Wrap(err, "failed to create iteration logs directory")
+	}
+
+	return client.DownloadIterationLogs(config, filepath.Join(path, result.Start.Format("20060102T150405")+".tar.gz"))
 }
 
 // collectLogs will collect the logs from the cluster/backup archive, note if an empty path is provided the logs will
@@ -162,14 +482,32 @@ func collectLogs(cluster *nodes.Cluster, client *nodes.BackupClient, config *val
 		return nil, "", errors.Wrap(err, "failed to create logs output directory")
 	}
 
-	clusterLogs, err := cluster.CollectLogs(path)
-	if err != nil {
-		return nil, "", errors.Wrap(err, "failed to collect cluster logs")
-	}
+	var (
+		pool        = hofp.NewPool(hofp.Options{Size: 2})
+		clusterLogs []string
+		backupLogs  string
+	)
+
+	// The cluster/cbbackupmgr logs are independent, so are collected concurrently to reduce the time spent waiting
+	_ = pool.Queue(func(_ context.Context) error {
+		var err error
+
+		clusterLogs, err = cluster.CollectLogs(path, config.ClusterLogs)
+
+		return errors.Wrap(err, "failed to collect cluster logs")
+	})
+
+	_ = pool.Queue(func(_ context.Context) error {
+		var err error
+
+		backupLogs, err = client.CollectLogs(config, path)
+
+		return errors.Wrap(err, "failed to collect cbbackupmgr logs")
+	})
 
-	backupLogs, err := client.CollectLogs(config, path)
+	err = pool.Stop()
 	if err != nil {
-		return nil, "", errors.Wrap(err, "failed to collect cbbackupmgr logs")
+		return nil, "", err
 	}
 
 	return clusterLogs, backupLogs, nil
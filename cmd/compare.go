@@ -0,0 +1,95 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jamesl33/cbtools-autobench/report"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// compareOptions encapsulates the possible options which can be used to change the behavior of the 'compare'
+// sub-command.
+var compareOptions = struct {
+	jsonOut bool
+	strict  bool
+}{}
+
+// compareCommand is the compare sub-command, used to compare two previously generated JSON reports.
+var compareCommand = &cobra.Command{
+	RunE:  compare,
+	Short: "compare two JSON reports, flagging any differences between the environments they were run in",
+	Use:   "compare <baseline> <candidate>",
+	Args:  cobra.ExactArgs(2),
+}
+
+// init the flags/arguments for the compare sub-command.
+func init() {
+	compareCommand.Flags().BoolVarP(
+		&compareOptions.jsonOut,
+		"json",
+		"j",
+		false,
+		"JSON format comparison",
+	)
+
+	compareCommand.Flags().BoolVar(
+		&compareOptions.strict,
+		"strict",
+		false,
+		"exit with an error if the environments of the reports differ",
+	)
+}
+
+// compare sub-command, this will read the given JSON reports then print a comparison of their results, along with any
+// differences between their environments.
+func compare(_ *cobra.Command, args []string) error {
+	baseline, err := os.ReadFile(args[0])
+	if err != nil {
+		return errors.Wrap(err, "failed to read baseline report")
+	}
+
+	candidate, err := os.ReadFile(args[1])
+	if err != nil {
+		return errors.Wrap(err, "failed to read candidate report")
+	}
+
+	comparison, err := report.NewReportComparison(baseline, candidate)
+	if err != nil {
+		return errors.Wrap(err, "failed to compare reports")
+	}
+
+	if !compareOptions.jsonOut {
+		fmt.Printf("%s\n", comparison)
+	} else {
+		data, err := json.Marshal(comparison)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal comparison")
+		}
+
+		fmt.Printf("%s\n", data)
+	}
+
+	if compareOptions.strict && !comparison.Comparable() {
+		return errors.Errorf("the environments of the reports differ in %d field(s)", len(comparison.Environment))
+	}
+
+	return nil
+}
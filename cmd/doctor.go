@@ -0,0 +1,94 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jamesl33/cbtools-autobench/nodes"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// doctorOptions encapsulates the possible options which can be used to change the behavior of the 'doctor'
+// sub-command.
+var doctorOptions = struct {
+	configPath string
+	jsonOut    bool
+}{}
+
+// doctorCommand is the doctor sub-command, used to validate the configured hosts prior to provisioning/benchmarking.
+var doctorCommand = &cobra.Command{
+	RunE:  doctor,
+	Short: "run preflight checks against the configured cluster/backup client",
+	Use:   "doctor",
+	Args:  cobra.NoArgs,
+}
+
+// init the flags/arguments for the doctor sub-command.
+func init() {
+	doctorCommand.Flags().StringVarP(
+		&doctorOptions.configPath,
+		"config",
+		"c",
+		"",
+		"path to a cbtools-autobench config file",
+	)
+
+	doctorCommand.Flags().BoolVarP(
+		&doctorOptions.jsonOut,
+		"json",
+		"j",
+		false,
+		"JSON format check results",
+	)
+
+	markFlagRequired(doctorCommand, "config")
+}
+
+// doctor sub-command, this will check connectivity, root access, the platform, free space, object store credentials
+// and clock skew for every configured host then print a pass/fail table to stdout. Returns an error if any checks
+// failed.
+func doctor(_ *cobra.Command, _ []string) error {
+	config, err := readConfig(doctorOptions.configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read autobench config")
+	}
+
+	if config.Blueprint == nil {
+		return errors.New("config does not contain a blueprint")
+	}
+
+	checks := nodes.Diagnose(config.SSHConfig, config.Blueprint, config.BenchmarkConfig)
+
+	if !doctorOptions.jsonOut {
+		fmt.Printf("%s\n", checks)
+	} else {
+		data, err := json.Marshal(checks)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal check results")
+		}
+
+		fmt.Printf("%s\n", data)
+	}
+
+	if failed := checks.Failed(); failed != 0 {
+		return errors.Errorf("%d preflight check(s) failed", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jamesl33/cbtools-autobench/nodes"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// execOptions encapsulates the possible options which can be used to change the behavior of the 'exec' sub-command.
+var execOptions = struct {
+	configPath string
+	hosts      string
+}{}
+
+// execCommand is the exec sub-command, used to run ad-hoc commands on the configured hosts.
+var execCommand = &cobra.Command{
+	RunE:  execute,
+	Short: "run an ad-hoc command concurrently on the configured cluster nodes/backup client",
+	Use:   "exec [--hosts cluster|client|all] -- <command>",
+	Args:  cobra.MinimumNArgs(1),
+}
+
+// init the flags/arguments for the exec sub-command.
+func init() {
+	execCommand.Flags().StringVarP(
+		&execOptions.configPath,
+		"config",
+		"c",
+		"",
+		"path to a cbtools-autobench config file",
+	)
+
+	execCommand.Flags().StringVar(
+		&execOptions.hosts,
+		"hosts",
+		nodes.ExecHostsAll,
+		"the hosts to run the command on i.e. cluster/client/all",
+	)
+
+	markFlagRequired(execCommand, "config")
+}
+
+// execute sub-command, this will run the given command on each of the selected hosts then print the output from each
+// host to stdout. Returns an error if the command failed on any of the hosts.
+func execute(_ *cobra.Command, args []string) error {
+	config, err := readConfig(execOptions.configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read autobench config")
+	}
+
+	if config.Blueprint == nil {
+		return errors.New("config does not contain a blueprint")
+	}
+
+	results, err := nodes.Exec(config.SSHConfig, config.Blueprint, execOptions.hosts, strings.Join(args, " "))
+	if err != nil {
+		return errors.Wrap(err, "failed to run command")
+	}
+
+	var failed int
+
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+
+			fmt.Printf("==> %s (failed: %s) <==\n", result.Host, result.Err)
+		} else {
+			fmt.Printf("==> %s <==\n", result.Host)
+		}
+
+		if output := strings.TrimRight(string(result.Output), "\n"); output != "" {
+			fmt.Printf("%s\n", output)
+		}
+	}
+
+	if failed != 0 {
+		return errors.Errorf("command failed on %d/%d host(s)", failed, len(results))
+	}
+
+	return nil
+}
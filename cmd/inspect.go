@@ -0,0 +1,95 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jamesl33/cbtools-autobench/nodes"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// inspectOptions encapsulates the possible options which can be used to change the behavior of the 'inspect'
+// sub-command.
+var inspectOptions = struct {
+	configPath string
+	jsonOut    bool
+}{}
+
+// inspectCommand is the inspect sub-command, used to display the contents of the configured archive.
+var inspectCommand = &cobra.Command{
+	RunE:  inspect,
+	Short: "display the backups/sizes in the configured archive",
+	Use:   "inspect",
+	Args:  cobra.NoArgs,
+}
+
+// init the flags/arguments for the inspect sub-command.
+func init() {
+	inspectCommand.Flags().StringVarP(
+		&inspectOptions.configPath,
+		"config",
+		"c",
+		"",
+		"path to a cbtools-autobench config file",
+	)
+
+	inspectCommand.Flags().BoolVarP(
+		&inspectOptions.jsonOut,
+		"json",
+		"j",
+		false,
+		"JSON format archive summary",
+	)
+
+	markFlagRequired(inspectCommand, "config")
+}
+
+// inspect sub-command, this will run 'info' against the configured archive on the backup client then print a summary of
+// the repositories/backups it contains to stdout.
+func inspect(_ *cobra.Command, _ []string) error {
+	config, err := readConfig(inspectOptions.configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read autobench config")
+	}
+
+	client, err := nodes.NewBackupClient(config.SSHConfig, config.Blueprint.BackupClient)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to backup client")
+	}
+	defer client.Close()
+
+	summary, err := client.Inspect(config.BenchmarkConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to inspect archive")
+	}
+
+	if !inspectOptions.jsonOut {
+		fmt.Printf("%s\n", summary)
+		return nil
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal archive summary")
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jamesl33/cbtools-autobench/nodes"
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// loadOptions encapsulates the possible options which can be used to change the behavior of the 'load' sub-command.
+var loadOptions = struct {
+	configPath string
+	jsonOut    bool
+
+	// loader/threads override the values from the data blueprint, zero values use the config. The number/size of the
+	// items can't be overridden, since benchmarks use the data blueprint to describe the dataset.
+	loader  string
+	threads int
+
+	// compact compacts the bucket after loading the dataset, even if it's not enabled in the bucket blueprint.
+	compact bool
+}{}
+
+// loadCommand is the load sub-command, used to (re)load the test dataset into an already provisioned cluster.
+var loadCommand = &cobra.Command{
+	RunE:  load,
+	Short: "flush and load the test dataset into an already provisioned cluster",
+	Use:   "load",
+	Args:  cobra.NoArgs,
+}
+
+// init the flags/arguments for the load sub-command.
+func init() {
+	loadCommand.Flags().StringVarP(
+		&loadOptions.configPath,
+		"config",
+		"c",
+		"",
+		"path to a cbtools-autobench config file",
+	)
+
+	loadCommand.Flags().BoolVarP(
+		&loadOptions.jsonOut,
+		"json",
+		"j",
+		false,
+		"JSON format load summary",
+	)
+
+	loadCommand.Flags().StringVar(
+		&loadOptions.loader,
+		"loader",
+		"",
+		"the data loader to use i.e. cbbackupmgr/pillowfight, overriding the data blueprint",
+	)
+
+	loadCommand.Flags().IntVar(
+		&loadOptions.threads,
+		"threads",
+		0,
+		"the number of threads used by the data loader on each node, overriding the data blueprint",
+	)
+
+	loadCommand.Flags().BoolVar(
+		&loadOptions.compact,
+		"compact",
+		false,
+		"compact the bucket once the dataset has been loaded",
+	)
+
+	markFlagRequired(loadCommand, "config")
+}
+
+// load sub-command, this will flush the benchmarking bucket then load the test dataset (applying any overrides from the
+// command line) before printing a summary of the load.
+func load(_ *cobra.Command, _ []string) error {
+	config, err := readConfig(loadOptions.configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read autobench config")
+	}
+
+	config, err = loadConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "invalid overrides")
+	}
+
+	summary, err := runLoad(signalHandler(), config, config.Blueprint.Cluster.Bucket.Compact || loadOptions.compact, nil)
+	if err != nil {
+		return err
+	}
+
+	if !loadOptions.jsonOut {
+		fmt.Printf("%s\n", summary)
+		return nil
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal load summary")
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+// runLoad connects to the cluster described by the given config then flushes and loads the test dataset, optionally
+// compacting the bucket afterwards; this is also used by 'provision --load-only'.
+func runLoad(ctx context.Context, config *value.AutobenchConfig, compact bool,
+	state *stateFile,
+) (*value.LoadSummary, error) {
+	cluster, err := nodes.NewCluster(config.SSHConfig, config.Blueprint.Cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to cluster")
+	}
+	defer cluster.Close()
+
+	return loadDataset(ctx, cluster, compact, state)
+}
+
+// loadDataset flushes and loads the test dataset into the given cluster, recording that it's been loaded in the given
+// state. Cancelling the given context interrupts loading the dataset.
+func loadDataset(ctx context.Context, cluster *nodes.Cluster, compact bool,
+	state *stateFile,
+) (*value.LoadSummary, error) {
+	summary, err := cluster.LoadData(ctx, compact)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load test dataset")
+	}
+
+	err = state.markLoaded()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update state file")
+	}
+
+	return summary, nil
+}
+
+// loadConfig returns a copy of the given config, where the data blueprint has the overrides from the command line
+// applied.
+func loadConfig(config *value.AutobenchConfig) (*value.AutobenchConfig, error) {
+	var (
+		copied    = *config
+		blueprint = *config.Blueprint
+		cluster   = *config.Blueprint.Cluster
+		bucket    = *config.Blueprint.Cluster.Bucket
+		data      = *config.Blueprint.Cluster.Bucket.Data
+	)
+
+	if loadOptions.threads < 0 {
+		return nil, errors.New("threads must not be negative")
+	}
+
+	if loadOptions.threads != 0 {
+		data.LoadThreads = loadOptions.threads
+	}
+
+	switch value.DataLoaderType(loadOptions.loader) {
+	case "":
+	case value.CBM, value.Pillowfight:
+		data.DataLoader = value.DataLoaderType(loadOptions.loader)
+	default:
+		return nil, errors.Errorf("unknown data loader '%s', expected cbbackupmgr/pillowfight", loadOptions.loader)
+	}
+
+	bucket.Data = &data
+	cluster.Bucket = &bucket
+	blueprint.Cluster = &cluster
+	copied.Blueprint = &blueprint
+
+	return &copied, nil
+}
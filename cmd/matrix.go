@@ -0,0 +1,219 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/jamesl33/cbtools-autobench/nodes"
+	"github.com/jamesl33/cbtools-autobench/report"
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// matrixOptions encapsulates the possible options which can be used to change the behavior of the 'matrix'
+// sub-command.
+var matrixOptions = struct {
+	configPath string
+	jsonOut    bool
+}{}
+
+// matrixCommand is the matrix sub-command, used to provision/benchmark every combination of the cluster/backup client
+// versions described by the 'matrix' section of the config.
+var matrixCommand = &cobra.Command{
+	RunE:      matrix,
+	Short:     "benchmark every combination of cluster/cbbackupmgr versions, producing a compatibility matrix",
+	Use:       "matrix {backup|restore|restore-range|info|remove}",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: value.BenchmarkTypes,
+}
+
+// init the flags/arguments for the matrix sub-command.
+func init() {
+	matrixCommand.Flags().StringVarP(
+		&matrixOptions.configPath,
+		"config",
+		"c",
+		"",
+		"path to a cbtools-autobench config file",
+	)
+
+	matrixCommand.Flags().BoolVarP(
+		&matrixOptions.jsonOut,
+		"json",
+		"j",
+		false,
+		"JSON format benchmarking report",
+	)
+
+	markFlagRequired(matrixCommand, "config")
+}
+
+// matrix sub-command, this will provision/benchmark each cluster/backup client version pair then print a report
+// containing the compatibility/performance matrix.
+func matrix(_ *cobra.Command, args []string) error {
+	config, err := readConfig(matrixOptions.configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read autobench config")
+	}
+
+	if config.Matrix == nil || len(config.Matrix.ClusterPackages) == 0 || len(config.Matrix.BackupClientPackages) == 0 {
+		return errors.New("config does not contain a matrix with both cluster and backup client packages")
+	}
+
+	// The backup client is provisioned independently of the cluster for each pair, which isn't possible when they share a
+	// node since they must be running the same version.
+	if config.Blueprint.Colocated() != nil {
+		return errors.New("matrix benchmarks require a backup client which is not also a cluster node")
+	}
+
+	results := runMatrix(signalHandler(), config, args[0])
+
+	var all value.BenchmarkResults
+
+	for _, result := range results {
+		all = append(all, result.Results...)
+	}
+
+	sinks, err := reportSinks(config.Sinks, matrixOptions.jsonOut)
+	if err != nil {
+		return errors.Wrap(err, "failed to create report sinks")
+	}
+
+	report := report.NewReport(report.Options{
+		Blueprint: config.Blueprint,
+		CBMConfig: config.BenchmarkConfig.CBMConfig,
+		Results:   all,
+		Matrix:    results,
+		Modes:     []string{args[0]},
+		Config:    config,
+	})
+
+	for _, sink := range sinks {
+		err = sink.Write(report)
+		if err != nil {
+			return errors.Wrap(err, "failed to write report")
+		}
+	}
+
+	return nil
+}
+
+// runMatrix provisions/benchmarks each cluster/backup client version pair in turn. The cluster is only provisioned (and
+// the dataset loaded) once per cluster version, with only the backup client being provisioned for the remaining pairs.
+//
+// NOTE: Failing to provision/benchmark a pair isn't fatal, it's recorded in the matrix (it may indicate that the pair
+// is incompatible) and the remaining pairs are still run.
+func runMatrix(ctx context.Context, config *value.AutobenchConfig, mode string) []*value.MatrixResult {
+	var (
+		results    = make([]*value.MatrixResult, 0)
+		provisions = make(map[string]error)
+	)
+
+	for _, pair := range config.Matrix.Pairs() {
+		fields := log.Fields{"cluster": pair.ClusterVersion(), "cbbackupmgr": pair.BackupVersion()}
+		log.WithFields(fields).Info("Beginning compatibility matrix pair")
+
+		result := &value.MatrixResult{Pair: pair}
+		results = append(results, result)
+
+		pairConfig := matrixConfig(config, pair)
+
+		clusterErr, provisioned := provisions[pair.ClusterPackage]
+
+		var err error
+
+		switch {
+		case !provisioned:
+			_, err = runProvision(ctx, pairConfig, false, nil)
+			provisions[pair.ClusterPackage] = err
+		case clusterErr != nil:
+			err = clusterErr
+		default:
+			err = provisionBackupClient(pairConfig)
+		}
+
+		if err != nil {
+			log.WithFields(fields).Warnf("Failed to provision pair: %s", err)
+			result.Err = errors.Wrap(err, "failed to provision")
+
+			continue
+		}
+
+		report, err := runBenchmark(ctx, pairConfig, mode, "", nil)
+		if err != nil {
+			log.WithFields(fields).Warnf("Failed to benchmark pair: %s", err)
+			result.Err = errors.Wrap(err, "failed to benchmark")
+
+			continue
+		}
+
+		result.Results = report.Results()
+
+		for _, benchmark := range result.Results {
+			benchmark.Variant = joinVariant(pair.Name(), benchmark.Variant)
+		}
+
+		// If the context has been cancelled, don't run any more pairs; the user wants to gracefully terminate
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return results
+}
+
+// matrixConfig returns a copy of the given config, where the cluster/backup client blueprints install the packages from
+// the given pair.
+func matrixConfig(config *value.AutobenchConfig, pair *value.MatrixPair) *value.AutobenchConfig {
+	var (
+		copied       = *config
+		blueprint    = *config.Blueprint
+		cluster      = *config.Blueprint.Cluster
+		backupClient = *config.Blueprint.BackupClient
+	)
+
+	cluster.PackagePath = pair.ClusterPackage
+	backupClient.PackagePath = pair.BackupClientPackage
+
+	blueprint.Cluster = &cluster
+	blueprint.BackupClient = &backupClient
+	copied.Blueprint = &blueprint
+
+	return &copied
+}
+
+// provisionBackupClient provisions only the backup client described by the given config.
+func provisionBackupClient(config *value.AutobenchConfig) error {
+	client, err := nodes.NewBackupClient(config.SSHConfig, config.Blueprint.BackupClient)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to backup client")
+	}
+	defer client.Close()
+
+	return client.Provision()
+}
+
+// joinVariant prefixes the given variant name with the name of the matrix pair.
+func joinVariant(pair, variant string) string {
+	if variant == "" {
+		return pair
+	}
+
+	return pair + ", " + variant
+}
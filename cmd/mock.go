@@ -0,0 +1,33 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/jamesl33/cbtools-autobench/mock"
+	"github.com/jamesl33/cbtools-autobench/nodes"
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+)
+
+// useMock replaces the cluster/backup client with in-process fakes which record commands and return canned outputs,
+// allowing the full provisioning/benchmarking/reporting pipeline to be run without any real hosts (e.g. in CI).
+func useMock() {
+	log.Warn("Running in mock mode, no commands will be executed and all the results are fake")
+
+	nodes.SetConnector(func(_ *value.SSHConfig, blueprint *value.NodeBlueprint) (nodes.Executor, error) {
+		return mock.NewClient(blueprint.Host), nil
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+)
+
+// mockConfig is a minimal config describing a single node cluster and backup client, which only exist in the mock.
+const mockConfig = `ssh:
+  username: root
+  private_key: /dev/null
+blueprint:
+  cluster:
+    nodes:
+      - host: node0
+        services: [data]
+    package_path: /dev/null
+    bucket:
+      data:
+        items: 1000
+        size: 256
+      auto_compaction:
+        disabled: true
+  backup_client:
+    host: client
+    package_path: /dev/null
+benchmark:
+  iterations: 2
+  cbbackupmgr_config:
+    archive: /mnt/archive
+    repository: repo
+`
+
+// TestMockProvisionBenchmark runs the provisioning/benchmarking/reporting pipeline end-to-end against the mock.
+func TestMockProvisionBenchmark(t *testing.T) {
+	if testing.Short() {
+		t.Skip("provisioning waits for the (mock) cluster to start, skipping in short mode")
+	}
+
+	useMock()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(path, []byte(mockConfig), 0o600)
+	if err != nil {
+		t.Fatalf("Expected to be able to write config: %s", err)
+	}
+
+	config, err := readConfig(path)
+	if err != nil {
+		t.Fatalf("Expected to be able to read config: %s", err)
+	}
+
+	summary, err := runProvision(context.Background(), config, false, nil)
+	if err != nil {
+		t.Fatalf("Expected to be able to provision: %s", err)
+	}
+
+	if summary == nil || summary.Items == 0 {
+		t.Fatalf("Expected a non-empty data load summary, got %+v", summary)
+	}
+
+	report, err := runBenchmark(context.Background(), config, value.BenchmarkBackup, "", nil)
+	if err != nil {
+		t.Fatalf("Expected to be able to benchmark: %s", err)
+	}
+
+	results := report.Results()
+	if len(results) != config.BenchmarkConfig.Iterations {
+		t.Fatalf("Expected %d results, got %d", config.BenchmarkConfig.Iterations, len(results))
+	}
+
+	for i, result := range results {
+		if result.AIN == 0 || result.ADS == 0 {
+			t.Fatalf("Expected iteration %d to have backed up some data, got %+v", i+1, result)
+		}
+	}
+
+	if report.Overview == nil {
+		t.Fatalf("Expected the report to include an overview")
+	}
+
+	if len(report.Rundown) != len(results) {
+		t.Fatalf("Expected the rundown to include %d iterations, got %d", len(results), len(report.Rundown))
+	}
+
+	if len(report.Hardware) != 2 {
+		t.Fatalf("Expected the hardware of the node and backup client, got %d", len(report.Hardware))
+	}
+
+	if report.Compaction == nil {
+		t.Fatalf("Expected the report to include the auto-compaction settings")
+	}
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Expected to be able to marshal report: %s", err)
+	}
+
+	if !strings.Contains(string(encoded), `"rundown"`) {
+		t.Fatalf("Expected the JSON report to include the rundown, got %s", encoded)
+	}
+
+	if !strings.Contains(report.String(), "Overview") {
+		t.Fatalf("Expected the report to include the overview, got:\n%s", report.String())
+	}
+}
@@ -16,9 +16,12 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jamesl33/cbtools-autobench/nodes"
+	"github.com/jamesl33/cbtools-autobench/value"
 
+	"github.com/apex/log"
 	"github.com/couchbase/tools-common/sync/v2/hofp"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -32,6 +35,10 @@ var provisionOptions = struct {
 	// loadOnly skips actual provisioning i.e. just flush and load the test dataset; this is useful when benchmarking
 	// multiple datasets whilst using the same cluster.
 	loadOnly bool
+
+	// statePath/resume allow an interrupted provisioning run to be resumed, skipping the steps which have completed.
+	statePath string
+	resume    bool
 }{}
 
 // provisionCommand is the provision sub-command, used to provision a cluster and load a test dataset.
@@ -59,6 +66,22 @@ func init() {
 		"skip provisioning and only load benchmark dataset",
 	)
 
+	provisionCommand.Flags().StringVarP(
+		&provisionOptions.statePath,
+		"state",
+		"",
+		"",
+		"persist provisioning progress to this file, allowing it to be resumed",
+	)
+
+	provisionCommand.Flags().BoolVarP(
+		&provisionOptions.resume,
+		"resume",
+		"",
+		false,
+		"resume provisioning from the state file, skipping any completed steps",
+	)
+
 	markFlagRequired(provisionCommand, "config")
 }
 
@@ -70,15 +93,58 @@ func provision(_ *cobra.Command, _ []string) error {
 		return errors.Wrap(err, "failed to read autobench config")
 	}
 
+	state, err := openState(provisionOptions.statePath, provisionOptions.resume, config, "")
+	if err != nil {
+		return errors.Wrap(err, "failed to open state file")
+	}
+
+	summary, err := runProvision(signalHandler(), config, provisionOptions.loadOnly, state)
+	if err != nil {
+		return err
+	}
+
+	if summary != nil {
+		fmt.Printf("%s\n", summary)
+	}
+
+	return nil
+}
+
+// runProvision provisions the cluster/backup client described by the given config and loads the test dataset, when
+// 'loadOnly' is set provisioning is skipped. Any steps which have already been recorded as complete in the provided
+// state will also be skipped. Cancelling the given context interrupts loading the test dataset. Returns a summary of
+// the data load, which will be nil if it was skipped.
+func runProvision(ctx context.Context, config *value.AutobenchConfig, loadOnly bool,
+	state *stateFile,
+) (*value.LoadSummary, error) {
+	if state.provisioned() && state.loaded() {
+		log.Info("Provisioning already completed, skipping")
+		return nil, nil
+	}
+
+	if !config.Blueprint.Cluster.IsManaged() {
+		return nil, errors.New("unmanaged clusters can't be provisioned, they may only be benchmarked")
+	}
+
+	// Only loading the dataset is the same as using the 'load' sub-command, without any overrides
+	if loadOnly {
+		if state.loaded() {
+			log.Info("Test dataset already loaded, skipping")
+			return nil, nil
+		}
+
+		return runLoad(ctx, config, config.Blueprint.Cluster.Bucket.Compact, state)
+	}
+
 	cluster, err := nodes.NewCluster(config.SSHConfig, config.Blueprint.Cluster)
 	if err != nil {
-		return errors.Wrap(err, "failed to connect to cluster")
+		return nil, errors.Wrap(err, "failed to connect to cluster")
 	}
 	defer cluster.Close()
 
-	client, err := nodes.NewBackupClient(config.SSHConfig, config.Blueprint.BackupClient)
+	client, err := nodes.NewColocatedBackupClient(config.SSHConfig, config.Blueprint.BackupClient, cluster)
 	if err != nil {
-		return errors.Wrap(err, "failed to connect to backup client")
+		return nil, errors.Wrap(err, "failed to connect to backup client")
 	}
 	defer client.Close()
 
@@ -87,7 +153,7 @@ func provision(_ *cobra.Command, _ []string) error {
 	}
 
 	var provisioners []provisioner
-	if !provisionOptions.loadOnly {
+	if !state.provisioned() {
 		provisioners = []provisioner{cluster, client}
 	}
 
@@ -105,13 +171,13 @@ func provision(_ *cobra.Command, _ []string) error {
 
 	err = pool.Stop()
 	if err != nil {
-		return errors.Wrap(err, "unexpected error whilst provisioning")
+		return nil, errors.Wrap(err, "unexpected error whilst provisioning")
 	}
 
-	err = cluster.LoadData(config.Blueprint.Cluster.Bucket.Compact)
+	err = state.markProvisioned()
 	if err != nil {
-		return errors.Wrap(err, "failed to load test dataset")
+		return nil, errors.Wrap(err, "failed to update state file")
 	}
 
-	return nil
+	return loadDataset(ctx, cluster, config.Blueprint.Cluster.Bucket.Compact, state)
 }
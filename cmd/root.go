@@ -15,19 +15,45 @@
 package cmd
 
 import (
+	"fmt"
+
+	"github.com/jamesl33/cbtools-autobench/utilities"
+
 	"github.com/spf13/cobra"
 )
 
+// rootOptions encapsulates the options which are shared by all the sub-commands.
+var rootOptions = struct {
+	mock bool
+}{}
+
 // rootCommand represents the root cbtools-autobench command and encapsulates all the supported sub-commands.
 var rootCommand = &cobra.Command{
 	Short:         "An automatic benchmarking tool designed to benchmark Couchbase tools",
 	SilenceErrors: true,
 	SilenceUsage:  true,
+	PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		if rootOptions.mock {
+			useMock()
+		}
+	},
 }
 
 // init the root command by adding all the supported sub-commands.
 func init() {
-	rootCommand.AddCommand(provisionCommand, benchmarkCommand)
+	version, commit := utilities.BuildVersion()
+	rootCommand.Version = fmt.Sprintf("%s (%s)", version, commit)
+
+	rootCommand.PersistentFlags().BoolVar(
+		&rootOptions.mock,
+		"mock",
+		false,
+		"replace the cluster/backup client with in-process fakes, for running the pipeline without any real hosts",
+	)
+
+	rootCommand.AddCommand(provisionCommand, benchmarkCommand, serveCommand, scheduleCommand, matrixCommand,
+		scaleCommand, loadCommand, inspectCommand, compareCommand, schemaCommand, doctorCommand, statsCommand,
+		execCommand)
 }
 
 // Execute cbtools-autobench, returning any errors raised during the operation of the chosen sub-command.
@@ -0,0 +1,204 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/jamesl33/cbtools-autobench/nodes"
+	"github.com/jamesl33/cbtools-autobench/report"
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// scaleOptions encapsulates the possible options which can be used to change the behavior of the 'scale' sub-command.
+var scaleOptions = struct {
+	configPath string
+	jsonOut    bool
+}{}
+
+// scaleCommand is the scale sub-command, used to benchmark the same dataset against each of the cluster sizes described
+// by the 'node_scaling' section of the config.
+var scaleCommand = &cobra.Command{
+	RunE:      scale,
+	Short:     "benchmark the same dataset against clusters of different sizes, reporting how throughput scales",
+	Use:       "scale {backup|restore|restore-range|info|remove}",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: value.BenchmarkTypes,
+}
+
+// init the flags/arguments for the scale sub-command.
+func init() {
+	scaleCommand.Flags().StringVarP(
+		&scaleOptions.configPath,
+		"config",
+		"c",
+		"",
+		"path to a cbtools-autobench config file",
+	)
+
+	scaleCommand.Flags().BoolVarP(
+		&scaleOptions.jsonOut,
+		"json",
+		"j",
+		false,
+		"JSON format benchmarking report",
+	)
+
+	markFlagRequired(scaleCommand, "config")
+}
+
+// scale sub-command, this will provision the cluster then benchmark each cluster size in turn, before printing a report
+// showing how the throughput scales with the number of nodes.
+func scale(_ *cobra.Command, args []string) error {
+	config, err := readConfig(scaleOptions.configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read autobench config")
+	}
+
+	counts, err := config.NodeScaling.Counts(len(config.Blueprint.Cluster.Nodes))
+	if err != nil {
+		return errors.Wrap(err, "invalid node scaling config")
+	}
+
+	// Nodes are rebalanced out of the cluster between runs, which isn't possible when the backup client is one of them
+	if config.Blueprint.Colocated() != nil {
+		return errors.New("node scaling benchmarks require a backup client which is not also a cluster node")
+	}
+
+	results, err := runScale(signalHandler(), config, args[0], counts)
+	if err != nil {
+		return err
+	}
+
+	var all value.BenchmarkResults
+
+	for _, result := range results {
+		all = append(all, result.Results...)
+	}
+
+	sinks, err := reportSinks(config.Sinks, scaleOptions.jsonOut)
+	if err != nil {
+		return errors.Wrap(err, "failed to create report sinks")
+	}
+
+	report := report.NewReport(report.Options{
+		Blueprint:   config.Blueprint,
+		CBMConfig:   config.BenchmarkConfig.CBMConfig,
+		Results:     all,
+		NodeScaling: results,
+		Modes:       []string{args[0]},
+		Config:      config,
+	})
+
+	for _, sink := range sinks {
+		err = sink.Write(report)
+		if err != nil {
+			return errors.Wrap(err, "failed to write report")
+		}
+	}
+
+	return nil
+}
+
+// runScale provisions the cluster (loading the dataset once) then benchmarks each of the given cluster sizes, which
+// must be in descending order, rebalancing nodes out of the cluster between each size.
+//
+// NOTE: Failing to benchmark a cluster size isn't fatal, it's recorded in the report and the remaining sizes are still
+// run. Failing to rebalance is, since the remaining sizes can't be reached.
+func runScale(ctx context.Context, config *value.AutobenchConfig, mode string,
+	counts []int,
+) ([]*value.NodeScalingResult, error) {
+	_, err := runProvision(ctx, config, false, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to provision")
+	}
+
+	var (
+		results = make([]*value.NodeScalingResult, 0, len(counts))
+		current = len(config.Blueprint.Cluster.Nodes)
+	)
+
+	for _, count := range counts {
+		result := &value.NodeScalingResult{Nodes: count}
+		results = append(results, result)
+
+		log.WithField("nodes", count).Info("Beginning node scaling benchmark")
+
+		if count < current {
+			err = scaleCluster(scaleConfig(config, current), count)
+			if err != nil {
+				log.WithField("nodes", count).Warnf("Failed to scale cluster: %s", err)
+				result.Err = errors.Wrap(err, "failed to scale cluster")
+
+				return results, nil
+			}
+
+			current = count
+		}
+
+		report, err := runBenchmark(ctx, scaleConfig(config, count), mode, "", nil)
+		if err != nil {
+			log.WithField("nodes", count).Warnf("Failed to benchmark cluster size: %s", err)
+			result.Err = errors.Wrap(err, "failed to benchmark")
+
+			continue
+		}
+
+		result.Results = report.Results()
+
+		for _, benchmark := range result.Results {
+			benchmark.Variant = joinVariant(result.Name(), benchmark.Variant)
+		}
+
+		// If the context has been cancelled, don't run any more sizes; the user wants to gracefully terminate
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// scaleConfig returns a copy of the given config, where the cluster blueprint only contains the first 'count' nodes.
+func scaleConfig(config *value.AutobenchConfig, count int) *value.AutobenchConfig {
+	var (
+		copied    = *config
+		blueprint = *config.Blueprint
+		cluster   = *config.Blueprint.Cluster
+	)
+
+	cluster.Nodes = cluster.Nodes[:count]
+
+	blueprint.Cluster = &cluster
+	copied.Blueprint = &blueprint
+
+	return &copied
+}
+
+// scaleCluster connects to the cluster described by the given config and rebalances it down to the given number of
+// nodes.
+func scaleCluster(config *value.AutobenchConfig, count int) error {
+	cluster, err := nodes.NewCluster(config.SSHConfig, config.Blueprint.Cluster)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to cluster")
+	}
+	defer cluster.Close()
+
+	return cluster.Scale(count)
+}
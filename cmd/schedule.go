@@ -0,0 +1,186 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jamesl33/cbtools-autobench/nodes"
+	"github.com/jamesl33/cbtools-autobench/report"
+	"github.com/jamesl33/cbtools-autobench/schedule"
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// scheduleOptions encapsulates the possible options which can be used to change the behavior of the 'schedule'
+// sub-command.
+var scheduleOptions = struct {
+	configPath string
+	logsPath   string
+}{}
+
+// scheduleCommand is the schedule sub-command, used to run recurring benchmarks described by the 'schedule' section of
+// the config.
+var scheduleCommand = &cobra.Command{
+	RunE:  scheduleBenchmarks,
+	Short: "run benchmark suites on a recurring schedule",
+	Use:   "schedule",
+}
+
+// init the flags/arguments for the schedule sub-command.
+func init() {
+	scheduleCommand.Flags().StringVarP(
+		&scheduleOptions.configPath,
+		"config",
+		"c",
+		"",
+		"path to a cbtools-autobench config file",
+	)
+
+	scheduleCommand.Flags().StringVarP(
+		&scheduleOptions.logsPath,
+		"collect-logs",
+		"l",
+		"",
+		"collect cluster/cbbackupmgr logs after each run and download them into this directory",
+	)
+
+	markFlagRequired(scheduleCommand, "config")
+}
+
+// scheduleBenchmarks sub-command, this will run the scheduled benchmark suites until interrupted.
+func scheduleBenchmarks(_ *cobra.Command, _ []string) error {
+	config, err := readConfig(scheduleOptions.configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read autobench config")
+	}
+
+	if config.Schedule == nil {
+		return errors.New("config does not contain a schedule")
+	}
+
+	sinks, err := report.NewSinks(config.Sinks)
+	if err != nil {
+		return errors.Wrap(err, "failed to create report sinks")
+	}
+
+	scheduler, err := schedule.NewScheduler(schedule.Options{
+		Config: config.Schedule,
+		Run: func(ctx context.Context, suite *value.SuiteSchedule) (*report.Report, error) {
+			return runSuite(ctx, config, suite, scheduleOptions.logsPath)
+		},
+		Sinks: sinks,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create scheduler")
+	}
+
+	return scheduler.Run(signalHandler())
+}
+
+// runSuite runs each of the steps in the given suite in turn, returning a report containing the results of every
+// benchmark step (labelled using the name of the step). Suites without any steps run their benchmark directly.
+//
+// NOTE: Topology changes aren't reverted once the suite completes, suites which change the topology should restore it
+// in their final steps so that subsequent runs start from the topology described by the config.
+func runSuite(ctx context.Context, config *value.AutobenchConfig, suite *value.SuiteSchedule,
+	logsPath string,
+) (*report.Report, error) {
+	if len(suite.Steps) == 0 {
+		return runBenchmark(ctx, config, suite.Benchmark, logsPath, nil)
+	}
+
+	var (
+		results value.BenchmarkResults
+		modes   []string
+	)
+
+	for idx, step := range suite.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step=%d", idx+1)
+		}
+
+		if step.Topology != nil {
+			log.WithFields(log.Fields{"suite": suite.Name, "step": name}).Info("Changing cluster topology")
+
+			err := changeTopology(config, step.Topology)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to change topology for step '%s'", name)
+			}
+
+			config = topologyConfig(config, step.Topology)
+
+			continue
+		}
+
+		report, err := runBenchmark(ctx, config, step.Benchmark, logsPath, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to run benchmark for step '%s'", name)
+		}
+
+		modes = append(modes, step.Benchmark)
+
+		for _, result := range report.Results() {
+			result.Variant = joinVariant(name, result.Variant)
+			results = append(results, result)
+		}
+
+		// If the context has been cancelled, don't run any more steps; the user wants to gracefully terminate
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return report.NewReport(report.Options{
+		Blueprint: config.Blueprint,
+		CBMConfig: config.BenchmarkConfig.CBMConfig,
+		Results:   results,
+		Modes:     modes,
+		Config:    config,
+	}), nil
+}
+
+// changeTopology connects to the cluster described by the given config and applies the given topology change.
+func changeTopology(config *value.AutobenchConfig, change *value.TopologyChange) error {
+	cluster, err := nodes.NewCluster(config.SSHConfig, config.Blueprint.Cluster)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to cluster")
+	}
+	defer cluster.Close()
+
+	return cluster.ChangeTopology(config.SSHConfig, change)
+}
+
+// topologyConfig returns a copy of the given config, where the cluster blueprint describes the nodes in the cluster
+// once the given topology change has been applied.
+func topologyConfig(config *value.AutobenchConfig, change *value.TopologyChange) *value.AutobenchConfig {
+	var (
+		copied    = *config
+		blueprint = *config.Blueprint
+		cluster   = *config.Blueprint.Cluster
+	)
+
+	cluster.Nodes = change.Apply(cluster.Nodes)
+
+	blueprint.Cluster = &cluster
+	copied.Blueprint = &blueprint
+
+	return &copied
+}
@@ -0,0 +1,44 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamesl33/cbtools-autobench/report"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// schemaCommand is the schema sub-command, used to print the JSON schema which describes the JSON report.
+var schemaCommand = &cobra.Command{
+	RunE:  schema,
+	Short: "print the JSON schema describing the JSON benchmarking report",
+	Use:   "schema",
+	Args:  cobra.NoArgs,
+}
+
+// schema sub-command, this will print the JSON schema for the JSON report to stdout.
+func schema(_ *cobra.Command, _ []string) error {
+	encoded, err := report.Schema()
+	if err != nil {
+		return errors.Wrap(err, "failed to generate schema")
+	}
+
+	fmt.Printf("%s\n", encoded)
+
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/jamesl33/cbtools-autobench/report"
+	"github.com/jamesl33/cbtools-autobench/server"
+	"github.com/jamesl33/cbtools-autobench/utilities"
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+// serveOptions encapsulates the possible options which can be used to change the behavior of the 'serve' sub-command.
+var serveOptions = struct {
+	address  string
+	token    string
+	logsPath string
+}{}
+
+// serveCommand is the serve sub-command, used to run cbtools-autobench as a daemon which is driven using a REST API.
+var serveCommand = &cobra.Command{
+	RunE:  serve,
+	Short: "run as a daemon exposing a REST API which can be used to trigger provisioning/benchmarks",
+	Use:   "serve",
+}
+
+// init the flags/arguments for the serve sub-command.
+func init() {
+	serveCommand.Flags().StringVarP(
+		&serveOptions.address,
+		"address",
+		"a",
+		"127.0.0.1:8080",
+		"the address to listen on, the REST API may be used to run arbitrary configs so take care when exposing it",
+	)
+
+	serveCommand.Flags().StringVarP(
+		&serveOptions.token,
+		"token",
+		"t",
+		"",
+		"require requests to provide this bearer token, defaults to the value of 'AUTOBENCH_TOKEN'",
+	)
+
+	serveCommand.Flags().StringVarP(
+		&serveOptions.logsPath,
+		"collect-logs",
+		"l",
+		"",
+		"collect cluster/cbbackupmgr logs after each benchmark and download them into this directory",
+	)
+}
+
+// serve sub-command, this will run the REST API until interrupted.
+func serve(_ *cobra.Command, _ []string) error {
+	// The token is read from the environment by default, rather than using it as the flag default, to avoid it being
+	// displayed in the usage
+	if serveOptions.token == "" {
+		serveOptions.token = os.Getenv("AUTOBENCH_TOKEN")
+	}
+
+	srv := server.NewServer(server.Options{
+		Address: serveOptions.address,
+		Token:   serveOptions.token,
+		Provision: func(ctx context.Context, config *value.AutobenchConfig, loadOnly bool) error {
+			_, err := runProvision(ctx, config, loadOnly, nil)
+			return err
+		},
+		Benchmark: func(ctx context.Context, config *value.AutobenchConfig, mode string) (*report.Report, error) {
+			return runBenchmark(ctx, config, mode, serveOptions.logsPath, nil)
+		},
+	})
+
+	// Any logging should also be captured as the progress of the currently running job
+	log.SetHandler(utilities.NewWriterLoggingHandler(io.MultiWriter(os.Stdout, srv)))
+
+	return srv.Serve(signalHandler())
+}
@@ -0,0 +1,197 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+)
+
+// stateFile persists the state of a run to disk after each completed step/iteration, allowing an interrupted run to be
+// resumed. Note that a nil state file is valid, and will simply not persist anything.
+type stateFile struct {
+	path  string
+	state *value.RunState
+}
+
+// openState opens the state file at the given path. When resuming, the existing state will be read (if there is any)
+// and validated against the given config/mode; otherwise, a new state will be started. Returns nil when no path is
+// provided.
+func openState(path string, resume bool, config *value.AutobenchConfig, mode string) (*stateFile, error) {
+	if path == "" {
+		if resume {
+			return nil, errors.New("a state file must be provided to resume a run")
+		}
+
+		return nil, nil
+	}
+
+	_, hash, err := config.Canonical()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash config")
+	}
+
+	file := &stateFile{path: path, state: &value.RunState{ConfigHash: hash, Mode: mode}}
+
+	if !resume {
+		return file, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read state file")
+	}
+
+	var state *value.RunState
+
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode state file")
+	}
+
+	if state.ConfigHash != hash {
+		return nil, errors.New("config has changed since the state file was written, unable to resume")
+	}
+
+	if state.Mode != mode {
+		return nil, errors.Errorf("state file is for a '%s' run, unable to resume a '%s' run", state.Mode, mode)
+	}
+
+	log.WithFields(log.Fields{"path": path, "completed": len(state.Results)}).Info("Resuming run from state file")
+
+	file.state = state
+
+	return file, nil
+}
+
+// provisioned returns a boolean indicating whether provisioning has already been completed.
+func (s *stateFile) provisioned() bool {
+	return s != nil && s.state.Provisioned
+}
+
+// loaded returns a boolean indicating whether the dataset has already been loaded.
+func (s *stateFile) loaded() bool {
+	return s != nil && s.state.Loaded
+}
+
+// deleted returns a boolean indicating whether the configured fraction of the dataset has already been deleted.
+func (s *stateFile) deleted() bool {
+	return s != nil && s.state.Deleted
+}
+
+// markProvisioned records that provisioning has been completed.
+func (s *stateFile) markProvisioned() error {
+	if s == nil {
+		return nil
+	}
+
+	s.state.Provisioned = true
+
+	return s.save()
+}
+
+// markLoaded records that the dataset has been loaded.
+func (s *stateFile) markLoaded() error {
+	if s == nil {
+		return nil
+	}
+
+	s.state.Loaded = true
+
+	return s.save()
+}
+
+// markDeleted records that the configured fraction of the dataset has been deleted.
+func (s *stateFile) markDeleted() error {
+	if s == nil {
+		return nil
+	}
+
+	s.state.Deleted = true
+
+	return s.save()
+}
+
+// completed returns the results of the completed iterations for the given variant.
+func (s *stateFile) completed(variant string) value.BenchmarkResults {
+	if s == nil {
+		return nil
+	}
+
+	return s.state.Results.Variant(variant)
+}
+
+// record persists the result of a completed iteration.
+func (s *stateFile) record(result *value.BenchmarkResult) error {
+	if s == nil {
+		return nil
+	}
+
+	s.state.Results = append(s.state.Results, result)
+
+	return s.save()
+}
+
+// discard removes the results of any completed iterations for the given variant, used when a partially completed
+// variant can't be resumed part way through.
+func (s *stateFile) discard(variant string) error {
+	if s == nil {
+		return nil
+	}
+
+	results := make(value.BenchmarkResults, 0, len(s.state.Results))
+
+	for _, result := range s.state.Results {
+		if result.Variant != variant {
+			results = append(results, result)
+		}
+	}
+
+	s.state.Results = results
+
+	return s.save()
+}
+
+// save atomically writes the state to disk, by writing to a temporary file then renaming it.
+func (s *stateFile) save() error {
+	data, err := json.Marshal(s.state)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode state")
+	}
+
+	tmp := filepath.Join(filepath.Dir(s.path), "."+filepath.Base(s.path)+".tmp")
+
+	err = os.WriteFile(tmp, data, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write state file")
+	}
+
+	err = os.Rename(tmp, s.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to replace state file")
+	}
+
+	return nil
+}
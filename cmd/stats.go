@@ -0,0 +1,103 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jamesl33/cbtools-autobench/nodes"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// statsOptions encapsulates the possible options which can be used to change the behavior of the 'stats' sub-command.
+var statsOptions = struct {
+	configPath string
+	jsonOut    bool
+}{}
+
+// statsCommand is the stats sub-command, used to display the stats for the buckets in the configured cluster.
+var statsCommand = &cobra.Command{
+	RunE:  stats,
+	Short: "display the item counts/disk usage/memory usage/residency ratio of the buckets in the cluster",
+	Use:   "stats",
+	Args:  cobra.NoArgs,
+}
+
+// init the flags/arguments for the stats sub-command.
+func init() {
+	statsCommand.Flags().StringVarP(
+		&statsOptions.configPath,
+		"config",
+		"c",
+		"",
+		"path to a cbtools-autobench config file",
+	)
+
+	statsCommand.Flags().BoolVarP(
+		&statsOptions.jsonOut,
+		"json",
+		"j",
+		false,
+		"JSON format bucket stats",
+	)
+
+	markFlagRequired(statsCommand, "config")
+}
+
+// stats sub-command, this will fetch the stats for every bucket in the cluster (along with the breakdown for each node)
+// then print them to stdout.
+func stats(_ *cobra.Command, _ []string) error {
+	config, err := readConfig(statsOptions.configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read autobench config")
+	}
+
+	cluster, err := nodes.NewCluster(config.SSHConfig, config.Blueprint.Cluster)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to cluster")
+	}
+	defer cluster.Close()
+
+	// The REST API of an unmanaged cluster is accessed via the backup client, so it must be attached to the cluster
+	if !config.Blueprint.Cluster.IsManaged() {
+		client, err := nodes.NewColocatedBackupClient(config.SSHConfig, config.Blueprint.BackupClient, cluster)
+		if err != nil {
+			return errors.Wrap(err, "failed to connect to backup client")
+		}
+		defer client.Close()
+	}
+
+	bucketStats, err := cluster.BucketStats()
+	if err != nil {
+		return errors.Wrap(err, "failed to get bucket stats")
+	}
+
+	if !statsOptions.jsonOut {
+		fmt.Printf("%s\n", bucketStats)
+		return nil
+	}
+
+	data, err := json.Marshal(bucketStats)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal bucket stats")
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
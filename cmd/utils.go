@@ -47,5 +47,24 @@ func readConfig(path string) (*value.AutobenchConfig, error) {
 		return nil, errors.Wrap(err, "failed to decode config file")
 	}
 
+	if config.BenchmarkConfig != nil && config.BenchmarkConfig.CBMConfig != nil {
+		err = config.BenchmarkConfig.CBMConfig.Validate()
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cbbackupmgr config")
+		}
+
+		err = config.BenchmarkConfig.CBMConfig.ResolveCredentials()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve AWS credentials")
+		}
+	}
+
+	if config != nil {
+		err = config.Validate()
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid config")
+		}
+	}
+
 	return config, nil
 }
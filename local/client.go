@@ -0,0 +1,121 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+
+	fsutil "github.com/couchbase/tools-common/fs/util"
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+)
+
+// Client executes commands on the machine running 'cbtools-autobench', allowing benchmarking against a locally
+// installed server without running an ssh server.
+//
+// NOTE: Commands are run as the current user, provisioning will therefore require running as root.
+type Client struct {
+	platform value.Platform
+
+	// binDirectory is the Couchbase Server bin directory, which is added to the 'PATH' when executing commands.
+	binDirectory string
+}
+
+// NewClient creates a new client which executes commands on the local machine, commands are executed with the given
+// Couchbase Server bin directory in their 'PATH'.
+func NewClient(binDirectory string) (*Client, error) {
+	client := &Client{binDirectory: binDirectory}
+
+	distro, err := client.execute(value.CommandDistro.ToString(nil))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to determine distribution")
+	}
+
+	like, err := client.execute(value.CommandDistroLike.ToString(nil))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to determine parent distributions")
+	}
+
+	release, err := client.execute(value.CommandRelease.ToString(nil))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to determine version")
+	}
+
+	client.platform, err = value.NewPlatform(string(distro), string(like), string(release))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to determine platform")
+	}
+
+	log.WithField("platform", client.platform).Info("Using local execution")
+
+	return client, nil
+}
+
+// SecureUpload copies the file at the provided source path to the sink path, both are on the local machine.
+func (c *Client) SecureUpload(source, sink string) error {
+	log.WithFields(log.Fields{"source": source, "sink": sink}).Debug("Copying file")
+
+	return fsutil.CopyFile(source, sink)
+}
+
+// SecureDownload copies the file at the provided source path to the sink path, both are on the local machine.
+func (c *Client) SecureDownload(source, sink string) error {
+	log.WithFields(log.Fields{"source": source, "sink": sink}).Debug("Copying file")
+
+	return fsutil.CopyFile(source, sink)
+}
+
+// Platform returns the platform of the local machine.
+func (c *Client) Platform() value.Platform {
+	return c.platform
+}
+
+// ExecuteCommand executes the given command on the local machine.
+func (c *Client) ExecuteCommand(command value.Command) ([]byte, error) {
+	return c.execute(command.ToString(map[string]string{
+		"PATH": fmt.Sprintf("%s:$PATH", c.binDirectory),
+	}))
+}
+
+// Dial connects to the given address from the local machine.
+func (c *Client) Dial(network, address string) (net.Conn, error) {
+	return net.Dial(network, address)
+}
+
+// Close is a no-op, the local client doesn't hold any resources.
+func (c *Client) Close() error {
+	return nil
+}
+
+// execute runs the given command using 'bash' and returns the combined output.
+func (c *Client) execute(command string) ([]byte, error) {
+	log.WithField("command", command).Debug("Executing local command")
+
+	output, err := exec.Command("bash", "-c", command).CombinedOutput()
+	if err == nil {
+		return output, nil
+	}
+
+	if len(strings.TrimSpace(string(output))) != 0 {
+		log.Errorf("%s", output)
+	}
+
+	return nil, err
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// mockBackupSize is the size reported for each fake backup.
+const mockBackupSize = 512 * 1024 * 1024
+
+// mockItems is the number of items reported for each fake backup/bucket.
+const mockItems = 100_000
+
+// archives is the state of the fake archives, keyed by the archive path then repository name.
+type archives map[string]map[string][]*backup
+
+// backup is a fake backup, in the same format as output by 'cbbackupmgr info'.
+type backup struct {
+	Date    string    `json:"date"`
+	Type    string    `json:"type"`
+	Size    uint64    `json:"size"`
+	Buckets []*bucket `json:"buckets"`
+}
+
+// bucket is a fake backed up bucket, in the same format as output by 'cbbackupmgr info'.
+type bucket struct {
+	Name  string `json:"name"`
+	Size  uint64 `json:"size"`
+	Items uint64 `json:"total_mutations"`
+}
+
+// repository is a fake repository, in the same format as output by 'cbbackupmgr info'.
+type repository struct {
+	Name    string    `json:"name"`
+	Size    uint64    `json:"size"`
+	Backups []*backup `json:"backups"`
+}
+
+// config creates a new empty repository.
+func (a archives) config(archive, name string) error {
+	if _, ok := a[archive][name]; ok {
+		return errors.Errorf("repository '%s' already exists", name)
+	}
+
+	if a[archive] == nil {
+		a[archive] = make(map[string][]*backup)
+	}
+
+	a[archive][name] = make([]*backup, 0)
+
+	return nil
+}
+
+// backup creates a new backup in the given repository, the first backup is full with the remainder being incremental.
+func (a archives) backup(archive, name string) error {
+	backups, ok := a[archive][name]
+	if !ok {
+		return errors.Errorf("repository '%s' does not exist", name)
+	}
+
+	kind := "INCR"
+	if len(backups) == 0 {
+		kind = "FULL"
+	}
+
+	a[archive][name] = append(backups, &backup{
+		Date:    time.Now().UTC().Format("2006-01-02T15_04_05.000000000") + "+00_00",
+		Type:    kind,
+		Size:    mockBackupSize,
+		Buckets: []*bucket{{Name: "default", Size: mockBackupSize, Items: mockItems}},
+	})
+
+	return nil
+}
+
+// remove removes the backups within the given (inclusive) range of dates from the repository.
+func (a archives) remove(archive, name, start, end string) error {
+	backups, ok := a[archive][name]
+	if !ok {
+		return errors.Errorf("repository '%s' does not exist", name)
+	}
+
+	a[archive][name] = slices.DeleteFunc(backups, func(b *backup) bool { return b.Date >= start && b.Date <= end })
+
+	return nil
+}
+
+// info returns the 'info' output for the given repository.
+func (a archives) info(archive, name string) ([]byte, error) {
+	backups, ok := a[archive][name]
+	if !ok {
+		return nil, errors.Errorf("repository '%s' does not exist", name)
+	}
+
+	return json.Marshal(newRepository(name, backups))
+}
+
+// archiveInfo returns the 'info' output for the given archive.
+func (a archives) archiveInfo(archive string) ([]byte, error) {
+	type overlay struct {
+		Repositories []*repository `json:"repos"`
+	}
+
+	decoded := overlay{Repositories: make([]*repository, 0, len(a[archive]))}
+
+	for name, backups := range a[archive] {
+		decoded.Repositories = append(decoded.Repositories, newRepository(name, backups))
+	}
+
+	sort.Slice(decoded.Repositories, func(i, j int) bool {
+		return decoded.Repositories[i].Name < decoded.Repositories[j].Name
+	})
+
+	return json.Marshal(decoded)
+}
+
+// newRepository returns the 'info' output for a repository containing the given backups.
+func newRepository(name string, backups []*backup) *repository {
+	repository := &repository{Name: name, Backups: backups}
+
+	for _, backup := range backups {
+		repository.Size += backup.Size
+	}
+
+	return repository
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+)
+
+// Handler returns the canned output for a command, it's provided with the submatches of the pattern it was registered
+// with.
+type Handler func(matches []string) ([]byte, error)
+
+// rule is a handler along with the pattern of the commands it handles.
+type rule struct {
+	pattern *regexp.Regexp
+	handler Handler
+}
+
+// Client is an in-process fake which records the commands it's asked to execute and returns canned outputs, allowing
+// the provisioning/benchmarking/reporting pipeline to be exercised without any real hosts. A fake archive is maintained
+// so that 'cbbackupmgr' behaves consistently (e.g. created backups are listed by 'info').
+//
+// NOTE: Commands which aren't matched by any handler succeed without producing any output.
+type Client struct {
+	host     string
+	lock     sync.Mutex
+	commands []string
+	rules    []rule
+	archives archives
+	manifest manifest
+	cpu      uint64
+
+	// server is the in-process REST server which connections are dialed to, see 'Dial'.
+	server     *server
+	serverOnce sync.Once
+}
+
+// NewClient creates a new fake client for the given host, with handlers for the commands run when benchmarking.
+func NewClient(host string) *Client {
+	client := &Client{host: host, archives: make(archives)}
+
+	client.handleDefaults()
+
+	return client
+}
+
+// Handle registers a handler for commands matching the given regular expression. Handlers are matched in the reverse
+// order that they're registered, so may be used to override the default handlers.
+func (c *Client) Handle(pattern string, handler Handler) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.rules = append(c.rules, rule{pattern: regexp.MustCompile(pattern), handler: handler})
+}
+
+// Commands returns the commands which have been executed (including file transfers) in the order they were run.
+func (c *Client) Commands() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append([]string(nil), c.commands...)
+}
+
+// Platform returns the platform of the fake machine, which is always Ubuntu.
+func (c *Client) Platform() value.Platform {
+	return value.PlatformUbuntu20_04
+}
+
+// ExecuteCommand records the given command then returns the output of the most recently registered matching handler.
+func (c *Client) ExecuteCommand(command value.Command) ([]byte, error) {
+	rendered := command.ToString(nil)
+
+	log.WithFields(log.Fields{"host": c.host, "command": rendered}).Debug("Executing mock command")
+
+	return c.handle(rendered)
+}
+
+// handle records the given command (or request) then returns the output of the most recently registered matching
+// handler.
+func (c *Client) handle(rendered string) ([]byte, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.commands = append(c.commands, rendered)
+
+	for idx := len(c.rules) - 1; idx >= 0; idx-- {
+		matches := c.rules[idx].pattern.FindStringSubmatch(rendered)
+		if matches != nil {
+			return c.rules[idx].handler(matches)
+		}
+	}
+
+	return nil, nil
+}
+
+// SecureUpload records the upload, the source file is not read.
+func (c *Client) SecureUpload(source, sink string) error {
+	c.record(fmt.Sprintf("upload %s %s", source, sink))
+	return nil
+}
+
+// SecureDownload records the download and creates an empty file at the sink path, so that downloaded logs exist.
+func (c *Client) SecureDownload(source, sink string) error {
+	c.record(fmt.Sprintf("download %s %s", source, sink))
+	return os.WriteFile(sink, nil, 0o644)
+}
+
+// Close stops the in-process REST server, if it was started.
+func (c *Client) Close() error {
+	if c.server == nil {
+		return nil
+	}
+
+	return c.server.Close()
+}
+
+// record appends the given operation to the list of executed commands.
+func (c *Client) record(operation string) {
+	log.WithFields(log.Fields{"host": c.host, "operation": operation}).Debug("Recording mock operation")
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.commands = append(c.commands, operation)
+}
@@ -0,0 +1,227 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// mockFreeSpace is the free space in bytes reported for every filesystem.
+const mockFreeSpace = 1024 * 1024 * 1024 * 1024
+
+// bucketStats is the subset of the basic stats for a bucket returned by ns_server which are faked.
+type bucketStats struct {
+	ItemCount uint64 `json:"itemCount"`
+	DiskUsed  uint64 `json:"diskUsed"`
+	MemUsed   uint64 `json:"memUsed"`
+}
+
+// bucketNode is the subset of the per-node stats for a bucket returned by ns_server which are faked.
+type bucketNode struct {
+	Hostname         string `json:"hostname"`
+	InterestingStats struct {
+		CurrItems uint64 `json:"curr_items"`
+		MemUsed   uint64 `json:"mem_used"`
+		DiskUsed  uint64 `json:"couch_docs_actual_disk_size"`
+	} `json:"interestingStats"`
+}
+
+// bucketInfo is the subset of the information about a bucket returned by ns_server which is faked.
+type bucketInfo struct {
+	Name       string      `json:"name"`
+	BasicStats bucketStats `json:"basicStats"`
+	Quota      struct {
+		RAM uint64 `json:"ram"`
+	} `json:"quota"`
+	VBucketServerMap struct {
+		VBucketMap [][]int `json:"vBucketMap"`
+	} `json:"vBucketServerMap"`
+	Nodes []*bucketNode `json:"nodes"`
+}
+
+// newBucketInfo returns the fake information for the bucket with the given name, which is hosted on a single node.
+func newBucketInfo(name, host string) *bucketInfo {
+	info := &bucketInfo{
+		Name:       name,
+		BasicStats: bucketStats{ItemCount: mockItems, DiskUsed: mockBackupSize, MemUsed: mockBackupSize},
+		Nodes:      []*bucketNode{{Hostname: host + ":8091"}},
+	}
+
+	info.Quota.RAM = 2 * mockBackupSize
+	info.VBucketServerMap.VBucketMap = make([][]int, 1024)
+
+	for idx := range info.VBucketServerMap.VBucketMap {
+		info.VBucketServerMap.VBucketMap[idx] = []int{0}
+	}
+
+	info.Nodes[0].InterestingStats.CurrItems = mockItems
+	info.Nodes[0].InterestingStats.MemUsed = mockBackupSize
+	info.Nodes[0].InterestingStats.DiskUsed = mockBackupSize
+
+	return info
+}
+
+// handleDefaults registers the default handlers, these return output in the same format as the real commands so that
+// it may be parsed when benchmarking.
+//
+// NOTE: Handlers are run whilst holding the client lock, so they may safely modify the fake state.
+func (c *Client) handleDefaults() {
+	c.handleArchive()
+	c.handleCluster()
+	c.handleMachine()
+}
+
+// handleArchive registers handlers for the 'cbbackupmgr' sub-commands which interact with the archive.
+func (c *Client) handleArchive() {
+	c.Handle(`cbbackupmgr config -a (\S+) -r (\S+)`, func(matches []string) ([]byte, error) {
+		return nil, c.archives.config(matches[1], matches[2])
+	})
+
+	c.Handle(`cbbackupmgr backup -a (\S+) -r (\S+)`, func(matches []string) ([]byte, error) {
+		return nil, c.archives.backup(matches[1], matches[2])
+	})
+
+	c.Handle(`cbbackupmgr remove -a (\S+) -r (\S+) --backups ([^,\s]+),(\S+)`, func(matches []string) ([]byte, error) {
+		return nil, c.archives.remove(matches[1], matches[2], matches[3], matches[4])
+	})
+
+	c.Handle(`cbbackupmgr info -a (\S+) -j`, func(matches []string) ([]byte, error) {
+		return c.archives.archiveInfo(matches[1])
+	})
+
+	c.Handle(`cbbackupmgr info -a (\S+) -r (\S+) -j`, func(matches []string) ([]byte, error) {
+		return c.archives.info(matches[1], matches[2])
+	})
+
+	// The repository metadata contains no settings, so is always compatible with the configuration
+	c.Handle(`cat (\S+)/(\S+)/backup-meta\.json`, func(matches []string) ([]byte, error) {
+		if _, ok := c.archives[matches[1]][matches[2]]; !ok {
+			return nil, errors.Errorf("repository '%s' does not exist", matches[2])
+		}
+
+		return []byte("{}"), nil
+	})
+
+	c.Handle(`^rm -rf (\S+)$`, func(matches []string) ([]byte, error) {
+		delete(c.archives, matches[1])
+		return nil, nil
+	})
+
+	c.Handle(`ls -t (\S+)/\*\.zip`, func(matches []string) ([]byte, error) {
+		return []byte(matches[1] + "/collectinfo-cbbackupmgr-mock.zip\n"), nil
+	})
+}
+
+// handleCluster registers handlers for the commands/REST requests used to inspect the cluster.
+func (c *Client) handleCluster() {
+	c.Handle(`cbstats .* -j`, func(_ []string) ([]byte, error) {
+		return []byte("{}"), nil
+	})
+
+	c.Handle(`^GET /pools/default/buckets$`, func(_ []string) ([]byte, error) {
+		return json.Marshal([]*bucketInfo{newBucketInfo("default", c.host)})
+	})
+
+	c.Handle(`^GET /pools/default/buckets/([^/\s]+)`, func(matches []string) ([]byte, error) {
+		return json.Marshal(newBucketInfo(matches[1], c.host))
+	})
+
+	// Collections are only tracked for the benchmarking bucket, so the manifest is shared between every bucket
+	c.Handle(`^GET /pools/default/buckets/\S+/scopes$`, func(_ []string) ([]byte, error) {
+		return c.manifest.marshal()
+	})
+
+	c.Handle(`^POST /pools/default/buckets/\S+/scopes name=(\S+)$`, func(matches []string) ([]byte, error) {
+		c.manifest.createScope(matches[1])
+		return nil, nil
+	})
+
+	c.Handle(`^POST /pools/default/buckets/\S+/scopes/(\S+)/collections name=(\S+)$`,
+		func(matches []string) ([]byte, error) {
+			c.manifest.createCollection(matches[1], matches[2])
+			return nil, nil
+		})
+
+	c.Handle(`^GET /pools/default/trustedCAs$`, func(_ []string) ([]byte, error) {
+		return []byte(`[{"id":0,"pem":"-----BEGIN CERTIFICATE-----\nmock\n-----END CERTIFICATE-----\n"}]`), nil
+	})
+
+	c.Handle(`^GET /settings/autoCompaction$`, func(_ []string) ([]byte, error) {
+		return []byte(`{"autoCompactionSettings":{"parallelDBAndViewCompaction":false,` +
+			`"databaseFragmentationThreshold":{"percentage":30,"size":"undefined"},` +
+			`"viewFragmentationThreshold":{"percentage":30,"size":"undefined"},"indexCompactionMode":"circular",` +
+			`"indexFragmentationThreshold":{"percentage":30}},"purgeInterval":3}`), nil
+	})
+
+	c.Handle(`^GET /pools/default/settings/memcached/global$`, func(_ []string) ([]byte, error) {
+		return []byte(`{"num_writer_threads":"default","num_reader_threads":"default"}`), nil
+	})
+
+	// Rebalances complete instantly, so each request reports a new rebalance report
+	c.Handle(`^GET /pools/default/tasks`, func(_ []string) ([]byte, error) {
+		return []byte(fmt.Sprintf(`[{"type":"rebalance","status":"notRunning",`+
+			`"lastReportURI":"/logs/rebalanceReport?reportID=%d"}]`, time.Now().UnixNano())), nil
+	})
+
+	// The restored documents always match those which were recorded when verifying integrity
+	c.Handle(`comm -3`, func(_ []string) ([]byte, error) {
+		return []byte(fmt.Sprintf("%[1]d\n%[1]d\n0\n", mockItems)), nil
+	})
+
+	c.Handle(`collect-logs-status .*'path :'`, func(_ []string) ([]byte, error) {
+		return []byte("/opt/couchbase/var/lib/couchbase/tmp/collectinfo-mock.zip\n"), nil
+	})
+}
+
+// handleMachine registers handlers for the commands used to inspect the machine itself.
+func (c *Client) handleMachine() {
+	c.Handle(`du -sb`, func(_ []string) ([]byte, error) {
+		return []byte(fmt.Sprintf("%d\n", mockBackupSize)), nil
+	})
+
+	c.Handle(`find .* \| wc -l`, func(_ []string) ([]byte, error) {
+		return []byte("64\n"), nil
+	})
+
+	c.Handle(`df --output=pcent`, func(_ []string) ([]byte, error) {
+		return []byte("10\n"), nil
+	})
+
+	c.Handle(`df --output=avail`, func(_ []string) ([]byte, error) {
+		return []byte(fmt.Sprintf("%d\n", mockFreeSpace)), nil
+	})
+
+	c.Handle(`^nproc; .*/proc/meminfo`, func(_ []string) ([]byte, error) {
+		return []byte("4\n16384000\nMock Linux\n"), nil
+	})
+
+	c.Handle(`^id -u$`, func(_ []string) ([]byte, error) {
+		return []byte("0\n"), nil
+	})
+
+	c.Handle(`^date \+%s%N$`, func(_ []string) ([]byte, error) {
+		return []byte(fmt.Sprintf("%d\n", time.Now().UnixNano())), nil
+	})
+
+	// Each measurement reports an additional second of CPU time, so that the CPU time used by benchmarks is non-zero
+	c.Handle(`getconf CLK_TCK`, func(_ []string) ([]byte, error) {
+		c.cpu += 100
+		return []byte(fmt.Sprintf("100\ncpu %d 0 0 0 0 0 0 0 0 0\n", c.cpu)), nil
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// manifest is the fake collection manifest of the benchmarking bucket, in the same format as returned by the
+// '/pools/default/buckets/<bucket>/scopes' endpoint.
+type manifest struct {
+	Scopes []*scope `json:"scopes"`
+
+	// uid is the UID of the most recently created scope/collection, UIDs are assigned in creation order.
+	uid uint64
+}
+
+// scope is a fake scope in the collection manifest.
+type scope struct {
+	Name        string        `json:"name"`
+	UID         string        `json:"uid"`
+	Collections []*collection `json:"collections"`
+}
+
+// collection is a fake collection in the collection manifest.
+type collection struct {
+	Name string `json:"name"`
+	UID  string `json:"uid"`
+}
+
+// createScope adds a new empty scope with the given name.
+func (m *manifest) createScope(name string) {
+	m.Scopes = append(m.Scopes, &scope{Name: name, UID: m.next(), Collections: make([]*collection, 0)})
+}
+
+// createCollection adds a new collection with the given name to the given scope, the scope is created if it doesn't
+// already exist.
+func (m *manifest) createCollection(scopeName, name string) {
+	for _, scope := range m.Scopes {
+		if scope.Name == scopeName {
+			scope.Collections = append(scope.Collections, &collection{Name: name, UID: m.next()})
+			return
+		}
+	}
+
+	m.createScope(scopeName)
+	m.createCollection(scopeName, name)
+}
+
+// next returns the UID for a newly created scope/collection, UIDs are hex encoded and the first is 8 (as they are in
+// Couchbase Server, where the lower UIDs are reserved).
+func (m *manifest) next() string {
+	m.uid++
+	return strconv.FormatUint(m.uid+7, 16)
+}
+
+// marshal returns the JSON representation of the manifest.
+func (m *manifest) marshal() ([]byte, error) {
+	return json.Marshal(m)
+}
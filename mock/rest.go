@@ -0,0 +1,116 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+)
+
+// Dial returns a connection to an in-process REST server, which records each request as '<method> <uri> <body>' then
+// responds with the output of the most recently registered matching handler (a handler error results in a 500).
+func (c *Client) Dial(_, _ string) (net.Conn, error) {
+	c.serverOnce.Do(func() { c.server = newServer(http.HandlerFunc(c.serveHTTP)) })
+
+	return c.server.dial()
+}
+
+// serveHTTP handles a request made to the in-process REST server.
+func (c *Client) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rendered := strings.TrimSpace(r.Method + " " + r.URL.RequestURI() + " " + string(body))
+
+	log.WithFields(log.Fields{"host": c.host, "request": rendered}).Debug("Handling mock request")
+
+	output, err := c.handle(rendered)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, _ = w.Write(output)
+}
+
+// server is an in-process HTTP server, which is connected to using in-memory pipes rather than the network.
+type server struct {
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+// newServer starts serving the given handler, connections should be created using 'dial'.
+func newServer(handler http.Handler) *server {
+	s := &server{conns: make(chan net.Conn), done: make(chan struct{})}
+
+	go func() { _ = http.Serve(s, handler) }()
+
+	return s
+}
+
+// dial returns a new connection to the server.
+func (s *server) dial() (net.Conn, error) {
+	local, remote := net.Pipe()
+
+	select {
+	case s.conns <- remote:
+		return local, nil
+	case <-s.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Accept implements the 'net.Listener' interface.
+func (s *server) Accept() (net.Conn, error) {
+	select {
+	case conn := <-s.conns:
+		return conn, nil
+	case <-s.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close implements the 'net.Listener' interface, the server will stop accepting new connections.
+func (s *server) Close() error {
+	s.once.Do(func() { close(s.done) })
+	return nil
+}
+
+// Addr implements the 'net.Listener' interface.
+func (s *server) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+// pipeAddr is the address of the in-process server.
+type pipeAddr struct{}
+
+// Network implements the 'net.Addr' interface.
+func (pipeAddr) Network() string {
+	return "pipe"
+}
+
+// String implements the 'net.Addr' interface.
+func (pipeAddr) String() string {
+	return "mock"
+}
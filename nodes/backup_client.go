@@ -15,28 +15,123 @@
 package nodes
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jamesl33/cbtools-autobench/value"
 
 	"github.com/apex/log"
+	"github.com/couchbase/tools-common/strings/format"
+	"github.com/couchbase/tools-common/sync/v2/hofp"
 	"github.com/pkg/errors"
 )
 
+// diskSpaceInterval is the interval at which the free space on the backup client is checked whilst benchmarking.
+const diskSpaceInterval = 10 * time.Second
+
+// progressInterval is the maximum interval at which the progress of a benchmark is checked whilst benchmarking.
+const progressInterval = 30 * time.Second
+
+// iperfDuration is the number of seconds for which the network bandwidth to each cluster node will be measured.
+const iperfDuration = 10
+
 // BackupClient represents a connection to a backup client/node and can be used to perform provisioning/benchmarking.
 type BackupClient struct {
-	blueprint *value.BackupClientBlueprint
-	node      *Node
+	blueprint  *value.BackupClientBlueprint
+	node       *Node
+	checkpoint func(result *value.BenchmarkResult) error
+
+	// colocated indicates that the backup client is also a cluster node, in which case the connection is owned by the
+	// cluster and Couchbase Server must be left running.
+	colocated bool
+}
+
+// backupOverview is the subset of the information output by the 'info' sub-command for a single backup which is
+// required when benchmarking.
+type backupOverview struct {
+	Date    string `json:"date"`
+	Type    string `json:"type"`
+	Size    uint64 `json:"size"`
+	Buckets []struct {
+		Name       string `json:"name"`
+		Size       uint64 `json:"size"`
+		Items      uint64 `json:"total_mutations"`
+		Tombstones uint64 `json:"tombstones"`
+		Views      uint64 `json:"views_count"`
+		FTS        uint64 `json:"fts_count"`
+		Indexes    uint64 `json:"index_count"`
+		Analytics  uint64 `json:"analytics_count"`
+	} `json:"buckets"`
+}
+
+// repositoryMeta is the subset of the repository metadata ('backup-meta.json') written by 'cbbackupmgr' which is
+// included in the report.
+type repositoryMeta struct {
+	Version     int    `json:"version"`
+	Storage     string `json:"storage"`
+	Encrypted   *bool  `json:"encrypted"`
+	PointInTime *bool  `json:"point_in_time"`
+}
+
+// backupPlan is the subset of the plan ('plan.json') written by 'cbbackupmgr' for each backup which is included in the
+// report.
+type backupPlan struct {
+	Shards     int   `json:"shards"`
+	Compressed *bool `json:"compressed"`
+}
+
+// items returns the total number of items across all the buckets in the backup.
+func (b *backupOverview) items() uint64 {
+	var items uint64
+	for _, bucket := range b.Buckets {
+		items += bucket.Items
+	}
+
+	return items
+}
+
+// details returns the structured details of the backup which will be included in the report, note that the file/shard
+// counts aren't part of the 'info' output and must be populated separately.
+func (b *backupOverview) details() *value.BackupDetails {
+	details := &value.BackupDetails{
+		Date:    b.Date,
+		Type:    b.Type,
+		Size:    b.Size,
+		Buckets: make([]*value.BucketDetails, 0, len(b.Buckets)),
+	}
+
+	for _, bucket := range b.Buckets {
+		details.Buckets = append(details.Buckets, &value.BucketDetails{
+			Name:       bucket.Name,
+			Size:       bucket.Size,
+			Items:      bucket.Items,
+			Tombstones: bucket.Tombstones,
+			Views:      bucket.Views,
+			FTS:        bucket.FTS,
+			Indexes:    bucket.Indexes,
+			Analytics:  bucket.Analytics,
+		})
+	}
+
+	return details
 }
 
 // NewBackupClient will connect to a backup client using the provided config.
 func NewBackupClient(config *value.SSHConfig, blueprint *value.BackupClientBlueprint) (*BackupClient, error) {
-	node, err := NewNode(config, &value.NodeBlueprint{Host: blueprint.Host})
+	node, err := NewNode(config, &value.NodeBlueprint{
+		Host:  blueprint.Host,
+		Local: blueprint.Local,
+		SSH:   blueprint.SSH,
+		Paths: blueprint.CBPaths,
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to connect to node")
 	}
@@ -47,26 +142,136 @@ func NewBackupClient(config *value.SSHConfig, blueprint *value.BackupClientBluep
 	}, nil
 }
 
+// NewColocatedBackupClient creates a backup client which shares the connection to the cluster node with the same host,
+// falling back to connecting using the provided config when the backup client is a dedicated machine. An unmanaged
+// cluster is attached to the backup client, which will be used to run commands against it.
+func NewColocatedBackupClient(config *value.SSHConfig, blueprint *value.BackupClientBlueprint,
+	cluster *Cluster,
+) (*BackupClient, error) {
+	if !cluster.blueprint.IsManaged() {
+		client, err := NewBackupClient(config, blueprint)
+		if err != nil {
+			return nil, err
+		}
+
+		cluster.attach(client.node.client)
+
+		return client, nil
+	}
+
+	colocated := (&value.Blueprint{Cluster: cluster.blueprint, BackupClient: blueprint}).Colocated()
+	if colocated == nil {
+		return NewBackupClient(config, blueprint)
+	}
+
+	if blueprint.PackagePath != "" && blueprint.PackagePath != cluster.blueprint.PackagePath {
+		return nil, errors.New("backup client is co-located with a cluster node, it must use the same package")
+	}
+
+	log.WithField("host", colocated.Host).Warn("Backup client is co-located with a cluster node, results may be skewed")
+
+	for _, node := range cluster.nodes {
+		if node.blueprint == colocated {
+			return &BackupClient{blueprint: blueprint, node: node, colocated: true}, nil
+		}
+	}
+
+	return nil, errors.Errorf("failed to find connection for cluster node '%s'", colocated.Host)
+}
+
+// SetCheckpoint sets a function which will be called with the result of each benchmark iteration as soon as it
+// completes, allowing the results to be persisted before the remaining iterations have run.
+func (b *BackupClient) SetCheckpoint(checkpoint func(result *value.BenchmarkResult) error) {
+	b.checkpoint = checkpoint
+}
+
 // Provision will use the client blueprint to provision the backup client, note that if the client is already
 // provisioned it will be re-provisioned i.e. we will remove then install Couchbase.
 func (b *BackupClient) Provision() error {
 	log.WithField("host", b.blueprint.Host).Info("Provisioning backup client")
 
-	err := b.node.provision(b.blueprint.PackagePath)
-	if err != nil {
-		return errors.Wrap(err, "failed to provision node")
+	// A co-located backup client is provisioned as part of the cluster, installing (or disabling) Couchbase Server here
+	// would break the cluster.
+	if !b.colocated {
+		err := b.node.provision(b.blueprint.PackagePath, b.blueprint.DependenciesPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to provision node")
+		}
+
+		// The backup client doesn't need to be running Couchbase in the background, we should disable it so it's not
+		// consuming any resources.
+		err = b.node.disableCB()
+		if err != nil {
+			return errors.Wrap(err, "failed to disable Couchbase Server")
+		}
+	}
+
+	if b.blueprint.Mount != nil {
+		err := b.mount()
+		if err != nil {
+			return errors.Wrap(err, "failed to mount network filesystem")
+		}
 	}
 
-	// The backup client doesn't need to be running Couchbase in the background, we should disable it so it's not
-	// consuming any resources.
-	err = b.node.disableCB()
+	err := b.node.configureVolumes(b.blueprint.Volumes)
 	if err != nil {
-		return errors.Wrap(err, "failed to disable Couchbase Server")
+		return errors.Wrap(err, "failed to configure volumes")
 	}
 
 	return nil
 }
 
+// Volumes returns the characteristics of the AWS EBS volumes described by the backup client blueprint, returns nil if
+// no volumes are described.
+func (b *BackupClient) Volumes() ([]*value.Volume, error) {
+	if len(b.blueprint.Volumes) == 0 {
+		return nil, nil
+	}
+
+	return b.node.describeVolumes(b.blueprint.Volumes)
+}
+
+// Hardware returns the CPUs, memory and operating system of the backup client, returns nil if it can't be described.
+func (b *BackupClient) Hardware() *value.Hardware {
+	hardware, err := b.node.describeHardware()
+	if err != nil {
+		log.WithField("host", b.blueprint.Host).Warnf("Failed to describe hardware: %s", err)
+		return nil
+	}
+
+	return hardware
+}
+
+// mount mounts the configured network filesystem on the backup client, any filesystem which is already mounted at the
+// mount path will be unmounted first to ensure the latest mount options are used.
+func (b *BackupClient) mount() error {
+	mount := b.blueprint.Mount
+
+	fields := log.Fields{"source": mount.Source, "path": mount.Path, "type": mount.FSType(), "options": mount.Options}
+	log.WithFields(fields).Info("Mounting network filesystem")
+
+	err := b.node.client.InstallPackages(b.node.client.Platform().NFSDependencies()...)
+	if err != nil {
+		return errors.Wrap(err, "failed to install dependencies")
+	}
+
+	_, err = b.node.client.ExecuteCommand(
+		value.NewCommand("mkdir -p %[1]s && (! mountpoint -q %[1]s || umount %[1]s)", mount.Path))
+	if err != nil {
+		return errors.Wrap(err, "failed to prepare mount path")
+	}
+
+	command := fmt.Sprintf("mount -t %s", mount.FSType())
+
+	if mount.Options != "" {
+		command += fmt.Sprintf(" -o %s", mount.Options)
+	}
+
+	_, err = b.node.client.ExecuteCommand(value.NewCommand("%s %s %s", command, mount.Source, mount.Path))
+
+	return err
+}
+
 // CollectLogs will run 'collect-logs' on the backup client then cp/download the logs into the provided directory.
 func (b *BackupClient) CollectLogs(config *value.BenchmarkConfig, path string) (string, error) {
 	log.WithField("path", path).Info("Collecting 'cbbackupmgr' logs")
@@ -76,13 +281,8 @@ func (b *BackupClient) CollectLogs(config *value.BenchmarkConfig, path string) (
 		return "", errors.Wrap(err, "failed to run 'collect-logs'")
 	}
 
-	local := config.CBMConfig.Archive
-	if config.CBMConfig.ObjStagingDirectory != "" {
-		local = config.CBMConfig.ObjStagingDirectory
-	}
-
 	output, err := b.node.client.ExecuteCommand(
-		value.NewCommand(`ls -t %s | head -1`, filepath.Join(local, "logs", "*.zip")))
+		value.NewCommand(`ls -t %s | head -1`, filepath.Join(logsDirectory(config), "*.zip")))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to determine which zip file to cp/download")
 	}
@@ -103,6 +303,195 @@ func (b *BackupClient) CollectLogs(config *value.BenchmarkConfig, path string) (
 	return sink, nil
 }
 
+// DownloadIterationLogs archives the 'cbbackupmgr' logs directory on the backup client then downloads it to the
+// provided path, this is used to retain the logs for an iteration before the archive is purged.
+func (b *BackupClient) DownloadIterationLogs(config *value.BenchmarkConfig, path string) error {
+	var (
+		logs   = logsDirectory(config)
+		remote = fmt.Sprintf("/tmp/cbbackupmgr-logs-%d.tar.gz", time.Now().UnixNano())
+	)
+
+	log.WithFields(log.Fields{"source": logs, "sink": path}).Info("Downloading 'cbbackupmgr' iteration logs")
+
+	_, err := b.node.client.ExecuteCommand(
+		value.NewCommand("tar -czf %s -C %s %s", remote, filepath.Dir(logs), filepath.Base(logs)))
+	if err != nil {
+		return errors.Wrap(err, "failed to archive logs")
+	}
+
+	defer func() { _, _ = b.node.client.ExecuteCommand(value.NewCommand("rm -f %s", remote)) }()
+
+	err = b.node.client.SecureDownload(remote, path)
+	if err != nil {
+		return errors.Wrap(err, "failed to cp/download logs")
+	}
+
+	return nil
+}
+
+// logsDirectory returns the directory on the backup client which contains the 'cbbackupmgr' logs, for cloud archives
+// this is within the staging directory.
+func logsDirectory(config *value.BenchmarkConfig) string {
+	local := config.CBMConfig.Archive
+	if config.CBMConfig.ObjStagingDirectory != "" {
+		local = config.CBMConfig.ObjStagingDirectory
+	}
+
+	return filepath.Join(local, "logs")
+}
+
+// MountStagingTmpfs mounts a tmpfs of the configured size at the obj staging directory, the returned function unmounts
+// it again and should be called once the benchmarks (and log collection) have completed. Nothing is mounted when not
+// using a staging directory or when no size has been configured.
+func (b *BackupClient) MountStagingTmpfs(config *value.BenchmarkConfig) (func(), error) {
+	path := config.CBMConfig.ObjStagingDirectory
+
+	if path == "" || config.StagingTmpfsSize == "" {
+		return func() {}, nil
+	}
+
+	log.WithFields(log.Fields{"path": path, "size": config.StagingTmpfsSize}).Info("Mounting staging tmpfs")
+
+	_, err := b.node.client.ExecuteCommand(value.NewCommand(
+		"mkdir -p %[1]s && (! mountpoint -q %[1]s || umount %[1]s) && mount -t tmpfs -o size=%[2]s tmpfs %[1]s",
+		path, config.StagingTmpfsSize))
+	if err != nil {
+		return nil, err
+	}
+
+	unmount := func() {
+		log.WithField("path", path).Info("Unmounting staging tmpfs")
+
+		_, err := b.node.client.ExecuteCommand(value.NewCommand("umount %s", path))
+		if err != nil {
+			log.Warnf("Failed to unmount staging tmpfs at '%s': %s", path, err)
+		}
+	}
+
+	return unmount, nil
+}
+
+// MeasureBandwidth measures the raw network throughput between the backup client and each of the cluster nodes using
+// 'iperf3', which will be installed (then removed) on any machines where it's missing.
+func (b *BackupClient) MeasureBandwidth(cluster *Cluster) ([]*value.Bandwidth, error) {
+	if !cluster.blueprint.IsManaged() {
+		return nil, errUnmanaged
+	}
+
+	log.WithField("hosts", cluster.hosts()).Info("Measuring network bandwidth to cluster")
+
+	cleanup, err := ensureIperf(b.node)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to install 'iperf3' on backup client")
+	}
+	defer cleanup()
+
+	bandwidth := make([]*value.Bandwidth, 0, len(cluster.nodes))
+
+	// Nodes are measured one at a time, otherwise they'd be competing for the backup clients bandwidth
+	for _, node := range cluster.nodes {
+		measured, err := b.measureBandwidth(node)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to measure bandwidth to '%s'", node.blueprint.Host)
+		}
+
+		bandwidth = append(bandwidth, measured)
+	}
+
+	return bandwidth, nil
+}
+
+// ValidateInstanceProfile checks that the backup client is able to reach the instance metadata service, has an IAM role
+// attached and that the role has access to the bucket used by the archive. This is only performed when authenticating
+// using instance metadata, allowing misconfiguration to fail fast rather than part way through a benchmark.
+func (b *BackupClient) ValidateInstanceProfile(config *value.BenchmarkConfig) error {
+	if !config.CBMConfig.ObjAuthByInstanceMetadata || !strings.HasPrefix(config.CBMConfig.Archive, "s3://") {
+		return nil
+	}
+
+	log.WithField("archive", config.CBMConfig.Archive).Info("Validating instance profile")
+
+	const imds = "http://169.254.169.254/latest"
+
+	token := fmt.Sprintf(`TOKEN=$(curl -s -f -X PUT %s/api/token -H 'X-aws-ec2-metadata-token-ttl-seconds: 60')`, imds)
+
+	_, err := b.node.client.ExecuteCommand(value.NewCommand("%s", token))
+	if err != nil {
+		return errors.New("unable to reach the instance metadata service from the backup client, is it an EC2 instance?")
+	}
+
+	output, err := b.node.client.ExecuteCommand(value.NewCommand(
+		`%s && curl -s -f -H "X-aws-ec2-metadata-token: $TOKEN" %s/meta-data/iam/security-credentials/`, token, imds))
+	if err != nil || strings.TrimSpace(string(output)) == "" {
+		return errors.New("the backup client does not have an IAM instance profile attached")
+	}
+
+	role := strings.TrimSpace(string(output))
+
+	var (
+		location = strings.TrimSuffix(config.CBMConfig.Archive, "/") + "/.autobench-preflight"
+		bucket   = strings.SplitN(strings.TrimPrefix(config.CBMConfig.Archive, "s3://"), "/", 2)[0]
+		endpoint string
+	)
+
+	if config.CBMConfig.ObjEndpoint != "" {
+		endpoint = " --endpoint-url " + config.CBMConfig.ObjEndpoint
+	}
+
+	region := awsRegion
+	if config.CBMConfig.ObjRegion != "" {
+		region = fmt.Sprintf("REGION=%s;", config.CBMConfig.ObjRegion)
+	}
+
+	_, err = b.node.client.ExecuteCommand(value.NewCommand(
+		"%s aws s3api head-bucket --region $REGION --bucket %s%s", region, bucket, endpoint))
+	if err != nil {
+		return errors.Errorf("instance profile role '%s' is unable to access bucket '%s'", role, bucket)
+	}
+
+	_, err = b.node.client.ExecuteCommand(value.NewCommand(
+		"%[1]s echo | aws s3 cp --region $REGION - %[2]s%[3]s && aws s3 rm --region $REGION %[2]s%[3]s",
+		region, location, endpoint))
+	if err != nil {
+		return errors.Errorf("instance profile role '%s' is unable to write to '%s'", role, config.CBMConfig.Archive)
+	}
+
+	return nil
+}
+
+// MeasureLatency measures the round trip time from the backup client to each of the cluster nodes, and between each of
+// the cluster nodes.
+func (b *BackupClient) MeasureLatency(cluster *Cluster) ([]*value.Latency, error) {
+	if !cluster.blueprint.IsManaged() {
+		return nil, errUnmanaged
+	}
+
+	log.WithField("hosts", cluster.hosts()).Info("Measuring network latency to cluster")
+
+	var (
+		sources = append([]*Node{b.node}, cluster.nodes...)
+		latency = make([]*value.Latency, 0, len(sources)*len(cluster.nodes))
+	)
+
+	for _, source := range sources {
+		for _, target := range cluster.nodes {
+			if source.blueprint.Host == target.blueprint.Host {
+				continue
+			}
+
+			rtt, err := source.ping(target.blueprint.Host)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to measure latency from '%s' to '%s'", source.blueprint.Host,
+					target.blueprint.Host)
+			}
+
+			latency = append(latency, &value.Latency{Source: source.blueprint.Host, Target: target.blueprint.Host, RTT: rtt})
+		}
+	}
+
+	return latency, nil
+}
+
 // BenchmarkBackup will run one or more backup benchmarks on the client using the provided benchmark config. If the
 // provided context is cancelled, we will gracefully complete the current backup then return early.
 func (b *BackupClient) BenchmarkBackup(ctx context.Context, config *value.BenchmarkConfig,
@@ -110,14 +499,28 @@ func (b *BackupClient) BenchmarkBackup(ctx context.Context, config *value.Benchm
 ) (value.BenchmarkResults, error) {
 	log.WithField("iterations", config.Iterations).Info("Beginning 'cbbackupmgr' backup benchmark(s)")
 
-	err := b.purgeArchive(config)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to purge archive")
+	if config.ExistingArchive {
+		return nil, errors.New("benchmarking using an existing archive is only supported by restore benchmarks")
 	}
 
-	err = b.createRepository(config)
+	tasks := config.Tasks()
+
+	err := b.prepareArchive(tasks...)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create repository")
+		return nil, errors.Wrap(err, "failed to prepare archive")
+	}
+
+	// When keeping the archive, the repositories may already contain backups which must remain once each of the
+	// benchmarks has completed (meaning the benchmarked backups will be incremental).
+	retain := make([]int, 0, len(tasks))
+
+	for _, task := range tasks {
+		backups, err := b.listBackups(task)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list backups")
+		}
+
+		retain = append(retain, len(backups))
 	}
 
 	results := make(value.BenchmarkResults, 0, config.Iterations)
@@ -125,13 +528,20 @@ func (b *BackupClient) BenchmarkBackup(ctx context.Context, config *value.Benchm
 	for iteration := 0; iteration < max(1, config.Iterations); iteration++ {
 		log.WithField("iteration", iteration+1).Info("Beginning 'cbbackupmgr' backup benchmark")
 
-		result, err := b.benchmarkBackup(config, cluster)
+		result, err := b.runIteration(config, cluster, "default", func() (*value.BenchmarkResult, error) {
+			return b.benchmarkBackup(config, cluster, retain)
+		})
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to run benchmark")
 		}
 
 		results = append(results, result)
 
+		err = b.saveCheckpoint(result)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to checkpoint result")
+		}
+
 		// If the context has been cancelled, don't run any more benchmarks; the user wants to gracefully terminate
 		if ctx.Err() != nil {
 			break
@@ -141,6 +551,15 @@ func (b *BackupClient) BenchmarkBackup(ctx context.Context, config *value.Benchm
 	return results, nil
 }
 
+// saveCheckpoint passes the result of a completed iteration to the checkpoint function, if one has been set.
+func (b *BackupClient) saveCheckpoint(result *value.BenchmarkResult) error {
+	if b.checkpoint == nil {
+		return nil
+	}
+
+	return b.checkpoint(result)
+}
+
 // BenchmarkRestore will run one or more restore benchmarks on the client using the providing benchmark config. If the
 // provided context is cancelled, we will gracefully complete the current restore then return early.
 func (b *BackupClient) BenchmarkRestore(ctx context.Context, config *value.BenchmarkConfig,
@@ -148,19 +567,57 @@ func (b *BackupClient) BenchmarkRestore(ctx context.Context, config *value.Bench
 ) (value.BenchmarkResults, error) {
 	log.WithField("iterations", config.Iterations).Info("Beginning 'cbbackupmgr' restore benchmark(s)")
 
-	err := b.purgeArchive(config)
+	err := validateRestoreConfig(config, cluster)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to purge archive")
+		return nil, err
+	}
+
+	backupInfo, err := b.prepareRestoreArchive(config, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	dates, err := b.createdBackups(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list created backups")
+	}
+
+	var start, end string
+	if len(dates) != 0 {
+		start, end = dates[0], dates[len(dates)-1]
+	}
+
+	// The documents are recorded once the final backup has been created (the data may have been mutated whilst seeding)
+	// but before the benchmarking bucket is restored into
+	if config.VerifyIntegrity {
+		cleanup, err := cluster.recordIntegrity()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to record documents")
+		}
+		defer cleanup()
+	}
+
+	err = b.primeRestoreBucket(config, cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prime bucket")
+	}
+
+	err = setTargetCompactionConcurrency(config, cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set compaction concurrency")
 	}
 
-	err = b.createRepository(config)
+	// The bucket is primed prior to throttling, since priming isn't timed
+	unthrottle, err := cluster.throttle(config.Throttle)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create repository")
+		return nil, errors.Wrap(err, "failed to throttle cluster")
 	}
+	defer unthrottle()
 
-	backupInfo, err := b.createBackup(config, cluster, true)
+	// Restores read every object in the restored backups, so this is used to estimate the number of requests
+	objects, _, err := b.countObjects(config, dates...)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create backup")
+		return nil, errors.Wrap(err, "failed to count objects in repository")
 	}
 
 	results := make(value.BenchmarkResults, 0, config.Iterations)
@@ -168,20 +625,34 @@ func (b *BackupClient) BenchmarkRestore(ctx context.Context, config *value.Bench
 	for iteration := 0; iteration < max(1, config.Iterations); iteration++ {
 		log.WithField("iteration", iteration+1).Info("Beginning 'cbbackupmgr' restore benchmark")
 
-		if !config.CBMConfig.Blackhole {
-			err = cluster.flushBucket()
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to flush bucket")
-			}
+		err = prepareRestoreBucket(config, cluster)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to prepare bucket")
 		}
 
-		result, err := b.benchmarkRestore(config, cluster, backupInfo.BackupSize)
+		result, err := b.runIteration(config, cluster, cluster.restoreBucket(), func() (*value.BenchmarkResult, error) {
+			return b.benchmarkRestore(config, cluster, backupInfo.BackupSize, start, end)
+		})
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to run benchmark")
 		}
 
+		result.GetRequests = objects
+
+		if config.VerifyIntegrity {
+			result.Integrity, err = cluster.verifyIntegrity()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to verify integrity")
+			}
+		}
+
 		results = append(results, result)
 
+		err = b.saveCheckpoint(result)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to checkpoint result")
+		}
+
 		// If the context has been cancelled, don't run any more benchmarks; the user wants to gracefully terminate
 		if ctx.Err() != nil {
 			break
@@ -191,251 +662,1654 @@ func (b *BackupClient) BenchmarkRestore(ctx context.Context, config *value.Bench
 	return results, nil
 }
 
-// benchmarkBackup will run an individual backup benchmark and fetch any data needed to produce a useful report.
-func (b *BackupClient) benchmarkBackup(config *value.BenchmarkConfig,
+// BenchmarkRestoreRange will seed the repository with a chain of backups then run one or more restore benchmarks for
+// each of the configured chain lengths, restoring the slice of the chain using the '--start' and '--end' flags. If the
+// provided context is cancelled, we will gracefully complete the current restore then return early.
+func (b *BackupClient) BenchmarkRestoreRange(ctx context.Context, config *value.BenchmarkConfig,
 	cluster *Cluster,
-) (*value.BenchmarkResult, error) {
-	result := &value.BenchmarkResult{}
+) (value.BenchmarkResults, error) {
+	if (config.Seed == nil && !config.ExistingArchive) || config.RestoreRange == nil ||
+		len(config.RestoreRange.Lengths) == 0 {
+		return nil, errors.New("range restore benchmarks require both 'seed' (or 'existing_archive') and " +
+			"'restore_range' to be configured")
+	}
 
-	start := time.Now()
-	defer func() {
-		result.Duration = time.Since(start)
-	}()
+	log.WithFields(log.Fields{
+		"iterations": config.Iterations,
+		"lengths":    config.RestoreRange.Lengths,
+	}).Info("Beginning 'cbbackupmgr' range restore benchmark(s)")
 
-	err := cluster.runPreBenchmarkTasks()
+	err := validateRestoreConfig(config, cluster)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to run cluster pre-benchmark tasks")
+		return nil, err
 	}
 
-	err = b.runPreBenchmarkTasks()
+	_, err = b.prepareRestoreArchive(config, cluster)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to run client pre-benchmark tasks")
+		return nil, err
 	}
 
-	backupInfo, err := b.createBackup(config, cluster, false)
+	err = b.primeRestoreBucket(config, cluster)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create backup")
+		return nil, errors.Wrap(err, "failed to prime bucket")
 	}
 
-	result.ADS = backupInfo.BackupSize
-	result.AIN = backupInfo.ItemsNum
-
-	err = b.purgeBackups(config)
+	err = setTargetCompactionConcurrency(config, cluster)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to purge created backup")
-	}
-
-	return result, nil
-}
-
-// benchmarkRestore will run an individual restore benchmark and fetch any data needed to produce a useful report.
-func (b *BackupClient) benchmarkRestore(config *value.BenchmarkConfig,
-	cluster *Cluster, ads uint64,
-) (*value.BenchmarkResult, error) {
-	result := &value.BenchmarkResult{
-		ADS: ads,
+		return nil, errors.Wrap(err, "failed to set compaction concurrency")
 	}
 
-	start := time.Now()
-	defer func() {
-		result.Duration = time.Since(start)
-	}()
-
-	err := cluster.runPreBenchmarkTasks()
+	// The bucket is primed prior to throttling, since priming isn't timed
+	unthrottle, err := cluster.throttle(config.Throttle)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to run cluster pre-benchmark tasks")
+		return nil, errors.Wrap(err, "failed to throttle cluster")
 	}
+	defer unthrottle()
 
-	err = b.runPreBenchmarkTasks()
+	backups, err := b.listBackups(config)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to run client pre-benchmark tasks")
+		return nil, errors.Wrap(err, "failed to list backups")
 	}
 
-	err = b.restoreBackup(config, cluster)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to restore backup")
-	}
+	first := max(1, config.RestoreRange.Start)
 
-	return result, nil
-}
+	results := make(value.BenchmarkResults, 0, len(config.RestoreRange.Lengths)*max(1, config.Iterations))
 
-// configureRepository wil run the config sub-command to create a new backup repository.
+	for _, length := range config.RestoreRange.Lengths {
+		last := first + length - 1
+		if length <= 0 || last > len(backups) {
+			return nil, errors.Errorf("range %d-%d is outside the seeded chain of %d backups", first, last, len(backups))
+		}
+
+		var (
+			start = backups[first-1].Date
+			end   = backups[last-1].Date
+			dates = make([]string, 0, length)
+			ads   uint64
+		)
+
+		for _, backup := range backups[first-1 : last] {
+			ads += backup.Size
+			dates = append(dates, backup.Date)
+		}
+
+		objects, _, err := b.countObjects(config, dates...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to count objects in range")
+		}
+
+		for iteration := 0; iteration < max(1, config.Iterations); iteration++ {
+			log.WithFields(log.Fields{"iteration": iteration + 1, "start": first, "end": last}).
+				Info("Beginning 'cbbackupmgr' range restore benchmark")
+
+			err = prepareRestoreBucket(config, cluster)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to prepare bucket")
+			}
+
+			result, err := b.runIteration(config, cluster, cluster.restoreBucket(), func() (*value.BenchmarkResult, error) {
+				return b.benchmarkRestore(config, cluster, ads, start, end)
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to run benchmark")
+			}
+
+			result.Range = fmt.Sprintf("%d-%d", first, last)
+			result.GetRequests = objects
+
+			results = append(results, result)
+
+			err = b.saveCheckpoint(result)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to checkpoint result")
+			}
+
+			// If the context has been cancelled, don't run any more benchmarks; the user wants to gracefully terminate
+			if ctx.Err() != nil {
+				return results, nil
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// BenchmarkInfo will prepare the repository (in the same way as the restore benchmarks) then run one or more info
+// benchmarks, timing how long 'cbbackupmgr' takes to scan the metadata of every backup in the repository. If the
+// provided context is cancelled, we will gracefully complete the current iteration then return early.
+func (b *BackupClient) BenchmarkInfo(ctx context.Context, config *value.BenchmarkConfig,
+	cluster *Cluster,
+) (value.BenchmarkResults, error) {
+	log.WithField("iterations", config.Iterations).Info("Beginning 'cbbackupmgr' info benchmark(s)")
+
+	backupInfo, err := b.prepareRestoreArchive(config, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(value.BenchmarkResults, 0, config.Iterations)
+
+	for iteration := 0; iteration < max(1, config.Iterations); iteration++ {
+		log.WithField("iteration", iteration+1).Info("Beginning 'cbbackupmgr' info benchmark")
+
+		result, err := b.runIteration(config, cluster, "default", func() (*value.BenchmarkResult, error) {
+			return b.benchmarkInfo(config, backupInfo)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to run benchmark")
+		}
+
+		results = append(results, result)
+
+		err = b.saveCheckpoint(result)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to checkpoint result")
+		}
+
+		// If the context has been cancelled, don't run any more benchmarks; the user wants to gracefully terminate
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// BenchmarkRemove will run one or more remove benchmarks, each iteration creates a backup (or a seeded chain of
+// backups) then times how long 'cbbackupmgr' takes to remove them. Any backups which existed in a kept archive are
+// retained. If the provided context is cancelled, we will gracefully complete the current iteration then return early.
+func (b *BackupClient) BenchmarkRemove(ctx context.Context, config *value.BenchmarkConfig,
+	cluster *Cluster,
+) (value.BenchmarkResults, error) {
+	log.WithField("iterations", config.Iterations).Info("Beginning 'cbbackupmgr' remove benchmark(s)")
+
+	if config.ExistingArchive {
+		return nil, errors.New("benchmarking using an existing archive is not supported by remove benchmarks, " +
+			"since its backups would be removed")
+	}
+
+	err := b.prepareArchive(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare archive")
+	}
+
+	backups, err := b.listBackups(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list backups")
+	}
+
+	var (
+		retain  = len(backups)
+		results = make(value.BenchmarkResults, 0, config.Iterations)
+	)
+
+	for iteration := 0; iteration < max(1, config.Iterations); iteration++ {
+		log.WithField("iteration", iteration+1).Info("Beginning 'cbbackupmgr' remove benchmark")
+
+		// Creating the backups isn't timed, only their removal
+		backupInfo, err := b.createRemoveBackups(config, cluster)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create backup(s)")
+		}
+
+		result, err := b.runIteration(config, cluster, "default", func() (*value.BenchmarkResult, error) {
+			return b.benchmarkRemove(config, backupInfo, retain)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to run benchmark")
+		}
+
+		results = append(results, result)
+
+		err = b.saveCheckpoint(result)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to checkpoint result")
+		}
+
+		// If the context has been cancelled, don't run any more benchmarks; the user wants to gracefully terminate
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// SeedArchive pre-seeds the repository with a chain of backups, mutating data in the cluster between each backup so
+// that the incremental backups contain data. Returns the combined size/items of all the created backups.
+//
+// NOTE: The data mutated between backups will remain in the cluster once seeding has completed.
+func (b *BackupClient) SeedArchive(config *value.BenchmarkConfig, cluster *Cluster) (*value.BackupInfo, error) {
+	fields := log.Fields{
+		"backups":   config.Seed.Backups,
+		"mutations": config.Seed.Mutations,
+		"growth":    config.Seed.GrowthPercentage,
+	}
+
+	log.WithFields(fields).Info("Seeding archive")
+
+	seeded := &value.BackupInfo{}
+
+	for backup := 0; backup < max(1, config.Seed.Backups); backup++ {
+		if backup != 0 && config.Seed.Mutations != 0 {
+			err := cluster.MutateData(config.Seed.Mutations,
+				config.Seed.ItemSize(backup, cluster.blueprint.Bucket.Data.Size))
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to mutate data")
+			}
+		}
+
+		// The chain starts with a full backup when keeping the archive, so that it may be restored without the backups
+		// which already existed in the repository
+		backupInfo, err := b.createBackup(config, cluster, true, backup == 0 && config.KeepArchive)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create backup %d", backup+1)
+		}
+
+		seeded.BackupSize += backupInfo.BackupSize
+		seeded.ItemsNum += backupInfo.ItemsNum
+	}
+
+	return seeded, nil
+}
+
+// prepareRestoreArchive prepares the archive and creates the backup(s) which will be restored by the restore
+// benchmarks, returning their combined size/items. When using an existing archive, the backups it already contains are
+// used as is.
+func (b *BackupClient) prepareRestoreArchive(config *value.BenchmarkConfig,
+	cluster *Cluster,
+) (*value.BackupInfo, error) {
+	if config.ExistingArchive {
+		return b.existingBackups(config)
+	}
+
+	err := b.prepareArchive(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare archive")
+	}
+
+	backupInfo, err := b.createRestoreBackups(config, cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create backup(s)")
+	}
+
+	return backupInfo, nil
+}
+
+// existingBackups returns the combined size/items of the backups in an existing archive/repository, which must contain
+// at least one backup.
+func (b *BackupClient) existingBackups(config *value.BenchmarkConfig) (*value.BackupInfo, error) {
+	log.WithFields(log.Fields{"archive": config.CBMConfig.Archive, "repository": config.CBMConfig.Repository}).
+		Info("Using existing archive")
+
+	backups, err := b.listBackups(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list backups in existing archive")
+	}
+
+	if len(backups) == 0 {
+		return nil, errors.Errorf("repository '%s' in the existing archive does not contain any backups",
+			config.CBMConfig.Repository)
+	}
+
+	return summarizeBackups(backups), nil
+}
+
+// summarizeBackups returns the combined size/items of the given backups.
+func summarizeBackups(backups []*backupOverview) *value.BackupInfo {
+	summary := &value.BackupInfo{}
+
+	for _, backup := range backups {
+		summary.BackupSize += backup.Size
+		summary.ItemsNum += backup.items()
+	}
+
+	return summary
+}
+
+// createRestoreBackups creates the backup(s) which will be restored by the restore benchmark, this is either a single
+// backup or a seeded chain of backups. When keeping the archive, they're created on top of any existing backups
+// starting with a full backup.
+func (b *BackupClient) createRestoreBackups(config *value.BenchmarkConfig,
+	cluster *Cluster,
+) (*value.BackupInfo, error) {
+	if config.Seed == nil {
+		return b.createBackup(config, cluster, true, config.KeepArchive)
+	}
+
+	return b.SeedArchive(config, cluster)
+}
+
+// createdBackups returns the dates of the backups created by 'createRestoreBackups' when keeping the archive, so that
+// only they are restored rather than every backup in the repository; nil is returned when the repository only contains
+// the created backups.
+func (b *BackupClient) createdBackups(config *value.BenchmarkConfig) ([]string, error) {
+	if !config.KeepArchive || config.ExistingArchive {
+		return nil, nil
+	}
+
+	backups, err := b.listBackups(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list backups")
+	}
+
+	created := 1
+	if config.Seed != nil {
+		created = max(1, config.Seed.Backups)
+	}
+
+	if len(backups) < created {
+		return nil, errors.Errorf("expected at least %d backup(s) but found %d", created, len(backups))
+	}
+
+	dates := make([]string, 0, created)
+	for _, backup := range backups[len(backups)-created:] {
+		dates = append(dates, backup.Date)
+	}
+
+	return dates, nil
+}
+
+// benchmarkBackup will run an individual backup benchmark and fetch any data needed to produce a useful report. Once
+// complete, the created backups are purged leaving only the number of backups given by 'retain' for each task.
+func (b *BackupClient) benchmarkBackup(config *value.BenchmarkConfig,
+	cluster *Cluster, retain []int,
+) (*value.BenchmarkResult, error) {
+	result := &value.BenchmarkResult{Start: time.Now()}
+	defer result.Complete()
+
+	err := cluster.runPreBenchmarkTasks()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run cluster pre-benchmark tasks")
+	}
+
+	err = b.runPreBenchmarkTasks()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run client pre-benchmark tasks")
+	}
+
+	tasks := config.Tasks()
+
+	stop := cluster.sampleDCP(time.Duration(config.DCPSampleInterval) * time.Second)
+
+	err = b.createBackups(cluster, tasks, result)
+
+	result.DCP = stop()
+
+	if err != nil {
+		b.cleanupFailedBackups(tasks, retain)
+		return nil, err
+	}
+
+	for idx, task := range tasks {
+		err = b.purgeBackups(task, retain[idx])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to purge created backup")
+		}
+	}
+
+	return result, nil
+}
+
+// createBackups creates the backup(s) for an individual backup benchmark, populating the given result.
+func (b *BackupClient) createBackups(cluster *Cluster, tasks []*value.BenchmarkConfig,
+	result *value.BenchmarkResult,
+) error {
+	if len(tasks) == 1 {
+		backupInfo, err := b.createBackup(tasks[0], cluster, false, false)
+		if err != nil {
+			return errors.Wrap(err, "failed to create backup")
+		}
+
+		result.ADS = backupInfo.BackupSize
+		result.AIN = backupInfo.ItemsNum
+		result.DiskSize = backupInfo.DiskSize
+		result.PutRequests = backupInfo.Objects
+		result.Backups = []*value.BackupDetails{backupInfo.Details}
+
+		return nil
+	}
+
+	var err error
+
+	result.Tasks, err = b.createConcurrentBackups(tasks, cluster)
+	if err != nil {
+		return errors.Wrap(err, "failed to create concurrent backups")
+	}
+
+	for _, task := range result.Tasks {
+		result.ADS += task.ADS
+		result.AIN += task.AIN
+		result.DiskSize += task.DiskSize
+		result.PutRequests += task.PutRequests
+		result.Backups = append(result.Backups, task.Backups...)
+	}
+
+	return nil
+}
+
+// createConcurrentBackups simultaneously creates a backup in each of the repositories for the given tasks, returning
+// the result of each individual backup.
+func (b *BackupClient) createConcurrentBackups(tasks []*value.BenchmarkConfig,
+	cluster *Cluster,
+) ([]*value.BenchmarkResult, error) {
+	var (
+		pool    = hofp.NewPool(hofp.Options{Size: len(tasks)})
+		results = make([]*value.BenchmarkResult, len(tasks))
+	)
+
+	create := func(idx int, task *value.BenchmarkConfig) error {
+		result := &value.BenchmarkResult{
+			Start:      time.Now(),
+			Archive:    task.CBMConfig.Archive,
+			Repository: task.CBMConfig.Repository,
+		}
+
+		backupInfo, err := b.createBackup(task, cluster, false, false)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create backup in repository '%s'", task.CBMConfig.Repository)
+		}
+
+		result.Complete()
+
+		result.ADS = backupInfo.BackupSize
+		result.AIN = backupInfo.ItemsNum
+		result.DiskSize = backupInfo.DiskSize
+		result.PutRequests = backupInfo.Objects
+		result.Backups = []*value.BackupDetails{backupInfo.Details}
+
+		results[idx] = result
+
+		return nil
+	}
+
+	queue := func(idx int, task *value.BenchmarkConfig) error {
+		return pool.Queue(func(_ context.Context) error { return create(idx, task) })
+	}
+
+	for idx, task := range tasks {
+		if queue(idx, task) != nil {
+			break
+		}
+	}
+
+	err := pool.Stop()
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// benchmarkRestore will run an individual restore benchmark and fetch any data needed to produce a useful report.
+func (b *BackupClient) benchmarkRestore(config *value.BenchmarkConfig,
+	cluster *Cluster, ads uint64, startBackup, endBackup string,
+) (*value.BenchmarkResult, error) {
+	result := &value.BenchmarkResult{
+		ADS:   ads,
+		Start: time.Now(),
+	}
+
+	defer result.Complete()
+
+	err := cluster.runPreBenchmarkTasks()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run cluster pre-benchmark tasks")
+	}
+
+	err = b.runPreBenchmarkTasks()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run client pre-benchmark tasks")
+	}
+
+	err = b.restoreBackup(config, cluster, startBackup, endBackup)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to restore backup")
+	}
+
+	return result, nil
+}
+
+// benchmarkInfo will run an individual info benchmark, scanning the metadata of every backup in the repository.
+func (b *BackupClient) benchmarkInfo(config *value.BenchmarkConfig,
+	backupInfo *value.BackupInfo,
+) (*value.BenchmarkResult, error) {
+	result := &value.BenchmarkResult{
+		ADS:   backupInfo.BackupSize,
+		AIN:   backupInfo.ItemsNum,
+		Start: time.Now(),
+	}
+
+	defer result.Complete()
+
+	err := b.runPreBenchmarkTasks()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run client pre-benchmark tasks")
+	}
+
+	_, err = b.listBackups(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get repository info")
+	}
+
+	return result, nil
+}
+
+// createRemoveBackups creates the backup(s) which will be removed by an iteration of the remove benchmark, this is
+// either a single backup or a seeded chain of backups.
+func (b *BackupClient) createRemoveBackups(config *value.BenchmarkConfig,
+	cluster *Cluster,
+) (*value.BackupInfo, error) {
+	if config.Seed == nil {
+		return b.createBackup(config, cluster, true, config.KeepArchive)
+	}
+
+	return b.SeedArchive(config, cluster)
+}
+
+// benchmarkRemove will run an individual remove benchmark, removing every backup except for the given number of oldest
+// backups which should be retained.
+func (b *BackupClient) benchmarkRemove(config *value.BenchmarkConfig, backupInfo *value.BackupInfo,
+	retain int,
+) (*value.BenchmarkResult, error) {
+	result := &value.BenchmarkResult{
+		ADS: backupInfo.BackupSize,
+		AIN: backupInfo.ItemsNum,
+	}
+
+	err := b.runPreBenchmarkTasks()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run client pre-benchmark tasks")
+	}
+
+	// The backups are listed prior to starting the timer, so that the duration only covers their removal
+	backups, err := b.listBackups(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list backups")
+	}
+
+	if len(backups) <= retain {
+		return nil, errors.New("no backups were created to remove")
+	}
+
+	result.Start = time.Now()
+	defer result.Complete()
+
+	_, err = b.node.client.ExecuteCommand(
+		config.CBMConfig.CommandRemove(backups[retain].Date, backups[len(backups)-1].Date),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to remove backups")
+	}
+
+	return result, nil
+}
+
+// prepareArchive ensures the archive(s) contain a repository for each of the given tasks. Unless the archive is being
+// kept, each archive will be purged beforehand; existing repositories in a kept archive are checked to be compatible
+// with the configuration then reused.
+func (b *BackupClient) prepareArchive(tasks ...*value.BenchmarkConfig) error {
+	// When striping across devices the tasks will be using different archives, each of which must be prepared once
+	prepared := make(map[string][]string)
+
+	for _, task := range tasks {
+		repositories, ok := prepared[task.CBMConfig.Archive]
+		if !ok {
+			var err error
+
+			repositories, err = b.resetArchive(task)
+			if err != nil {
+				return err
+			}
+
+			prepared[task.CBMConfig.Archive] = repositories
+		}
+
+		if !slices.Contains(repositories, task.CBMConfig.Repository) {
+			err := b.createRepository(task)
+			if err != nil {
+				return errors.Wrap(err, "failed to create repository")
+			}
+
+			continue
+		}
+
+		err := b.checkRepositoryCompatible(task)
+		if err != nil {
+			return errors.Wrapf(err, "existing repository '%s' can't be reused", task.CBMConfig.Repository)
+		}
+	}
+
+	return nil
+}
+
+// resetArchive purges the archive used by the given config, unless it's being kept in which case the repositories it
+// already contains are returned.
+func (b *BackupClient) resetArchive(config *value.BenchmarkConfig) ([]string, error) {
+	if config.KeepArchive {
+		repositories, err := b.listRepositories(config)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list repositories")
+		}
+
+		return repositories, nil
+	}
+
+	err := b.purgeArchive(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to purge archive")
+	}
+
+	return nil, nil
+}
+
+// checkRepositoryCompatible ensures that an existing repository can be reused with the current configuration i.e. that
+// its backups can be listed using the configured passphrase/key and that it uses the configured storage/encryption.
+func (b *BackupClient) checkRepositoryCompatible(config *value.BenchmarkConfig) error {
+	log.WithField("repository", config.CBMConfig.Repository).Info("Checking existing repository is compatible")
+
+	// The 'info' sub-command is run using the configured passphrase/key, so fails when they're incorrect
+	backups, err := b.listBackups(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to list backups")
+	}
+
+	var meta repositoryMeta
+
+	err = b.readArchiveFile(config, &meta, config.CBMConfig.Repository, "backup-meta.json")
+	if err != nil {
+		return errors.Wrap(err, "failed to read repository metadata")
+	}
+
+	if meta.Encrypted != nil && *meta.Encrypted != config.CBMConfig.Encrypted {
+		return errors.Errorf("repository encryption (%t) doesn't match the configuration (%t)", *meta.Encrypted,
+			config.CBMConfig.Encrypted)
+	}
+
+	if meta.Storage != "" && config.CBMConfig.Storage != "" && meta.Storage != config.CBMConfig.Storage {
+		return errors.Errorf("repository storage type '%s' doesn't match the configured storage type '%s'",
+			meta.Storage, config.CBMConfig.Storage)
+	}
+
+	log.WithFields(log.Fields{
+		"repository": config.CBMConfig.Repository,
+		"backups":    len(backups),
+	}).Info("Reusing existing repository")
+
+	return nil
+}
+
+// listRepositories uses the info sub-command to list the names of the repositories in the archive, an archive which
+// doesn't exist yet contains no repositories.
+func (b *BackupClient) listRepositories(config *value.BenchmarkConfig) ([]string, error) {
+	// The archive hasn't been created yet, there's no need to check for repositories
+	if !strings.HasPrefix(config.CBMConfig.Archive, "s3://") && !b.node.client.FileExists(config.CBMConfig.Archive) {
+		return nil, nil
+	}
+
+	output, err := b.node.client.ExecuteCommand(config.CBMConfig.CommandArchiveInfo())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run info")
+	}
+
+	type repository struct {
+		Name string `json:"name"`
+	}
+
+	type overlay struct {
+		Repositories []repository `json:"repos"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(output, &decoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode info output")
+	}
+
+	repositories := make([]string, 0, len(decoded.Repositories))
+	for _, repository := range decoded.Repositories {
+		repositories = append(repositories, repository.Name)
+	}
+
+	return repositories, nil
+}
+
+// Inspect returns a summary of the contents of the configured archive using the 'info' sub-command, an empty summary is
+// returned for a local archive which hasn't been created yet.
+func (b *BackupClient) Inspect(config *value.BenchmarkConfig) (*value.ArchiveSummary, error) {
+	summary := &value.ArchiveSummary{Archive: config.CBMConfig.Archive}
+
+	if !strings.HasPrefix(config.CBMConfig.Archive, "s3://") && !b.node.client.FileExists(config.CBMConfig.Archive) {
+		return summary, nil
+	}
+
+	output, err := b.node.client.ExecuteCommand(config.CBMConfig.CommandArchiveInfo())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run info")
+	}
+
+	type repository struct {
+		Name    string            `json:"name"`
+		Size    uint64            `json:"size"`
+		Backups []*backupOverview `json:"backups"`
+	}
+
+	type overlay struct {
+		Repositories []repository `json:"repos"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(output, &decoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode info output")
+	}
+
+	for _, repository := range decoded.Repositories {
+		backups := make([]*value.BackupDetails, 0, len(repository.Backups))
+		for _, backup := range repository.Backups {
+			backups = append(backups, backup.details())
+		}
+
+		summary.Repositories = append(summary.Repositories, &value.RepositorySummary{
+			Name:    repository.Name,
+			Size:    repository.Size,
+			Backups: backups,
+		})
+	}
+
+	return summary, nil
+}
+
+// configureRepository wil run the config sub-command to create a new backup repository.
 func (b *BackupClient) createRepository(config *value.BenchmarkConfig) error {
-	log.Info("Creating repository")
+	log.WithField("repository", config.CBMConfig.Repository).Info("Creating repository")
+
+	_, err := b.node.client.ExecuteCommand(config.CBMConfig.CommandConfig())
+
+	return err
+}
+
+// runPreBenchmarkTasks will run any pre-benchmark tasks on the backup client. For example, we should always flush the
+// caches prior to running a benchmark.
+func (b *BackupClient) runPreBenchmarkTasks() error {
+	log.Info("Running backup client pre-benchmark tasks")
+
+	return b.node.client.FlushCaches()
+}
+
+// createBackup creates a backup of the provided cluster, note that the 'ignoreBlackhole' argument is required to allow
+// benchmarking restore to blackhole i.e. we must create a backup to restore. The 'full' argument forces a full backup,
+// even when the repository already contains backups.
+func (b *BackupClient) createBackup(config *value.BenchmarkConfig, cluster *Cluster,
+	ignoreBlackhole, full bool,
+) (*value.BackupInfo, error) {
+	fields := log.Fields{
+		"blackhole":  config.CBMConfig.Blackhole,
+		"hosts":      cluster.hosts(),
+		"repository": config.CBMConfig.Repository,
+	}
+
+	log.WithFields(fields).Info("Creating backup")
+
+	command := config.CBMConfig.CommandBackup(cluster.ConnectionString(config.CBMConfig.TLS), cluster.credentials(),
+		ignoreBlackhole, full)
+
+	stop := heartbeat(fmt.Sprintf("Creating backup in repository '%s'", config.CBMConfig.Repository),
+		b.sizeProgress(config), format.Bytes)
+
+	_, err := b.node.client.ExecuteCommand(command)
+
+	stop()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run backup")
+	}
+
+	// All the data should be synced to disk by cbbackupmgr, however, for good measure we'll sync now
+	err = b.node.client.Sync()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sync data to disk")
+	}
+
+	backups, err := b.listBackups(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list backups")
+	}
+
+	if len(backups) == 0 {
+		return nil, errors.Errorf("repository '%s' contains no backups", config.CBMConfig.Repository)
+	}
+
+	// The repository may contain other backups (for example when it's been seeded) so we only care about the size of
+	// the last backup in the list which is the one we just created
+	latest := backups[len(backups)-1]
+
+	backupInfo := &value.BackupInfo{
+		BackupSize: latest.Size,
+		ItemsNum:   latest.items(),
+		Details:    latest.details(),
+	}
+
+	// When using the blackhole sink, there's no backup on disk to measure
+	if ignoreBlackhole || !config.CBMConfig.Blackhole {
+		err = b.measureBackup(config, latest, backupInfo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to measure backup on disk")
+		}
+
+		// The metadata is purely informational, failing to read it shouldn't cause the benchmark to fail
+		backupInfo.Details.Metadata, err = b.readMetadata(config, latest.Date)
+		if err != nil {
+			log.Warnf("Failed to read backup metadata, it will be omitted from the report: %s", err)
+		}
+
+		if backupInfo.Details.Metadata != nil && backupInfo.Details.Metadata.Shards == 0 {
+			backupInfo.Details.Metadata.Shards = backupInfo.Details.Shards
+		}
+	}
+
+	return backupInfo, nil
+}
+
+// measureBackup populates the size of the given backup on disk along with its file/shard counts, for cloud archives the
+// size/number of objects in the object store are populated instead.
+func (b *BackupClient) measureBackup(config *value.BenchmarkConfig, backup *backupOverview,
+	backupInfo *value.BackupInfo,
+) error {
+	var err error
+
+	if strings.HasPrefix(config.CBMConfig.Archive, "s3://") {
+		backupInfo.Objects, backupInfo.DiskSize, err = b.countObjects(config, backup.Date)
+		if err != nil {
+			return errors.Wrap(err, "failed to count objects in backup")
+		}
+
+		return nil
+	}
+
+	path := filepath.Join(config.CBMConfig.Archive, config.CBMConfig.Repository, backup.Date)
+
+	backupInfo.DiskSize, err = b.node.client.DirectorySize(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to get size of backup")
+	}
+
+	backupInfo.Details.Files, err = b.node.client.CountFiles(path, "")
+	if err != nil {
+		return errors.Wrap(err, "failed to count files in backup")
+	}
+
+	// The data for each vBucket is sharded across the files in the 'data' directory of each bucket
+	backupInfo.Details.Shards, err = b.node.client.CountFiles(path, "-path '*/data/*'")
+	if err != nil {
+		return errors.Wrap(err, "failed to count shards in backup")
+	}
+
+	return nil
+}
+
+// readMetadata parses the repository metadata and the plan for the backup with the given date, which are written into
+// the archive by 'cbbackupmgr'.
+func (b *BackupClient) readMetadata(config *value.BenchmarkConfig, date string) (*value.BackupMetadata, error) {
+	var meta repositoryMeta
+
+	err := b.readArchiveFile(config, &meta, config.CBMConfig.Repository, "backup-meta.json")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read repository metadata")
+	}
+
+	var plan backupPlan
+
+	err = b.readArchiveFile(config, &plan, config.CBMConfig.Repository, date, "plan.json")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read backup plan")
+	}
+
+	return &value.BackupMetadata{
+		StorageVersion: meta.Version,
+		Storage:        meta.Storage,
+		Shards:         plan.Shards,
+		Encrypted:      meta.Encrypted,
+		Compressed:     plan.Compressed,
+		PointInTime:    meta.PointInTime,
+	}, nil
+}
+
+// readArchiveFile decodes the JSON file at the given path (relative to the archive) into the provided value, the file
+// is downloaded using the 'aws' cli for cloud archives.
+func (b *BackupClient) readArchiveFile(config *value.BenchmarkConfig, decoded any, path ...string) error {
+	var (
+		relative = strings.Join(path, "/")
+		command  value.Command
+	)
+
+	if strings.HasPrefix(config.CBMConfig.Archive, "s3://") {
+		cp := awsEnvironment(config.CBMConfig) +
+			fmt.Sprintf("aws s3 cp %s/%s -", strings.TrimSuffix(config.CBMConfig.Archive, "/"), relative)
+
+		if config.CBMConfig.ObjEndpoint != "" {
+			cp += fmt.Sprintf(" --endpoint=%s", config.CBMConfig.ObjEndpoint)
+		}
+
+		command = value.NewCommand("%s", cp)
+	} else {
+		command = value.NewCommand("cat %s", filepath.Join(config.CBMConfig.Archive, relative))
+	}
+
+	output, err := b.node.client.ExecuteCommand(command)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read '%s'", relative)
+	}
+
+	err = json.Unmarshal(output, decoded)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode '%s'", relative)
+	}
+
+	return nil
+}
+
+// restoreBackup will run a restore of the backups in the repository between start and end, empty values will restore
+// all the backups in the repository.
+func (b *BackupClient) restoreBackup(config *value.BenchmarkConfig, cluster *Cluster, start, end string) error {
+	fields := log.Fields{
+		"blackhole": config.CBMConfig.Blackhole,
+		"hosts":     cluster.hosts(),
+		"start":     start,
+		"end":       end,
+		"bucket":    cluster.restoreBucket(),
+	}
+
+	log.WithFields(fields).Info("Restoring backup")
+
+	command := config.CBMConfig.CommandRestore(cluster.ConnectionString(config.CBMConfig.TLS), cluster.credentials(),
+		start, end, cluster.blueprint.Bucket.TargetBucket)
+
+	// The number of restored items can only be tracked when restoring into an empty bucket
+	var progress progressFunc
+	if !config.CBMConfig.Blackhole && !config.RestoreIntoExisting {
+		progress = cluster.itemProgress(cluster.restoreBucket(), uint64(cluster.blueprint.Bucket.Data.Items))
+	}
+
+	stop := heartbeat("Restoring backup", progress, formatCount)
+	defer stop()
+
+	_, err := b.node.client.ExecuteCommand(command)
+
+	return err
+}
+
+// sizeProgress returns a progress function which reports the combined size of the archive/staging directories, the
+// total is unknown since it depends on how well the data compresses.
+func (b *BackupClient) sizeProgress(config *value.BenchmarkConfig) progressFunc {
+	paths := watchedPaths(config)
+	if len(paths) == 0 {
+		return nil
+	}
+
+	return func() (uint64, uint64, error) {
+		var size uint64
+
+		for _, path := range paths {
+			if !b.node.client.FileExists(path) {
+				continue
+			}
+
+			current, err := b.node.client.DirectorySize(path)
+			if err != nil {
+				return 0, 0, err
+			}
+
+			size += current
+		}
+
+		return size, 0, nil
+	}
+}
+
+// purgeArchive ensures our workspace is clean, we don't want any existing files to get in the way.
+func (b *BackupClient) purgeArchive(config *value.BenchmarkConfig) error {
+	if !strings.HasPrefix(config.CBMConfig.Archive, "s3://") {
+		log.WithField("archive", config.CBMConfig.Archive).Info("Purging local archive")
+		return b.node.client.RemoveDirectory(config.CBMConfig.Archive)
+	}
+
+	log.WithField("archive", config.CBMConfig.Archive).Info("Purging remote archive")
+
+	command := awsEnvironment(config.CBMConfig) + fmt.Sprintf("aws s3 rm %s --recursive", config.CBMConfig.Archive)
+
+	if config.CBMConfig.ObjEndpoint != "" {
+		command += fmt.Sprintf(" --endpoint=%s", config.CBMConfig.ObjEndpoint)
+	}
+
+	// We're using S3 backup, use the AWS cli to ensure the remote archive has been removed
+	_, err := b.node.client.ExecuteCommand(value.NewCommand("%s", command))
+	if err != nil {
+		return errors.Wrap(err, "failed to purge remote archive")
+	}
+
+	log.WithField("staging_directory", config.CBMConfig.ObjStagingDirectory).Info("Purging local staging directory")
+
+	// The staging directory may be a tmpfs mount point, in which case we must only remove its contents
+	if config.StagingTmpfsSize != "" {
+		return b.node.client.EmptyDirectory(config.CBMConfig.ObjStagingDirectory)
+	}
+
+	return b.node.client.RemoveDirectory(config.CBMConfig.ObjStagingDirectory)
+}
+
+// countObjects returns the number/total size of the objects which make up the backups with the given dates, or the
+// entire repository when no dates are provided. Always returns zero for local archives.
+func (b *BackupClient) countObjects(config *value.BenchmarkConfig, dates ...string) (uint64, uint64, error) {
+	if !strings.HasPrefix(config.CBMConfig.Archive, "s3://") {
+		return 0, 0, nil
+	}
+
+	repository := strings.TrimSuffix(config.CBMConfig.Archive, "/") + "/" + config.CBMConfig.Repository + "/"
+
+	prefixes := []string{repository}
+	if len(dates) != 0 {
+		prefixes = make([]string, 0, len(dates))
+
+		for _, date := range dates {
+			prefixes = append(prefixes, repository+date+"/")
+		}
+	}
+
+	var objects, size uint64
+
+	for _, prefix := range prefixes {
+		command := awsEnvironment(config.CBMConfig) + fmt.Sprintf("aws s3 ls %s --recursive --summarize", prefix)
+
+		if config.CBMConfig.ObjEndpoint != "" {
+			command += fmt.Sprintf(" --endpoint=%s", config.CBMConfig.ObjEndpoint)
+		}
+
+		output, err := b.node.client.ExecuteCommand(value.NewCommand("%s", command))
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "failed to list objects in '%s'", prefix)
+		}
 
-	_, err := b.node.client.ExecuteCommand(config.CBMConfig.CommandConfig())
+		match := regexp.MustCompile(value.RegexS3Summary).FindStringSubmatch(string(output))
+		if match == nil {
+			return 0, 0, errors.Errorf("failed to find summary in output for '%s'", prefix)
+		}
 
-	return err
+		count, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "failed to parse object count")
+		}
+
+		bytes, err := strconv.ParseUint(match[2], 10, 64)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "failed to parse total size")
+		}
+
+		objects += count
+		size += bytes
+	}
+
+	return objects, size, nil
 }
 
-// runPreBenchmarkTasks will run any pre-benchmark tasks on the backup client. For example, we should always flush the
-// caches prior to running a benchmark.
-func (b *BackupClient) runPreBenchmarkTasks() error {
-	log.Info("Running backup client pre-benchmark tasks")
+// awsEnvironment returns a prefix for 'aws' commands which exports the credentials/region used by 'cbbackupmgr'.
+func awsEnvironment(config *value.CBMConfig) string {
+	var env string
 
-	return b.node.client.FlushCaches()
+	if config.ObjAccessKeyID != "" {
+		env += fmt.Sprintf("export AWS_ACCESS_KEY_ID=%s; ", config.ObjAccessKeyID)
+	}
+
+	if config.ObjSecretAccessKey != "" {
+		env += fmt.Sprintf("export AWS_SECRET_ACCESS_KEY=%s; ", config.ObjSecretAccessKey)
+	}
+
+	if config.ObjSessionToken != "" {
+		env += fmt.Sprintf("export AWS_SESSION_TOKEN=%s; ", config.ObjSessionToken)
+	}
+
+	if config.ObjRegion != "" {
+		env += fmt.Sprintf("export AWS_REGION=%s; ", config.ObjRegion)
+	}
+
+	return env
 }
 
-// createBackup creates a backup of the provided cluster, note that the 'ignoreBlackhole' argument is required to allow
-// benchmarking restore to blackhole i.e. we must create a backup to restore.
-func (b *BackupClient) createBackup(config *value.BenchmarkConfig, cluster *Cluster,
-	ignoreBlackhole bool,
-) (*value.BackupInfo, error) {
-	fields := log.Fields{
-		"blackhole": config.CBMConfig.Blackhole,
-		"hosts":     cluster.hosts(),
+// cleanupFailedBackups makes a best-effort attempt to remove any partially created backups (and the staging data for
+// cloud archives) after a backup benchmark has failed, so that they don't poison subsequent iterations. Failures are
+// logged rather than returned, since the original error is more useful.
+func (b *BackupClient) cleanupFailedBackups(tasks []*value.BenchmarkConfig, retain []int) {
+	log.Warn("Backup failed, cleaning up partially created backups")
+
+	for idx, task := range tasks {
+		err := b.purgeBackups(task, retain[idx])
+		if err != nil {
+			log.Warnf("Failed to remove partial backup from repository '%s': %s", task.CBMConfig.Repository, err)
+		}
 	}
 
-	log.WithFields(fields).Info("Creating backup")
+	staging := tasks[0].CBMConfig.ObjStagingDirectory
+	if staging == "" {
+		return
+	}
 
-	command := config.CBMConfig.CommandBackup(cluster.ConnectionString(config.CBMConfig.TLS), ignoreBlackhole)
+	log.WithField("staging_directory", staging).Info("Clearing local staging directory")
 
-	_, err := b.node.client.ExecuteCommand(command)
+	err := b.node.client.EmptyDirectory(staging)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to run backup")
+		log.Warnf("Failed to clear staging directory '%s': %s", staging, err)
 	}
+}
 
-	// All the data should be synced to disk by cbbackupmgr, however, for good measure we'll sync now
-	err = b.node.client.Sync()
+// purgeBackups uses the remove sub-command to purged all the backups we've created, retaining the given number of
+// oldest backups (for example those which existed in a kept archive). Note that we use remove instead of doing this
+// manually so that we don't have to handle removing cloud data i.e. that's handled by cbbackupmgr.
+//
+// NOTE: We only want to purge the backups we created and not the whole archive. We might be collecting the logs upon
+// completion, therefore, we want all the benchmarks run against the same archive.
+func (b *BackupClient) purgeBackups(config *value.BenchmarkConfig, retain int) error {
+	log.Info("Purging created backups")
+
+	backups, err := b.listBackups(config)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to sync data to disk")
+		return errors.Wrap(err, "failed to list backups")
+	}
+
+	if len(backups) <= retain {
+		return nil
 	}
 
+	_, err = b.node.client.ExecuteCommand(
+		config.CBMConfig.CommandRemove(backups[retain].Date, backups[len(backups)-1].Date),
+	)
+
+	return err
+}
+
+// listBackups uses the info sub-command to list the backups in the repository, these are ordered from oldest to newest.
+func (b *BackupClient) listBackups(config *value.BenchmarkConfig) ([]*backupOverview, error) {
 	output, err := b.node.client.ExecuteCommand(config.CBMConfig.CommandInfo())
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to run info")
 	}
 
-	type overlayBucket struct {
-		Items uint64 `json:"total_mutations"`
+	type overlay struct {
+		Backups []*backupOverview `json:"backups"`
 	}
 
-	type overlayBackup struct {
-		Size    uint64          `json:"size"`
-		Buckets []overlayBucket `json:"buckets"`
+	var decoded overlay
+
+	err = json.Unmarshal(output, &decoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode info output")
 	}
 
-	type overlay struct {
-		Backups []overlayBackup `json:"backups"`
+	return decoded.Backups, nil
+}
+
+// measureBandwidth measures the throughput from the given node to the backup client (the direction data is transferred
+// when backing up) by running a one-off 'iperf3' server on the node, which sends to the client.
+func (b *BackupClient) measureBandwidth(node *Node) (*value.Bandwidth, error) {
+	cleanup, err := ensureIperf(node)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to install 'iperf3' on node")
 	}
+	defer cleanup()
 
-	var decoded overlay
+	// The server will exit once it has handled a single client, we give it a moment to start listening
+	_, err = node.client.ExecuteCommand(value.NewCommand("iperf3 --server --daemon --one-off && sleep 1"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start 'iperf3' server")
+	}
+
+	// Ensure the server doesn't outlive a failed measurement, it's expected to have already exited upon success. The
+	// brackets stop the pattern matching the shell running 'pkill'.
+	defer func() { _, _ = node.client.ExecuteCommand(value.NewCommand("pkill -f '[i]perf3 --server' || true")) }()
+
+	output, err := b.node.client.ExecuteCommand(
+		value.NewCommand("iperf3 --client %s --reverse --time %d --json", node.blueprint.Host, iperfDuration))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run 'iperf3' client")
+	}
+
+	var decoded struct {
+		End struct {
+			SumReceived struct {
+				BitsPerSecond float64 `json:"bits_per_second"`
+			} `json:"sum_received"`
+		} `json:"end"`
+	}
 
 	err = json.Unmarshal(output, &decoded)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to decode info output")
+		return nil, errors.Wrap(err, "failed to decode 'iperf3' output")
 	}
 
-	backupInfo := &value.BackupInfo{
-		// On each iteration we only do one backup so we only care about the size of the first and only backup in the
-		// list
-		BackupSize: decoded.Backups[0].Size,
-		// We are only backing up one bucket so we can get the number of items from the first and only bucket
-		// NOTE: This is subject to change, the number of items will need to be collected across all buckets if we add
-		// support for testing backups/restores with multiple buckets
-		ItemsNum: decoded.Backups[0].Buckets[0].Items,
+	bandwidth := &value.Bandwidth{
+		Host:          node.blueprint.Host,
+		BitsPerSecond: uint64(decoded.End.SumReceived.BitsPerSecond),
 	}
 
-	return backupInfo, nil
+	fields := log.Fields{"host": bandwidth.Host, "bandwidth": format.Bytes(bandwidth.BytesPerSecond()) + "/s"}
+	log.WithFields(fields).Info("Measured network bandwidth")
+
+	return bandwidth, nil
 }
 
-// restoreBackup will run a restore of the backups in the repository, realistically there should only be a single
-// backup.
-func (b *BackupClient) restoreBackup(config *value.BenchmarkConfig, cluster *Cluster) error {
-	fields := log.Fields{
-		"blackhole": config.CBMConfig.Blackhole,
-		"hosts":     cluster.hosts(),
+// ensureIperf installs 'iperf3' on the given node if it's missing, the returned function removes it again and should
+// be called once it's no longer required (it's a no-op when 'iperf3' was already installed).
+func ensureIperf(node *Node) (func(), error) {
+	if node.client.CommandExists("iperf3") {
+		return func() {}, nil
 	}
 
-	log.WithFields(fields).Info("Restoring backup")
+	log.WithField("host", node.blueprint.Host).Info("Installing 'iperf3'")
 
-	command := config.CBMConfig.CommandRestore(cluster.ConnectionString(config.CBMConfig.TLS))
+	err := node.client.InstallPackages("iperf3")
+	if err != nil {
+		return nil, err
+	}
 
-	_, err := b.node.client.ExecuteCommand(command)
+	cleanup := func() {
+		log.WithField("host", node.blueprint.Host).Info("Removing 'iperf3'")
 
-	return err
+		err := node.client.UninstallPackages("iperf3")
+		if err != nil {
+			log.Warnf("Failed to remove 'iperf3' from '%s': %s", node.blueprint.Host, err)
+		}
+	}
+
+	return cleanup, nil
 }
 
-// purgeArchive ensures our workspace is clean, we don't want any existing files to get in the way.
-func (b *BackupClient) purgeArchive(config *value.BenchmarkConfig) error {
-	if !strings.HasPrefix(config.CBMConfig.Archive, "s3://") {
-		log.WithField("archive", config.CBMConfig.Archive).Info("Purging local archive")
-		return b.node.client.RemoveDirectory(config.CBMConfig.Archive)
+// validateRestoreConfig ensures that the restore related options in the given config are compatible. A target bucket
+// must be configured when 'cbbackupmgr' is automatically creating buckets, since the benchmarking bucket must never be
+// deleted; it's the source of the backups (and KV stats).
+func validateRestoreConfig(config *value.BenchmarkConfig, cluster *Cluster) error {
+	if config.ArchiveDeviceMode == value.ArchiveDeviceModeStripe && len(config.ArchiveDevices) != 0 {
+		return errors.New("striping across archive devices is only supported by backup benchmarks")
 	}
 
-	log.WithField("archive", config.CBMConfig.Archive).Info("Purging remote archive")
+	if config.VerifyIntegrity && (config.ExistingArchive || config.CBMConfig.Blackhole) {
+		return errors.New("verifying integrity is not supported when using an existing archive or restoring to " +
+			"blackhole, since the restored documents can't be compared with the benchmarking bucket")
+	}
 
-	var command string
+	if !config.CBMConfig.AutoCreateBuckets {
+		return nil
+	}
 
-	if config.CBMConfig.ObjAccessKeyID != "" {
-		command += fmt.Sprintf("export AWS_ACCESS_KEY_ID=%s; ", config.CBMConfig.ObjAccessKeyID)
+	if cluster.blueprint.Bucket.TargetBucket == "" {
+		return errors.New("restore benchmarks using 'auto_create_buckets' require a 'target_bucket' to be configured")
 	}
 
-	if config.CBMConfig.ObjSecretAccessKey != "" {
-		command += fmt.Sprintf("export AWS_SECRET_ACCESS_KEY=%s; ", config.CBMConfig.ObjSecretAccessKey)
+	if config.RestoreIntoExisting {
+		return errors.New("'auto_create_buckets' and 'restore_into_existing' are mutually exclusive")
 	}
 
-	if config.CBMConfig.ObjRegion != "" {
-		command += fmt.Sprintf("export AWS_REGION=%s; ", config.CBMConfig.ObjRegion)
+	return nil
+}
+
+// prepareRestoreBucket ensures the bucket being restored into is empty prior to a restore benchmark (unless restoring
+// into existing data); it's deleted when 'cbbackupmgr' will be automatically creating it, otherwise it's flushed.
+func prepareRestoreBucket(config *value.BenchmarkConfig, cluster *Cluster) error {
+	if config.CBMConfig.Blackhole || config.RestoreIntoExisting {
+		return nil
 	}
 
-	command += fmt.Sprintf("aws s3 rm %s --recursive", config.CBMConfig.Archive)
+	if config.CBMConfig.AutoCreateBuckets {
+		return cluster.deleteBucket(cluster.restoreBucket())
+	}
 
-	if config.CBMConfig.ObjEndpoint != "" {
-		command += fmt.Sprintf(" --endpoint=%s", config.CBMConfig.ObjEndpoint)
+	return cluster.flushBucket(cluster.restoreBucket())
+}
+
+// primeRestoreBucket performs an untimed restore into the target bucket when restoring into existing data, ensuring
+// that the first benchmark doesn't restore into an empty bucket. This isn't required when restoring into the
+// benchmarking bucket, since it already contains the data which was backed up.
+func (b *BackupClient) primeRestoreBucket(config *value.BenchmarkConfig, cluster *Cluster) error {
+	if !config.RestoreIntoExisting || config.CBMConfig.Blackhole || cluster.blueprint.Bucket.TargetBucket == "" {
+		return nil
+	}
+
+	log.WithField("bucket", cluster.restoreBucket()).Info("Priming bucket with existing data")
+
+	return b.restoreBackup(config, cluster, "", "")
+}
+
+// setTargetCompactionConcurrency applies the compaction concurrency to the target bucket prior to restoring into it,
+// since it may have been recreated since provisioning. Buckets which are automatically created by 'cbbackupmgr' don't
+// exist until the restore has started, so use the default compaction concurrency.
+func setTargetCompactionConcurrency(config *value.BenchmarkConfig, cluster *Cluster) error {
+	if cluster.restoreBucket() == "default" || config.CBMConfig.Blackhole || config.CBMConfig.AutoCreateBuckets {
+		return nil
+	}
+
+	return cluster.setCompactionConcurrency(cluster.restoreBucket())
+}
+
+// runIteration runs a single benchmark iteration, snapshotting the KV stats for the given bucket before/after,
+// measuring the CPU time used on the backup client, counting the requests made to the object store and watching the
+// free space on the backup client for the duration of the benchmark.
+func (b *BackupClient) runIteration(config *value.BenchmarkConfig, cluster *Cluster, bucket string,
+	benchmark func() (*value.BenchmarkResult, error),
+) (*value.BenchmarkResult, error) {
+	var (
+		stop    = b.watchDiskSpace(config)
+		stopped = b.watchProgress(config)
+	)
+
+	requests, reqErr := b.countRequests(config)
+
+	before, cpuErr := b.node.client.CPUTime()
+
+	result, err := withKVStats(cluster, bucket, benchmark)
+
+	// Running out of space/stalling is the more useful error, since it's likely the cause of the benchmark failing
+	if watchErr := cmp.Or(stop(), stopped()); watchErr != nil {
+		return nil, watchErr
 	}
 
-	// We're using S3 backup, use the AWS cli to ensure the remote archive has been removed
-	_, err := b.node.client.ExecuteCommand(value.NewCommand(command))
 	if err != nil {
-		return errors.Wrap(err, "failed to purge remote archive")
+		return nil, err
 	}
 
-	log.WithField("staging_directory", config.CBMConfig.ObjStagingDirectory).Info("Purging local staging directory")
+	b.recordRequests(config, result, requests, reqErr)
 
-	return b.node.client.RemoveDirectory(config.CBMConfig.ObjStagingDirectory)
+	var after time.Duration
+	if cpuErr == nil {
+		after, cpuErr = b.node.client.CPUTime()
+	}
+
+	if cpuErr != nil {
+		log.Warnf("Failed to measure backup client CPU time, it will be omitted from the report: %s", cpuErr)
+		return result, nil
+	}
+
+	result.CPUTime = after - before
+
+	return result, nil
 }
 
-// purgeBackups uses the remove sub-command to purged all the backups we've created. Note that we use remove instead of
-// doing this manually so that we don't have to handle removing cloud data i.e. that's handled by cbbackupmgr.
-//
-// NOTE: We only want to purge the backups we created and not the whole archive. We might be collecting the logs upon
-// completion, therefore, we want all the benchmarks run against the same archive.
-func (b *BackupClient) purgeBackups(config *value.BenchmarkConfig) error {
-	log.Info("Purging created backups")
+// recordRequests populates the requests made to the object store by the given benchmark, using the snapshot of the
+// request counts taken before it was run. Failing to count the requests isn't fatal, they're omitted instead.
+func (b *BackupClient) recordRequests(config *value.BenchmarkConfig, result *value.BenchmarkResult,
+	before value.S3Requests, err error,
+) {
+	var after value.S3Requests
+	if err == nil {
+		after, err = b.countRequests(config)
+	}
 
-	output, err := b.node.client.ExecuteCommand(config.CBMConfig.CommandInfo())
 	if err != nil {
-		return errors.Wrap(err, "failed to run info")
+		log.Warnf("Failed to count object store requests, estimates will be used instead: %s", err)
+		return
 	}
 
-	type backup struct {
-		Date string `json:"date"`
+	if after == nil {
+		return
 	}
 
-	type overlay struct {
-		Backups []backup `json:"backups"`
+	result.Requests = after.Sub(before)
+	result.PutRequests = result.Requests.Put()
+	result.GetRequests = result.Requests.Get()
+	result.ListRequests = result.Requests.List()
+}
+
+// countRequests returns the number of requests made to the object store for each S3 operation, by parsing the S3 SDK
+// debug logs written by 'cbbackupmgr'. Returns nil when not counting requests.
+func (b *BackupClient) countRequests(config *value.BenchmarkConfig) (value.S3Requests, error) {
+	if !config.CBMConfig.ObjCountRequests || !strings.HasPrefix(config.CBMConfig.Archive, "s3://") {
+		return nil, nil
 	}
 
-	var decoded overlay
+	logs := filepath.Join(logsDirectory(config), "*.log")
 
-	err = json.Unmarshal(output, &decoded)
+	output, err := b.node.client.ExecuteCommand(
+		value.NewCommand(`(grep -oh 'Request s3/[A-Za-z0-9]*' %s || true) | sort | uniq -c`, logs))
 	if err != nil {
-		return errors.Wrap(err, "failed to unmarshal info output")
+		return nil, errors.Wrap(err, "failed to count requests in logs")
 	}
 
-	if len(decoded.Backups) == 0 {
-		return nil
+	requests := make(value.S3Requests)
+
+	for _, match := range regexp.MustCompile(value.RegexS3Request).FindAllStringSubmatch(string(output), -1) {
+		count, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse request count for '%s'", match[2])
+		}
+
+		requests[match[2]] = count
 	}
 
-	_, err = b.node.client.ExecuteCommand(
-		config.CBMConfig.CommandRemove(decoded.Backups[0].Date, decoded.Backups[len(decoded.Backups)-1].Date),
+	return requests, nil
+}
+
+// watchDiskSpace starts a background watcher which periodically checks the free space on the filesystems used by the
+// archive/staging directory. When the configured threshold is crossed, running 'cbbackupmgr' processes are terminated
+// so that the benchmark is aborted early; the returned function stops the watcher, returning the reason for aborting.
+func (b *BackupClient) watchDiskSpace(config *value.BenchmarkConfig) func() error {
+	paths := watchedPaths(config)
+
+	if config.MinFreeSpacePercentage == 0 || len(paths) == 0 {
+		return func() error { return nil }
+	}
+
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		done        = make(chan struct{})
+		exceeded    error
 	)
 
-	return err
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(diskSpaceInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			exceeded = b.checkDiskSpace(paths, config.MinFreeSpacePercentage)
+			if exceeded == nil {
+				continue
+			}
+
+			log.Errorf("Aborting benchmark: %s", exceeded)
+
+			_, err := b.node.client.ExecuteCommand(value.NewCommand("pkill cbbackupmgr || true"))
+			if err != nil {
+				log.Warnf("Failed to terminate 'cbbackupmgr': %s", err)
+			}
+
+			return
+		}
+	}()
+
+	return func() error {
+		cancel()
+		<-done
+
+		return exceeded
+	}
+}
+
+// watchProgress starts a background watcher which periodically checks whether the benchmark is making progress, by
+// checking whether the archive/staging directory or the 'cbbackupmgr' logs have changed. When no progress has been
+// observed for the configured timeout, running 'cbbackupmgr' processes are terminated so that the benchmark is aborted
+// early; the returned function stops the watcher, returning the reason for aborting.
+func (b *BackupClient) watchProgress(config *value.BenchmarkConfig) func() error {
+	if config.StallTimeout == 0 {
+		return func() error { return nil }
+	}
+
+	var (
+		timeout     = time.Duration(config.StallTimeout) * time.Second
+		paths       = append(watchedPaths(config), logsDirectory(config))
+		ctx, cancel = context.WithCancel(context.Background())
+		done        = make(chan struct{})
+		stalled     error
+	)
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(min(progressInterval, timeout))
+		defer ticker.Stop()
+
+		var (
+			last       string
+			progressed = time.Now()
+		)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := b.progress(paths)
+			if err != nil {
+				log.Warnf("Failed to check benchmark progress: %s", err)
+				continue
+			}
+
+			if current != last {
+				last, progressed = current, time.Now()
+				continue
+			}
+
+			if time.Since(progressed) < timeout {
+				continue
+			}
+
+			stalled = errors.Errorf("no progress has been observed for %s, the archive/logs haven't changed since %s",
+				format.Duration(timeout), progressed.UTC().Format(time.RFC3339))
+
+			log.Errorf("Aborting benchmark: %s\n%s", stalled, b.stallDiagnostic(config))
+
+			_, err = b.node.client.ExecuteCommand(value.NewCommand("pkill cbbackupmgr || true"))
+			if err != nil {
+				log.Warnf("Failed to terminate 'cbbackupmgr': %s", err)
+			}
+
+			return
+		}
+	}()
+
+	return func() error {
+		cancel()
+		<-done
+
+		return stalled
+	}
+}
+
+// progress returns a snapshot of the sizes of the given paths, a change in the snapshot indicates that the benchmark
+// is making progress. Paths which don't exist (yet) are ignored.
+func (b *BackupClient) progress(paths []string) (string, error) {
+	output, err := b.node.client.ExecuteCommand(
+		value.NewCommand("du -sb %s 2>/dev/null || true", strings.Join(paths, " ")))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get size of archive/logs")
+	}
+
+	return string(output), nil
+}
+
+// stallDiagnostic returns diagnostic information about a stalled benchmark (the running 'cbbackupmgr' processes and the
+// tail of the most recent log), errors are included in the output since it's purely informational.
+func (b *BackupClient) stallDiagnostic(config *value.BenchmarkConfig) string {
+	output, err := b.node.client.ExecuteCommand(value.NewCommand(
+		"ps -o pid,etime,pcpu,rss,args -C cbbackupmgr; tail -n 20 $(ls -t %s | head -1)",
+		filepath.Join(logsDirectory(config), "*.log")))
+	if err != nil {
+		return fmt.Sprintf("failed to collect diagnostics: %s", err)
+	}
+
+	return strings.TrimSpace(string(output))
+}
+
+// checkDiskSpace returns an error if any of the given paths are on a filesystem with less than the given percentage of
+// free space. Paths which can't be checked (for example, because they don't exist yet) are ignored.
+func (b *BackupClient) checkDiskSpace(paths []string, minFree int) error {
+	for _, path := range paths {
+		output, err := b.node.client.ExecuteCommand(value.NewCommand("df --output=pcent %s | tail -1 | tr -d ' %%'", path))
+		if err != nil {
+			log.Warnf("Failed to check free space for '%s': %s", path, err)
+			continue
+		}
+
+		used, err := strconv.Atoi(strings.TrimSpace(string(output)))
+		if err != nil {
+			log.Warnf("Failed to parse used space for '%s': %s", path, err)
+			continue
+		}
+
+		if free := 100 - used; free < minFree {
+			return errors.Errorf("filesystem for '%s' has %d%% free space, which is below the threshold of %d%%", path,
+				free, minFree)
+		}
+	}
+
+	return nil
+}
+
+// watchedPaths returns the local paths whose free space should be watched during benchmarks, this is the archive(s)
+// when not using cloud storage and the staging directory when one is configured.
+func watchedPaths(config *value.BenchmarkConfig) []string {
+	var paths []string
+
+	for _, task := range config.Tasks() {
+		if !strings.HasPrefix(task.CBMConfig.Archive, "s3://") && !slices.Contains(paths, task.CBMConfig.Archive) {
+			paths = append(paths, task.CBMConfig.Archive)
+		}
+	}
+
+	if config.CBMConfig.ObjStagingDirectory != "" {
+		paths = append(paths, config.CBMConfig.ObjStagingDirectory)
+	}
+
+	return paths
+}
+
+// withKVStats runs the given benchmark, capturing a snapshot of the KV stats for the given bucket immediately
+// before/after it and attaching them to the result.
+func withKVStats(cluster *Cluster, bucket string,
+	benchmark func() (*value.BenchmarkResult, error),
+) (*value.BenchmarkResult, error) {
+	before, err := cluster.KVStats(bucket)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get KV stats before benchmark")
+	}
+
+	result, err := benchmark()
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := cluster.KVStats(bucket)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get KV stats after benchmark")
+	}
+
+	result.KVStatsBefore, result.KVStatsAfter = before, after
+
+	return result, nil
 }
 
 // Close the connection to the backup client.
 func (b *BackupClient) Close() error {
+	if b.colocated {
+		return nil
+	}
+
 	return b.node.Close()
 }
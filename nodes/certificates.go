@@ -0,0 +1,372 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// certificateInbox is the directory, relative to the install directory, from which Couchbase Server loads the node
+	// certificate/private key.
+	certificateInbox = "var/lib/couchbase/inbox"
+
+	// clusterCAPath is the path the CA certificate is uploaded to on the controller, before it's added to the cluster.
+	clusterCAPath = "/tmp/autobench-ca.pem"
+
+	// clientCAPath is the path the trusted CAs for the cluster are uploaded to on the backup client.
+	clientCAPath = "/tmp/autobench-cluster-ca.pem"
+
+	// clientAuthPath is the path the client certificate authentication settings are uploaded to on the controller.
+	clientAuthPath = "/tmp/autobench-client-auth.json"
+
+	// clientAuthSettings enables (optional) client certificate authentication, using the common name of the client
+	// certificate as the username.
+	clientAuthSettings = `{"state":"enable","prefixes":[{"path":"subject.cn","prefix":"","delimiter":""}]}`
+
+	// certificateValidity is how long the generated certificates are valid for.
+	certificateValidity = 365 * 24 * time.Hour
+
+	// certificateKeySize is the size of the generated RSA keys.
+	certificateKeySize = 2048
+)
+
+// certificateAuthority is used to sign the certificates for each node in the cluster.
+type certificateAuthority struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+	pem  []byte
+}
+
+// newCertificateAuthority loads the CA using the given config, generating a self-signed CA if none is configured.
+func newCertificateAuthority(config *value.TLSConfig) (*certificateAuthority, error) {
+	if config.GeneratedCA() {
+		return generateCertificateAuthority()
+	}
+
+	certPEM, err := os.ReadFile(config.CACertPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read CA certificate")
+	}
+
+	keyPEM, err := os.ReadFile(config.CAKeyPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read CA private key")
+	}
+
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse CA certificate/private key")
+	}
+
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse CA certificate")
+	}
+
+	if !cert.IsCA {
+		return nil, errors.Errorf("certificate '%s' is not a CA certificate", config.CACertPath)
+	}
+
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported CA private key type")
+	}
+
+	return &certificateAuthority{cert: cert, key: key, pem: encodeCertificate(pair.Certificate[0])}, nil
+}
+
+// generateCertificateAuthority generates a self-signed CA.
+func generateCertificateAuthority() (*certificateAuthority, error) {
+	key, err := rsa.GenerateKey(rand.Reader, certificateKeySize)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate private key")
+	}
+
+	template, err := certificateTemplate("cbtools-autobench CA")
+	if err != nil {
+		return nil, err
+	}
+
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create certificate")
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse certificate")
+	}
+
+	return &certificateAuthority{cert: cert, key: key, pem: encodeCertificate(der)}, nil
+}
+
+// sign generates a private key and certificate for the given host signed by the CA, returning them PEM encoded. The
+// certificate is also valid for 'localhost', since that's how the CLI connects to each node.
+func (c *certificateAuthority) sign(host string) ([]byte, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, certificateKeySize)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate private key")
+	}
+
+	template, err := certificateTemplate(host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	template.DNSNames = []string{"localhost"}
+	template.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = append(template.IPAddresses, ip)
+	} else {
+		template.DNSNames = append(template.DNSNames, host)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, c.cert, key.Public(), c.key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create certificate")
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	return encodeCertificate(der), keyPEM, nil
+}
+
+// certificateTemplate returns a certificate template with the given common name, and a random serial number.
+func certificateTemplate(name string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate serial number")
+	}
+
+	now := time.Now()
+
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certificateValidity),
+	}, nil
+}
+
+// encodeCertificate returns the given DER encoded certificate PEM encoded.
+func encodeCertificate(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// configureCertificates adds the configured (or a generated) CA to the trusted CAs for the cluster, then sets a
+// certificate signed by it on each node.
+func (c *Cluster) configureCertificates() error {
+	if c.blueprint.TLS == nil {
+		return nil
+	}
+
+	ca, err := newCertificateAuthority(c.blueprint.TLS)
+	if err != nil {
+		return errors.Wrap(err, "failed to load CA")
+	}
+
+	err = c.uploadClusterCA(ca)
+	if err != nil {
+		return errors.Wrap(err, "failed to upload cluster CA")
+	}
+
+	err = c.setNodeCertificates(ca, c.nodes)
+	if err != nil {
+		return err
+	}
+
+	if !c.blueprint.TLS.ClientAuth {
+		return nil
+	}
+
+	err = c.enableClientAuth()
+	if err != nil {
+		return errors.Wrap(err, "failed to enable client certificate authentication")
+	}
+
+	return nil
+}
+
+// configureAddedCertificates sets a certificate signed by the configured CA on each of the given nodes, which have just
+// been added into the cluster.
+func (c *Cluster) configureAddedCertificates(added []*Node) error {
+	if c.blueprint.TLS == nil || len(added) == 0 {
+		return nil
+	}
+
+	ca, err := newCertificateAuthority(c.blueprint.TLS)
+	if err != nil {
+		return errors.Wrap(err, "failed to load CA")
+	}
+
+	return c.setNodeCertificates(ca, added)
+}
+
+// uploadClusterCA adds the given CA to the trusted CAs for the cluster.
+func (c *Cluster) uploadClusterCA(ca *certificateAuthority) error {
+	log.WithField("subject", ca.cert.Subject.String()).Info("Uploading cluster CA")
+
+	err := c.controller().WriteFile(clusterCAPath, ca.pem)
+	if err != nil {
+		return errors.Wrap(err, "failed to write CA certificate")
+	}
+
+	_, err = c.controller().ExecuteCommand(value.NewCommand(
+		`couchbase-cli ssl-manage -c %s %s --upload-cluster-ca %s`, c.address(), c.cliFlags(), clusterCAPath))
+
+	return err
+}
+
+// enableClientAuth enables client certificate authentication for the cluster.
+func (c *Cluster) enableClientAuth() error {
+	log.Info("Enabling client certificate authentication")
+
+	err := c.controller().WriteFile(clientAuthPath, []byte(clientAuthSettings))
+	if err != nil {
+		return errors.Wrap(err, "failed to write client authentication settings")
+	}
+
+	_, err = c.controller().ExecuteCommand(value.NewCommand(
+		`couchbase-cli ssl-manage -c %s %s --set-client-auth %s`, c.address(), c.cliFlags(), clientAuthPath))
+
+	return err
+}
+
+// setNodeCertificates sets a certificate signed by the given CA on each of the given nodes.
+func (c *Cluster) setNodeCertificates(ca *certificateAuthority, nodes []*Node) error {
+	return c.forNodes(nodes, func(node *Node) error {
+		err := c.setNodeCertificate(ca, node)
+		return errors.Wrapf(err, "failed to set certificate for node '%s'", node.blueprint.Host)
+	})
+}
+
+// setNodeCertificate generates a certificate for the given node, signed by the given CA, then places it (along with
+// its private key) in the inbox before instructing the node to load it.
+func (c *Cluster) setNodeCertificate(ca *certificateAuthority, node *Node) error {
+	log.WithField("host", node.blueprint.Host).Info("Setting node certificate")
+
+	cert, key, err := ca.sign(node.blueprint.Host)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate certificate")
+	}
+
+	inbox := filepath.Join(node.blueprint.Paths.Install(), certificateInbox)
+
+	_, err = node.client.ExecuteCommand(value.NewCommand("mkdir -p %s", inbox))
+	if err != nil {
+		return errors.Wrap(err, "failed to create inbox")
+	}
+
+	err = node.client.WriteFile(filepath.Join(inbox, "chain.pem"), cert)
+	if err != nil {
+		return errors.Wrap(err, "failed to write certificate")
+	}
+
+	err = node.client.WriteFile(filepath.Join(inbox, "pkey.key"), key)
+	if err != nil {
+		return errors.Wrap(err, "failed to write private key")
+	}
+
+	_, err = node.client.ExecuteCommand(value.NewCommand(
+		"chown -R couchbase:couchbase %[1]s && chmod 0700 %[1]s && chmod 0600 %[1]s/pkey.key", inbox))
+	if err != nil {
+		return errors.Wrap(err, "failed to set inbox permissions")
+	}
+
+	_, err = node.client.ExecuteCommand(value.NewCommand(
+		`couchbase-cli ssl-manage -c %s %s --set-node-certificate`, c.address(), c.cliFlags()))
+
+	return err
+}
+
+// trustedCAs returns the PEM encoded CA certificates which are trusted by the cluster.
+func (c *Cluster) trustedCAs() ([]byte, error) {
+	output, err := c.rest.Execute(&restRequest{Endpoint: "/pools/default/trustedCAs"})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get trusted CAs")
+	}
+
+	var decoded []struct {
+		PEM string `json:"pem"`
+	}
+
+	err = json.Unmarshal(output, &decoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal trusted CAs")
+	}
+
+	if len(decoded) == 0 {
+		return nil, errors.New("cluster has no trusted CAs")
+	}
+
+	var buffer bytes.Buffer
+
+	for _, ca := range decoded {
+		buffer.WriteString(ca.PEM)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// PrepareClusterCA uploads the trusted CAs for the cluster onto the backup client, so that 'cbbackupmgr' is able to
+// verify the cluster certificates, returning the path they were uploaded to. This is only performed when using TLS
+// without a configured CA certificate, and without verification being disabled; an empty path is returned otherwise.
+//
+// NOTE: The CAs are uploaded for each run, since the cluster may have been re-provisioned using a different CA.
+func (b *BackupClient) PrepareClusterCA(config *value.BenchmarkConfig, cluster *Cluster) (string, error) {
+	if !config.CBMConfig.TLS || config.CBMConfig.NoSSLVerify || config.CBMConfig.CACert != "" {
+		return "", nil
+	}
+
+	log.WithField("path", clientCAPath).Info("Uploading cluster CA to backup client")
+
+	cas, err := cluster.trustedCAs()
+	if err != nil {
+		return "", err
+	}
+
+	err = b.node.client.WriteFile(clientCAPath, cas)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to write CA certificates")
+	}
+
+	return clientCAPath, nil
+}
@@ -15,12 +15,20 @@
 package nodes
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jamesl33/cbtools-autobench/value"
@@ -33,22 +41,92 @@ import (
 	"github.com/pkg/errors"
 )
 
-// memInfo is a prefix which will be added to commands which require memory/quota based information. For example, when
-// provisioning a bucket we will use 80% of the available memory by default.
+// memInfo is a prefix which will be added to commands which require memory/quota based information, it should be
+// formatted with the percentage of the total memory to use for the data service quota. For example, when initializing
+// the cluster we will use 80% of the available memory by default.
 const memInfo = `
 	FREE=$(free | awk '{ print $2 }' | sed '1d;3d' | awk '{ print int($0 / 1024) }');
-	QUOTA=$(echo $FREE | awk '{ print int($0 * 0.8) }');
+	CLUSTER_QUOTA=$(echo $FREE | awk '{ print int($0 * %d / 100) }');
 `
 
+const (
+	// randomPrefixLength is the length of the prefixes returned by 'randomPrefix'.
+	randomPrefixLength = 7
+
+	// seedPrefix is the key prefix used when mutating data, it's constant so that the same keys are updated each time.
+	seedPrefix = "autobench-seed::"
+
+	// pillowfightKeyLength is the length of the zero padded number appended to each key by 'cbc-pillowfight'.
+	pillowfightKeyLength = 20
+
+	// uuidLength is the length of the UUID appended to each key when using the 'uuid' key distribution.
+	uuidLength = 36
+
+	// importPath is the prefix of the path to the temporary files used when loading data using 'cbimport', each shard
+	// uses its own file since shards may be loaded concurrently on the same node.
+	importPath = "/tmp/autobench-import"
+
+	// deletionsPath is the path to the temporary file used when exporting the keys of the documents to delete.
+	deletionsPath = "/tmp/autobench-deletions.json"
+
+	// loadSummaryPath is the path to the file on the first node which records the summary of the last data load, so
+	// that it may be included in the reports of subsequent benchmarks.
+	loadSummaryPath = "/tmp/autobench-load"
+
+	// defaultCompressionMode is the compression mode used by Couchbase Server when one isn't provided.
+	defaultCompressionMode = "passive"
+
+	// itemCountTimeout is how long we'll wait for the item count to reach the expected value once data is loaded.
+	itemCountTimeout = 5 * time.Minute
+
+	// reconcileTimeout is how long we'll wait for the item count to reach the expected value before reloading shards.
+	reconcileTimeout = time.Minute
+
+	// loadAttempts is the number of times loading a shard will be attempted before giving up.
+	loadAttempts = 3
+)
+
+// loadShard is the portion of the dataset loaded into a single collection by a single node.
+type loadShard struct {
+	node       *Node
+	collection *value.Collection
+	index      int
+	items      int
+
+	// prefix is the random key prefix unique to the shard, it's fixed so that loading the shard again overwrites the
+	// same keys.
+	prefix string
+}
+
 // Cluster represents a connection to a number of nodes in a Couchbase Cluster (note that the cluster may not be setup
 // yet).
 type Cluster struct {
 	blueprint *value.ClusterBlueprint
 	nodes     []*Node
+
+	// control is the machine used to drive an unmanaged cluster, see 'attach'.
+	control *machine
+
+	// rest is the client used to make requests to the cluster manager REST API.
+	rest *restClient
 }
 
-// NewCluster creates a connection to each of the remote cluster nodes using the provided ssh config.
+// errUnmanaged is returned when attempting an operation which requires ssh access to the cluster nodes against an
+// unmanaged cluster.
+var errUnmanaged = errors.New("operation requires ssh access to the cluster nodes, which isn't available for an " +
+	"unmanaged cluster")
+
+// NewCluster creates a connection to each of the remote cluster nodes using the provided ssh config. No connections are
+// created for an unmanaged cluster, it must instead be attached to a backup client (see 'NewColocatedBackupClient').
 func NewCluster(config *value.SSHConfig, blueprint *value.ClusterBlueprint) (*Cluster, error) {
+	if len(blueprint.Nodes) == 0 {
+		return nil, errors.New("cluster blueprint doesn't contain any nodes")
+	}
+
+	if !blueprint.IsManaged() {
+		return newUnmanagedCluster(blueprint), nil
+	}
+
 	var (
 		pool  = hofp.NewPool(hofp.Options{Size: min(system.NumCPU(), len(blueprint.Nodes))})
 		nodes = make([]*Node, len(blueprint.Nodes))
@@ -57,6 +135,8 @@ func NewCluster(config *value.SSHConfig, blueprint *value.ClusterBlueprint) (*Cl
 	connect := func(idx int, nb *value.NodeBlueprint) error {
 		var err error
 
+		nb.Paths = blueprint.CBPaths
+
 		nodes[idx], err = NewNode(config, nb)
 		if err != nil {
 			return err
@@ -80,14 +160,58 @@ func NewCluster(config *value.SSHConfig, blueprint *value.ClusterBlueprint) (*Cl
 		return nil, errors.Wrap(err, "failed to stop pool")
 	}
 
-	return &Cluster{blueprint: blueprint, nodes: nodes}, nil
+	return newCluster(blueprint, nodes), nil
+}
+
+// newCluster creates a cluster for the given nodes, along with the client used to make requests to its REST API.
+func newCluster(blueprint *value.ClusterBlueprint, nodes []*Node) *Cluster {
+	cluster := &Cluster{blueprint: blueprint, nodes: nodes}
+	cluster.rest = newRESTClient(cluster.restURL(), blueprint.RESTConfig(), blueprint.Credentials, cluster.dial)
+
+	return cluster
+}
+
+// newUnmanagedCluster creates a cluster for the nodes of an existing cluster which isn't managed by autobench, the
+// nodes have no connection and all commands are run on the machine the cluster is attached to.
+func newUnmanagedCluster(blueprint *value.ClusterBlueprint) *Cluster {
+	nodes := make([]*Node, 0, len(blueprint.Nodes))
+
+	for _, nb := range blueprint.Nodes {
+		nb.Paths = blueprint.CBPaths
+		nodes = append(nodes, &Node{blueprint: nb})
+	}
+
+	cluster := newCluster(blueprint, nodes)
+
+	log.WithField("hosts", cluster.hosts()).Info("Using unmanaged cluster")
+
+	return cluster
+}
+
+// attach the given machine to the cluster, it'll be used to run all the commands against an unmanaged cluster.
+func (c *Cluster) attach(control *machine) {
+	c.control = control
 }
 
 // Provision will provision the cluster installing Couchbase and any required dependencies.
 func (c *Cluster) Provision() error {
+	if !c.blueprint.IsManaged() {
+		return errUnmanaged
+	}
+
+	err := value.ValidateServices(c.blueprint.Nodes)
+	if err != nil {
+		return errors.Wrap(err, "invalid node services")
+	}
+
+	err = c.blueprint.TLS.Validate()
+	if err != nil {
+		return errors.Wrap(err, "invalid TLS config")
+	}
+
 	log.WithField("hosts", c.hosts()).Info("Provision cluster")
 
-	err := c.provisionNodes()
+	err = c.provisionNodes()
 	if err != nil {
 		return errors.Wrap(err, "failed to provision nodes")
 	}
@@ -97,11 +221,21 @@ func (c *Cluster) Provision() error {
 		return errors.Wrap(err, "failed to initialize Couchbase")
 	}
 
+	err = c.configureCertificates()
+	if err != nil {
+		return errors.Wrap(err, "failed to configure certificates")
+	}
+
 	err = c.enableDeveloperPreviewMode()
 	if err != nil {
 		return errors.Wrap(err, "failed to enable developer preview mode")
 	}
 
+	err = c.setRebalanceMoves()
+	if err != nil {
+		return errors.Wrap(err, "failed to set rebalance moves per node")
+	}
+
 	// Sometimes it's useful to limit the number of vBuckets in the remote cluster when performing testing which is
 	// scaled to simulate a dataset of a certain size.
 	err = c.limitVBuckets()
@@ -109,11 +243,38 @@ func (c *Cluster) Provision() error {
 		return errors.Wrap(err, "failed to limit vBuckets")
 	}
 
-	err = c.createBucket()
+	err = c.createBucket("default")
 	if err != nil {
 		return errors.Wrap(err, "failed to create bucket")
 	}
 
+	err = c.createCollections()
+	if err != nil {
+		return errors.Wrap(err, "failed to create collections")
+	}
+
+	if c.blueprint.Bucket.TargetBucket != "" {
+		err = c.createBucket(c.blueprint.Bucket.TargetBucket)
+		if err != nil {
+			return errors.Wrap(err, "failed to create target bucket")
+		}
+	}
+
+	err = c.validateVBuckets()
+	if err != nil {
+		return errors.Wrap(err, "failed to validate vBuckets")
+	}
+
+	buckets := []string{"default"}
+	if c.blueprint.Bucket.TargetBucket != "" {
+		buckets = append(buckets, c.blueprint.Bucket.TargetBucket)
+	}
+
+	err = c.setCompactionConcurrency(buckets...)
+	if err != nil {
+		return errors.Wrap(err, "failed to set compaction concurrency")
+	}
+
 	// If we request to flush the bucket to close to the creation, we may hit a 500 internal error
 	time.Sleep(30 * time.Second)
 
@@ -121,439 +282,1642 @@ func (c *Cluster) Provision() error {
 }
 
 // LoadData will load the benchmark dataset using the data loader specified in the config. The load phase is sped up by
-// modifying the eviction pager settings to speed up eviction.
-func (c *Cluster) LoadData(compact bool) error {
+// modifying the eviction pager settings to speed up eviction. Cancelling the given context terminates the data loaders
+// running on each node. Returns a summary of the load, which is also recorded on the cluster.
+func (c *Cluster) LoadData(ctx context.Context, compact bool) (*value.LoadSummary, error) {
+	if !c.blueprint.IsManaged() {
+		return nil, errUnmanaged
+	}
+
 	log.WithField("compact", compact).Info("Loading test data")
 
-	err := c.flushBucket()
+	// Remove the summary of any previous load up-front, it won't describe the dataset if this load fails
+	_, err := c.controller().ExecuteCommand(value.NewCommand("rm -f %s", loadSummaryPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to remove previous load summary")
+	}
+
+	err = c.flushBucket("default")
 	if err != nil {
-		return errors.Wrap(err, "failed to flush bucket")
+		return nil, errors.Wrap(err, "failed to flush bucket")
 	}
 
 	err = c.modifyEvictionPercentages(0)
 	if err != nil {
-		return errors.Wrap(err, "failed to set eviction percentages to zero")
+		return nil, errors.Wrap(err, "failed to set eviction percentages to zero")
+	}
+
+	stop := heartbeat("Loading test data", c.itemProgress("default", uint64(c.blueprint.Bucket.Data.Items)), formatCount)
+
+	stopWatcher := c.watchInterrupt(ctx)
+
+	start := time.Now()
+
+	err = c.loadData(ctx)
+
+	interrupted := stopWatcher()
+
+	stop()
+
+	if interrupted != nil {
+		// Best effort, we don't want to leave the cluster unable to evict items for subsequent runs
+		if err := c.modifyEvictionPercentages(30); err != nil {
+			log.Warnf("Failed to reset eviction percentages: %s", err)
+		}
+
+		return nil, errors.Wrap(interrupted, "data load interrupted")
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load data")
 	}
 
-	err = c.loadData()
+	items, err := c.verifyItems()
 	if err != nil {
-		return errors.Wrap(err, "failed to load data")
+		return nil, errors.Wrap(err, "failed to verify loaded data")
+	}
+
+	summary := &value.LoadSummary{
+		DataLoader: c.blueprint.Bucket.Data.Loader(),
+		Items:      items,
+		Bytes:      items * uint64(c.blueprint.Bucket.Data.Size),
+		Duration:   time.Since(start),
 	}
 
+	log.WithFields(log.Fields{
+		"items":            summary.Items,
+		"duration":         summary.Duration,
+		"items_per_second": summary.ItemsPerSecond(),
+	}).Info("Loaded test data")
+
 	err = c.modifyEvictionPercentages(30)
 	if err != nil {
-		return errors.Wrap(err, "failed to reset eviction percentages")
+		return nil, errors.Wrap(err, "failed to reset eviction percentages")
 	}
 
-	if !compact {
-		return nil
+	if compact {
+		err = c.compactBucket()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compact bucket")
+		}
 	}
 
-	err = c.compactBucket()
+	err = c.saveLoadSummary(summary)
 	if err != nil {
-		return errors.Wrap(err, "failed to compact bucket")
+		return nil, errors.Wrap(err, "failed to save load summary")
 	}
 
-	return nil
+	return summary, nil
+}
+
+// saveLoadSummary records the given load summary on the first node in the cluster, see 'LoadSummary'.
+func (c *Cluster) saveLoadSummary(summary *value.LoadSummary) error {
+	_, err := c.controller().ExecuteCommand(value.NewCommand("echo '%s %d %d %d' > %s", summary.DataLoader,
+		summary.Items, summary.Bytes, summary.Duration, loadSummaryPath))
+
+	return err
 }
 
-// CollectLogs will collect the logs from the remote cluster then copy the logs into the provided directory.
-func (c *Cluster) CollectLogs(path string) ([]string, error) {
-	log.WithField("path", path).Info("Collecting cluster logs")
+// LoadSummary returns the summary of the last data load recorded on the cluster, returns nil if there isn't one (for
+// example, because the dataset was loaded by an older version of autobench or the cluster is unmanaged).
+func (c *Cluster) LoadSummary() (*value.LoadSummary, error) {
+	if !c.blueprint.IsManaged() {
+		return nil, nil
+	}
 
-	err := c.startCollection()
+	output, err := c.controller().ExecuteCommand(value.NewCommand("cat %s 2>/dev/null || true", loadSummaryPath))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to start collection")
+		return nil, err
 	}
 
-	// We are safe to ignore the error here since 'logCollectionComplete' does not return an error
-	timeout, _ := poll(c.logCollectionComplete, 5*time.Minute)
-	if timeout {
-		return nil, errors.New("timeout whilst waiting for log collection to complete")
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, nil
 	}
 
-	paths, err := c.collectionPaths()
+	var (
+		summary  value.LoadSummary
+		duration int64
+	)
+
+	_, err = fmt.Sscanf(string(output), "%s %d %d %d", &summary.DataLoader, &summary.Items, &summary.Bytes, &duration)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to determine the paths to logs")
+		return nil, errors.Wrap(err, "failed to parse load summary")
 	}
 
-	err = c.downloadLogs(paths, path)
+	summary.Duration = time.Duration(duration)
+
+	return &summary, nil
+}
+
+// verifyItems waits for the item count of the benchmarking bucket to reach the number of items which should have been
+// loaded, catching data loaders which have silently failed to load the entire dataset. Returns the observed item count.
+func (c *Cluster) verifyItems() (uint64, error) {
+	expected := c.blueprint.Bucket.Data.ExpectedItems()
+
+	log.WithField("expected", expected).Info("Verifying bucket item count")
+
+	count, reached, err := c.waitForItems(expected, itemCountTimeout)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to download logs")
+		return 0, err
 	}
 
-	converted := make([]string, 0, len(paths))
-	for _, logPath := range paths {
-		converted = append(converted, filepath.Join(path, filepath.Base(logPath)))
+	if reached {
+		log.WithField("items", count).Info("Verified bucket item count")
+		return count, nil
 	}
 
-	return converted, nil
+	if !c.blueprint.Bucket.Data.AllowShortLoad {
+		return 0, errors.Errorf("bucket contains %d items but %d were expected, the data loader may have failed",
+			count, expected)
+	}
+
+	log.WithFields(log.Fields{"items": count, "expected": expected}).Warn("Bucket contains fewer items than expected")
+
+	return count, nil
 }
 
-// Stats returns the basic stats from the cluster as reported by ns_server.
-func (c *Cluster) Stats() (*value.Stats, error) {
-	log.WithField("host", c.blueprint.Nodes[0].Host).Info("Getting bucket stats")
+// waitForItems waits for the item count of the benchmarking bucket to reach the expected value, returning the last
+// observed count and whether it was reached before the timeout.
+func (c *Cluster) waitForItems(expected uint64, timeout time.Duration) (uint64, bool, error) {
+	var (
+		progress = c.itemProgress("default", expected)
+		count    uint64
+	)
+
+	timedOut, err := poll(func() (bool, error) {
+		var err error
 
-	// This should probably be done with 'cbrest' or by using an actual HTTP client but for now using curl will suffice
-	output, err := exec.Command("curl", "-s", "-u", "Administrator:asdasd",
-		fmt.Sprintf("%s:8091/pools/default/buckets/default", c.blueprint.Nodes[0].Host)).CombinedOutput()
+		count, _, err = progress()
+		if err != nil {
+			return false, err
+		}
+
+		return count >= expected, nil
+	}, timeout)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to execute curl command")
+		return 0, false, errors.Wrap(err, "failed to get item count")
 	}
 
-	type overlay struct {
-		BasicStats *value.Stats `json:"basicStats"`
-	}
+	return count, !timedOut, nil
+}
 
-	var decoded overlay
+// MutateData mutates the given number of items in the benchmarking bucket, writing items of the given size. The same
+// keys are used each time, meaning the first call will create the items and any subsequent calls will update them. The
+// items are distributed between the collections in the same way as the dataset.
+func (c *Cluster) MutateData(items, size int) error {
+	fields := log.Fields{"bucket": "default", "items": items, "size": size}
+	log.WithFields(fields).Info("Mutating data in bucket")
 
-	err = json.Unmarshal(output, &decoded)
+	collections, err := c.collections()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal stats")
+		return errors.Wrap(err, "failed to get collections")
 	}
 
-	return decoded.BasicStats, nil
+	return c.forCollections(collections, items, func(collection *value.Collection, items int) error {
+		return c.mutateCollection(collection, items, size)
+	})
 }
 
-// startCollection uses the CLI to begin a log collection on all the nodes in the cluster.
-func (c *Cluster) startCollection() error {
-	log.Info("Starting log collection")
+// mutateCollection mutates the given number of items in the given collection of the benchmarking bucket, see
+// 'MutateData'.
+func (c *Cluster) mutateCollection(collection *value.Collection, items, size int) error {
+	if c.blueprint.Bucket.Data.Durable() {
+		if !c.blueprint.IsManaged() {
+			return errUnmanaged
+		}
+
+		return c.populateFromNodeUsingPillowfight(c.nodes[0], collection, items, size,
+			c.blueprint.Bucket.Data.Prefix(seedPrefix, len(seedPrefix), pillowfightKeyLength))
+	}
+
+	command := fmt.Sprintf(`cbbackupmgr generate --cluster %s -u %s --password %s \
+		--bucket default --num-documents %d --prefix %s --size %d --threads $(nproc) --no-progress-bar`,
+		c.address(),
+		c.credentials().QuotedUsername(),
+		c.credentials().QuotedPassword(),
+		items,
+		c.blueprint.Bucket.Data.Prefix(seedPrefix, len(seedPrefix), len(strconv.Itoa(items))),
+		size,
+	)
+
+	if !c.blueprint.Bucket.Data.Compressible {
+		command += " --low-compression"
+	}
 
-	_, err := c.nodes[0].client.ExecuteCommand(
-		value.NewCommand(`couchbase-cli collect-logs-start -c %s -u Administrator -p asdasd --all-nodes`,
-			c.nodes[0].blueprint.Host))
+	command += generateCollectionArgs(collection)
+
+	_, err := c.controller().ExecuteCommand(value.NewCommand("%s", command))
 
 	return err
 }
 
-// compactionComplete returns a boolean indicating whether any compaction tasks are still running on the cluster.
-func (c *Cluster) compactionComplete() (bool, error) {
-	log.Info("Checking compaction status")
+// DeleteData deletes the configured percentage of the documents in the benchmarking bucket, leaving behind tombstones.
+// The keys are exported then sorted, so the same documents are deleted given the same dataset.
+//
+// NOTE: Documents are deleted from the default collection, so this isn't supported when the dataset is loaded into
+// collections.
+func (c *Cluster) DeleteData(config *value.DeletionConfig) error {
+	if c.blueprint.Bucket.Data.Collections != nil {
+		return errors.New("deleting data is not supported when the dataset is loaded into collections")
+	}
+
+	if config.PurgeInterval != 0 {
+		log.WithField("purge_interval", config.PurgeInterval).Info("Setting metadata purge interval")
+
+		_, err := c.controller().ExecuteCommand(value.NewCommand(`couchbase-cli bucket-edit -c %s \
+			%s --bucket default --purge-interval %g`, c.address(), c.cliFlags(), config.PurgeInterval))
+		if err != nil {
+			return errors.Wrap(err, "failed to set metadata purge interval")
+		}
+	}
 
-	// This should probably be done with 'cbrest' or by using an actual HTTP client but for now using curl will suffice
-	output, err := exec.Command("curl", "-s", "-u", "Administrator:asdasd",
-		fmt.Sprintf("%s:8091/pools/default/tasks", c.blueprint.Nodes[0].Host)).CombinedOutput()
+	info, err := c.bucketInfo("default")
 	if err != nil {
-		return false, errors.Wrap(err, "")
+		return errors.Wrap(err, "failed to get bucket info")
 	}
 
-	type overlay struct {
-		Type   string `json:"type"`
-		Status string `json:"status"`
+	if info.BasicStats == nil {
+		return errors.New("bucket 'default' does not exist")
 	}
 
-	var decoded []overlay
+	items := uint64(math.Round(float64(info.BasicStats.ItemCount) * config.Percentage / 100))
 
-	err = json.Unmarshal(output, &decoded)
+	log.WithFields(log.Fields{"bucket": "default", "items": items}).Info("Deleting data from bucket")
+
+	err = c.exportDocuments("default", deletionsPath)
 	if err != nil {
-		return false, errors.Wrap(err, "failed to unmarshal response")
+		return errors.Wrap(err, "failed to export documents")
 	}
 
-	for _, task := range decoded {
-		if task.Type == "bucket_compaction" && task.Status == "running" {
-			return false, nil
-		}
-	}
+	defer func() { _ = c.controller().RemoveFile(deletionsPath) }()
 
-	return len(decoded) == 1 && decoded[0].Type == "rebalance", nil
-}
+	stop := heartbeat("Deleting data", c.deletionProgress(info.BasicStats.ItemCount, items), formatCount)
+	defer stop()
 
-// logCollectionComplete returns a boolean indicating whether the current log collection has completed.
-func (c *Cluster) logCollectionComplete() (bool, error) {
-	log.Info("Checking log collection status")
+	output, err := c.controller().ExecuteCommand(value.NewCommand(`grep -o '"%[1]s":"[^"]*"' %[2]s |
+		cut -d '"' -f 4 | head -n %[3]d`, exportKey, deletionsPath, items))
+	if err != nil {
+		return errors.Wrap(err, "failed to list keys")
+	}
 
-	_, err := c.nodes[0].client.ExecuteCommand(value.NewCommand(`couchbase-cli collect-logs-status -c %s \
-		-u Administrator -p asdasd | grep -q '^Status: completed'`, c.nodes[0].blueprint.Host))
+	// This should probably be done using an SDK but for now using the REST API will suffice
+	err = c.deleteDocuments(strings.Fields(string(output)))
+	if err != nil {
+		return errors.Wrap(err, "failed to delete documents")
+	}
 
-	return err == nil, nil
+	return nil
 }
 
-// collectionPaths returns a slice of the paths to the cbcollect archives.
-func (c *Cluster) collectionPaths() ([]string, error) {
-	log.Info("Determining which logs to download from cluster")
-
-	output, err := c.nodes[0].client.ExecuteCommand(value.NewCommand(
-		`couchbase-cli collect-logs-status -c %s -u Administrator -p asdasd | grep 'path :' | \
-			awk '{ print $3 }' | paste -sd ","`, c.nodes[0].blueprint.Host,
-	))
+// deleteDocuments concurrently deletes the documents with the given keys from the benchmarking bucket.
+func (c *Cluster) deleteDocuments(keys []string) error {
+	pool := hofp.NewPool(hofp.Options{Size: system.NumCPU()})
 
-	return strings.Split(strings.TrimSpace(string(output)), ","), err
-}
+	queue := func(key string) error {
+		return pool.Queue(func(_ context.Context) error {
+			_, err := c.rest.Execute(&restRequest{
+				Method:   http.MethodDelete,
+				Endpoint: "/pools/default/buckets/default/docs/" + url.PathEscape(key),
+			})
 
-func (c *Cluster) downloadLogs(logPaths []string, output string) error {
-	log.Info("Downloading cluster logs")
+			return errors.Wrapf(err, "failed to delete document '%s'", key)
+		})
+	}
 
-	for _, source := range logPaths {
-		err := c.forEachNode(func(node *Node) error {
-			if !node.client.FileExists(source) {
-				return nil
-			}
+	for _, key := range keys {
+		if queue(key) != nil {
+			break
+		}
+	}
 
-			sink := filepath.Join(output, filepath.Base(source))
+	return pool.Stop()
+}
 
-			fields := log.Fields{"host": node.blueprint.Host, "source": source, "sink": sink}
-			log.WithFields(fields).Info("Downloading cluster logs from node")
+// deletionProgress returns a progress function which reports the number of documents deleted from the benchmarking
+// bucket, given the number of items it contained beforehand.
+func (c *Cluster) deletionProgress(before, total uint64) progressFunc {
+	progress := c.itemProgress("default", total)
 
-			return node.client.SecureDownload(source, sink)
-		})
+	return func() (uint64, uint64, error) {
+		items, _, err := progress()
 		if err != nil {
-			return errors.Wrapf(err, "failed to download logs at '%s'", source)
+			return 0, 0, err
 		}
-	}
 
-	return nil
+		return before - min(before, items), total, nil
+	}
 }
 
-// provisionNodes provisions and initializes Couchbase Server on all the node in the cluster.
-func (c *Cluster) provisionNodes() error {
-	return c.forEachNode(func(node *Node) error { return c.provisionNode(node) })
-}
+// CollectLogs will collect the logs from the remote cluster then copy the logs into the provided directory, the given
+// config may be used to limit which nodes the logs are collected from.
+func (c *Cluster) CollectLogs(path string, config *value.ClusterLogsConfig) ([]string, error) {
+	if !c.blueprint.IsManaged() {
+		log.Warn("Skipping cluster log collection, the logs can't be downloaded from an unmanaged cluster")
+		return nil, nil
+	}
 
-// provisionNode provision and initialize Couchbase Server on the provided node.
-func (c *Cluster) provisionNode(node *Node) error {
-	log.WithField("host", node.blueprint.Host).Info("Provisioning node")
+	nodes, err := c.logNodes(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to determine which nodes to collect logs from")
+	}
+
+	if len(nodes) == 0 {
+		log.Info("Skipping cluster log collection")
+		return nil, nil
+	}
 
-	err := node.provision(c.blueprint.PackagePath)
+	log.WithFields(log.Fields{"path": path, "nodes": len(nodes)}).Info("Collecting cluster logs")
+
+	err = c.startCollection(nodes)
 	if err != nil {
-		return errors.Wrap(err, "failed to provision node")
+		return nil, errors.Wrap(err, "failed to start collection")
 	}
 
-	err = node.createDataPath()
+	// We are safe to ignore the error here since 'logCollectionComplete' does not return an error
+	timeout, _ := poll(c.logCollectionComplete, 5*time.Minute)
+	if timeout {
+		return nil, errors.New("timeout whilst waiting for log collection to complete")
+	}
+
+	paths, err := c.collectionPaths()
 	if err != nil {
-		return errors.Wrap(err, "failed to create data path")
+		return nil, errors.Wrap(err, "failed to determine the paths to logs")
 	}
 
-	err = node.initializeCB()
+	err = c.downloadLogs(nodes, paths, path)
 	if err != nil {
-		return errors.Wrap(err, "failed to initialize Couchbase Server")
+		return nil, errors.Wrap(err, "failed to download logs")
 	}
 
-	return nil
+	converted := make([]string, 0, len(paths))
+	for _, logPath := range paths {
+		converted = append(converted, filepath.Join(path, filepath.Base(logPath)))
+	}
+
+	return converted, nil
 }
 
-// initializeCB will initialize Couchbase Server
-func (c *Cluster) initializeCB() error {
-	err := c.clusterInit()
-	if err != nil {
-		return errors.Wrap(err, "failed to initialize cluster")
+// bucketInfo is the subset of the information about a bucket returned by ns_server that we use.
+type bucketInfo struct {
+	Name       string       `json:"name"`
+	BasicStats *value.Stats `json:"basicStats"`
+	Quota      struct {
+		RAM uint64 `json:"ram"`
+	} `json:"quota"`
+	VBucketServerMap struct {
+		VBucketMap [][]int `json:"vBucketMap"`
+	} `json:"vBucketServerMap"`
+	Nodes []struct {
+		Hostname         string `json:"hostname"`
+		InterestingStats struct {
+			CurrItems              uint64 `json:"curr_items"`
+			MemUsed                uint64 `json:"mem_used"`
+			DiskUsed               uint64 `json:"couch_docs_actual_disk_size"`
+			VBActiveNumNonResident uint64 `json:"vb_active_num_non_resident"`
+		} `json:"interestingStats"`
+	} `json:"nodes"`
+}
+
+// stats returns the basic stats for the bucket, populated with those which aren't part of the basic stats.
+func (b *bucketInfo) stats() *value.Stats {
+	if b.BasicStats != nil {
+		b.BasicStats.RAMQuota = b.Quota.RAM
+		b.BasicStats.VBuckets = len(b.VBucketServerMap.VBucketMap)
 	}
 
-	err = c.forEachNode(func(node *Node) error { return c.serverAdd(node) })
+	return b.BasicStats
+}
+
+// Stats returns the basic stats from the cluster as reported by ns_server.
+func (c *Cluster) Stats() (*value.Stats, error) {
+	log.WithField("host", c.blueprint.Nodes[0].Host).Info("Getting bucket stats")
+
+	info, err := c.bucketInfo("default")
 	if err != nil {
-		return errors.Wrap(err, "failed to add cluster nodes")
+		return nil, errors.Wrap(err, "failed to get bucket info")
 	}
 
-	err = c.rebalance()
+	return info.stats(), nil
+}
+
+// BucketStats returns the basic stats for every bucket in the cluster as reported by ns_server, along with the
+// breakdown of each bucket's stats for each node.
+func (c *Cluster) BucketStats() (value.ClusterStats, error) {
+	log.WithField("host", c.blueprint.Nodes[0].Host).Info("Getting stats for all buckets")
+
+	output, err := c.rest.Execute(&restRequest{Endpoint: "/pools/default/buckets"})
 	if err != nil {
-		return errors.Wrap(err, "failed to rebalance nodes into cluster")
+		return nil, errors.Wrap(err, "failed to execute request")
 	}
 
-	return nil
-}
+	var decoded []*bucketInfo
+
+	err = json.Unmarshal(output, &decoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal bucket info")
+	}
+
+	stats := make(value.ClusterStats, 0, len(decoded))
+
+	for _, info := range decoded {
+		bucket := &value.BucketStats{Name: info.Name, Stats: info.stats()}
+		if bucket.Stats == nil {
+			bucket.Stats = &value.Stats{}
+		}
+
+		for _, node := range info.Nodes {
+			bucket.Nodes = append(bucket.Nodes, &value.NodeStats{
+				Host: node.Hostname,
+				Stats: &value.Stats{
+					ItemCount:              node.InterestingStats.CurrItems,
+					MemUsed:                node.InterestingStats.MemUsed,
+					DiskUsed:               node.InterestingStats.DiskUsed,
+					VBActiveNumNonResident: node.InterestingStats.VBActiveNumNonResident,
+				},
+			})
+		}
+
+		stats = append(stats, bucket)
+	}
+
+	return stats, nil
+}
+
+// Hardware returns the CPUs, memory and operating system of each node, nodes which can't be described are logged and
+// omitted (or the cluster is unmanaged, in which case nil is returned).
+func (c *Cluster) Hardware() []*value.Hardware {
+	if !c.blueprint.IsManaged() {
+		return nil
+	}
+
+	var (
+		lock     sync.Mutex
+		byHost   = make(map[string]*value.Hardware)
+		hardware = make([]*value.Hardware, 0, len(c.nodes))
+	)
+
+	_ = c.forEachNode(func(node *Node) error {
+		described, err := node.describeHardware()
+		if err != nil {
+			log.WithField("host", node.blueprint.Host).Warnf("Failed to describe hardware: %s", err)
+			return nil
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		byHost[node.blueprint.Host] = described
+
+		return nil
+	})
+
+	for _, node := range c.nodes {
+		if described, ok := byHost[node.blueprint.Host]; ok {
+			hardware = append(hardware, described)
+		}
+	}
+
+	return hardware
+}
+
+// Volumes returns the characteristics of the AWS EBS volumes described by the cluster blueprint for each node, returns
+// nil if no volumes are described (or the cluster is unmanaged). Nodes whose volumes can't be described are logged and
+// omitted, so that one node doesn't prevent the remaining volumes being reported.
+func (c *Cluster) Volumes() ([]*value.Volume, error) {
+	if len(c.blueprint.Volumes) == 0 || !c.blueprint.IsManaged() {
+		return nil, nil
+	}
+
+	var (
+		lock    sync.Mutex
+		byHost  = make(map[string][]*value.Volume)
+		volumes = make([]*value.Volume, 0, len(c.nodes)*len(c.blueprint.Volumes))
+	)
+
+	err := c.forEachNode(func(node *Node) error {
+		described, err := node.describeVolumes(c.blueprint.Volumes)
+		if err != nil {
+			log.WithField("host", node.blueprint.Host).Warnf("Failed to describe volumes: %s", err)
+			return nil
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		byHost[node.blueprint.Host] = described
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range c.nodes {
+		volumes = append(volumes, byHost[node.blueprint.Host]...)
+	}
+
+	return volumes, nil
+}
+
+// KVStats returns a snapshot of some key KV stats for the given bucket aggregated across all the nodes in the cluster.
+func (c *Cluster) KVStats(bucket string) (*value.KVStats, error) {
+	var (
+		stats = &value.KVStats{}
+		lock  sync.Mutex
+	)
+
+	err := c.forNodes(c.dataNodes(), func(node *Node) error {
+		nodeStats, err := c.nodeKVStats(node, bucket)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get KV stats for node '%s'", node.blueprint.Host)
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		stats.Add(nodeStats)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	complete, err := c.compactionComplete()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get compaction status")
+	}
+
+	stats.Compacting = !complete
+
+	return stats, nil
+}
+
+// nodeKVStats uses 'cbstats' to get a snapshot of the KV stats for the given bucket on the given node.
+func (c *Cluster) nodeKVStats(node *Node, bucket string) (*value.KVStats, error) {
+	all, err := c.cbstats(node, bucket, "all")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get engine stats")
+	}
+
+	dcp, err := c.cbstats(node, bucket, "dcpagg")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get DCP stats")
+	}
+
+	stats := &value.KVStats{
+		DiskWriteQueue: all["ep_queue_size"] + all["ep_flusher_todo"],
+		BGFetched:      all["ep_bg_fetched"],
+	}
+
+	// The aggregate stats are grouped by connection type, we don't want to count the total twice
+	for key, remaining := range dcp {
+		if strings.HasSuffix(key, ":items_remaining") && !strings.HasPrefix(key, ":total") {
+			stats.DCPBacklog += remaining
+		}
+	}
+
+	return stats, nil
+}
+
+// sampleDCP periodically samples the DCP stats for the 'cbbackupmgr' connection(s) at the given interval until the
+// returned function is called, which returns a summary of the samples. A zero interval disables sampling, in which case
+// the returned summary will be nil.
+func (c *Cluster) sampleDCP(interval time.Duration) func() *value.DCPSummary {
+	if interval == 0 {
+		return func() *value.DCPSummary { return nil }
+	}
+
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		done        = make(chan struct{})
+		summary     = &value.DCPSummary{}
+	)
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			sample, err := c.dcpSample()
+			if err != nil {
+				log.Warnf("Failed to sample DCP stats: %s", err)
+				continue
+			}
+
+			summary.Add(sample)
+		}
+	}()
+
+	return func() *value.DCPSummary {
+		cancel()
+		<-done
+
+		return summary
+	}
+}
+
+// dcpSample returns a sample of the DCP stats for the 'cbbackupmgr' connection(s), along with the number of memcached
+// connections, aggregated across the cluster.
+func (c *Cluster) dcpSample() (*value.DCPSample, error) {
+	var (
+		sample = &value.DCPSample{}
+		lock   sync.Mutex
+	)
+
+	err := c.forNodes(c.dataNodes(), func(node *Node) error {
+		stats, err := c.cbstats(node, "default", "dcp")
+		if err != nil {
+			return errors.Wrapf(err, "failed to get DCP stats for node '%s'", node.blueprint.Host)
+		}
+
+		all, err := c.cbstats(node, "default", "all")
+		if err != nil {
+			return errors.Wrapf(err, "failed to get stats for node '%s'", node.blueprint.Host)
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		sample.MemcachedConnections += all["curr_connections"]
+
+		for key, stat := range stats {
+			if !strings.Contains(key, "cbbackupmgr") {
+				continue
+			}
+
+			// Each connection reports the items remaining once, whilst each stream reports its opaque
+			switch {
+			case strings.HasSuffix(key, "items_remaining"):
+				sample.ItemsRemaining += stat
+				sample.Connections++
+			case strings.HasSuffix(key, ":backfill_buffer_bytes_read"):
+				// The bytes read into the backfill buffer which haven't been sent yet (see 'DcpProducer::addStats')
+				sample.BackfillBytes += stat
+			case strings.Contains(key, ":stream_") && strings.HasSuffix(key, "_opaque"):
+				sample.Streams++
+			}
+		}
+
+		return nil
+	})
+
+	return sample, err
+}
+
+// cbstats runs 'cbstats' for the given bucket/group on the provided node, returning any numeric stats. For an unmanaged
+// cluster 'cbstats' is run remotely from the controller.
+func (c *Cluster) cbstats(node *Node, bucket, group string) (map[string]uint64, error) {
+	executor, address := node.client, "localhost:11210"
+	if !c.blueprint.IsManaged() {
+		executor, address = c.controller(), net.JoinHostPort(node.blueprint.Host, "11210")
+	}
+
+	output, err := executor.ExecuteCommand(value.NewCommand(
+		`cbstats %s %s -b %s %s -j`, address, c.credentials().Flags(), bucket, group))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run cbstats")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	decoder.UseNumber()
+
+	var decoded map[string]any
+
+	err = decoder.Decode(&decoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal cbstats output")
+	}
+
+	stats := make(map[string]uint64, len(decoded))
+
+	for key, raw := range decoded {
+		// Depending on the version, stats may be output as numbers or strings
+		parsed, err := strconv.ParseUint(strings.TrimSpace(fmt.Sprint(raw)), 10, 64)
+		if err == nil {
+			stats[key] = parsed
+		}
+	}
+
+	return stats, nil
+}
+
+// bucketInfo returns information about the benchmarking bucket as reported by ns_server.
+func (c *Cluster) bucketInfo(name string) (*bucketInfo, error) {
+	output, err := c.rest.Execute(&restRequest{Endpoint: "/pools/default/buckets/" + url.PathEscape(name)})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute request")
+	}
+
+	var decoded bucketInfo
+
+	err = json.Unmarshal(output, &decoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal bucket info")
+	}
+
+	return &decoded, nil
+}
+
+// startCollection uses the CLI to begin a log collection on all the nodes in the cluster.
+func (c *Cluster) startCollection(nodes []*Node) error {
+	log.Info("Starting log collection")
+
+	scope := "--all-nodes"
+	if len(nodes) != len(c.nodes) {
+		hosts := make([]string, 0, len(nodes))
+		// Nodes are identified by their plaintext management address (as listed by ns_server), even when using TLS
+		for _, node := range nodes {
+			hosts = append(hosts, node.blueprint.ManagementAddress())
+		}
+
+		scope = "--nodes " + strings.Join(hosts, ",")
+	}
+
+	_, err := c.controller().ExecuteCommand(
+		value.NewCommand(`couchbase-cli collect-logs-start -c %s %s %s`,
+			c.nodes[0].blueprint.ManagementURL(c.blueprint.TLSEnabled()), c.cliFlags(), scope))
+
+	return err
+}
+
+// logNodes returns the nodes which the cluster logs should be collected from according to the given config.
+func (c *Cluster) logNodes(config *value.ClusterLogsConfig) ([]*Node, error) {
+	if config == nil {
+		return c.nodes, nil
+	}
+
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Nodes) != 0 {
+		nodes := make([]*Node, 0, len(config.Nodes))
+
+		for _, host := range config.Nodes {
+			node := c.node(host)
+			if node == nil {
+				return nil, errors.Errorf("host '%s' is not a node in the cluster", host)
+			}
+
+			nodes = append(nodes, node)
+		}
+
+		return nodes, nil
+	}
+
+	switch config.Scope {
+	case value.ClusterLogsConnected:
+		// 'cbbackupmgr' bootstraps using the first node in the connection string
+		return c.nodes[:1], nil
+	case value.ClusterLogsNone:
+		return nil, nil
+	}
+
+	return c.nodes, nil
+}
+
+// compactionComplete returns a boolean indicating whether any compaction tasks are still running on the cluster.
+func (c *Cluster) compactionComplete() (bool, error) {
+	log.Info("Checking compaction status")
+
+	output, err := c.rest.Execute(&restRequest{Endpoint: "/pools/default/tasks"})
+	if err != nil {
+		return false, errors.Wrap(err, "")
+	}
+
+	type overlay struct {
+		Type   string `json:"type"`
+		Status string `json:"status"`
+	}
+
+	var decoded []overlay
+
+	err = json.Unmarshal(output, &decoded)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to unmarshal response")
+	}
+
+	for _, task := range decoded {
+		if task.Type == "bucket_compaction" && task.Status == "running" {
+			return false, nil
+		}
+	}
+
+	return len(decoded) == 1 && decoded[0].Type == "rebalance", nil
+}
+
+// logCollectionComplete returns a boolean indicating whether the current log collection has completed.
+func (c *Cluster) logCollectionComplete() (bool, error) {
+	log.Info("Checking log collection status")
+
+	_, err := c.controller().ExecuteCommand(value.NewCommand(`couchbase-cli collect-logs-status -c %s \
+		%s | grep -q '^Status: completed'`, c.nodes[0].blueprint.ManagementURL(c.blueprint.TLSEnabled()), c.cliFlags()))
+
+	return err == nil, nil
+}
+
+// collectionPaths returns a slice of the paths to the cbcollect archives.
+func (c *Cluster) collectionPaths() ([]string, error) {
+	log.Info("Determining which logs to download from cluster")
+
+	output, err := c.controller().ExecuteCommand(value.NewCommand(
+		`couchbase-cli collect-logs-status -c %s %s | grep 'path :' | \
+			awk '{ print $3 }' | paste -sd ","`, c.nodes[0].blueprint.ManagementURL(c.blueprint.TLSEnabled()), c.cliFlags(),
+	))
+
+	return strings.Split(strings.TrimSpace(string(output)), ","), err
+}
+
+// downloadLogs downloads the logs at the given paths from whichever of the given nodes they exist on into the provided
+// directory, every path/node pair is downloaded concurrently, limited by the configured concurrency.
+func (c *Cluster) downloadLogs(nodes []*Node, logPaths []string, output string) error {
+	log.Info("Downloading cluster logs")
+
+	pool := hofp.NewPool(hofp.Options{
+		Size: max(1, min(c.concurrency(), len(logPaths)*len(nodes))),
+	})
+
+	download := func(node *Node, source string) error {
+		if !node.client.FileExists(source) {
+			return nil
+		}
+
+		sink := filepath.Join(output, filepath.Base(source))
+
+		fields := log.Fields{"host": node.blueprint.Host, "source": source, "sink": sink}
+		log.WithFields(fields).Info("Downloading cluster logs from node")
+
+		err := node.client.SecureDownload(source, sink)
+		if err != nil {
+			return errors.Wrapf(err, "failed to download logs at '%s'", source)
+		}
+
+		return nil
+	}
+
+	queue := func(node *Node, source string) error {
+		return pool.Queue(func(_ context.Context) error { return download(node, source) })
+	}
+
+	for _, source := range logPaths {
+		for _, node := range nodes {
+			if queue(node, source) != nil {
+				return pool.Stop()
+			}
+		}
+	}
+
+	return pool.Stop()
+}
+
+// provisionNodes provisions and initializes Couchbase Server on all the node in the cluster, when a batch size is
+// configured the nodes will be provisioned in batches, waiting for each batch to complete before starting the next.
+func (c *Cluster) provisionNodes() error {
+	size := c.blueprint.ProvisionBatchSize
+	if size <= 0 {
+		size = len(c.nodes)
+	}
+
+	for start := 0; start < len(c.nodes); start += size {
+		batch := c.nodes[start:min(start+size, len(c.nodes))]
+
+		if size < len(c.nodes) {
+			log.WithFields(log.Fields{"batch": start/size + 1, "nodes": len(batch)}).Info("Provisioning batch of nodes")
+		}
+
+		err := c.forNodes(batch, func(node *Node) error { return c.provisionNode(node) })
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// provisionNode provision and initialize Couchbase Server on the provided node.
+func (c *Cluster) provisionNode(node *Node) error {
+	log.WithField("host", node.blueprint.Host).Info("Provisioning node")
+
+	err := node.provision(c.blueprint.PackagePath, c.blueprint.DependenciesPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to provision node")
+	}
+
+	err = node.createDataPath()
+	if err != nil {
+		return errors.Wrap(err, "failed to create data path")
+	}
+
+	err = node.configureVolumes(c.blueprint.Volumes)
+	if err != nil {
+		return errors.Wrap(err, "failed to configure volumes")
+	}
+
+	err = node.initializeCB(c.address(), c.cliFlags())
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize Couchbase Server")
+	}
+
+	return nil
+}
+
+// initializeCB will initialize Couchbase Server
+func (c *Cluster) initializeCB() error {
+	err := c.clusterInit()
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize cluster")
+	}
+
+	err = c.forEachNode(func(node *Node) error { return c.serverAdd(node) })
+	if err != nil {
+		return errors.Wrap(err, "failed to add cluster nodes")
+	}
+
+	err = c.rebalance()
+	if err != nil {
+		return errors.Wrap(err, "failed to rebalance nodes into cluster")
+	}
+
+	return nil
+}
+
+// limitVBuckets uses /diag/eval to limit the number of vBuckets in the cluster.
+func (c *Cluster) limitVBuckets() error {
+	// We're using a default number of vBuckets don't bother changing anything; note that 1024 is still set explicitly
+	// since it's not the default for every storage backend (e.g. Magma buckets default to 128 vBuckets in 7.6+)
+	if c.blueprint.Bucket.VBuckets == 0 {
+		return nil
+	}
+
+	log.WithField("vbuckets", c.blueprint.Bucket.VBuckets).Info("Limiting number of vBuckets")
+
+	_, err := c.rest.Execute(&restRequest{
+		Method:   http.MethodPost,
+		Endpoint: "/diag/eval",
+		Body:     fmt.Sprintf("ns_config:set(couchbase_num_vbuckets_default, %d).", c.blueprint.Bucket.VBuckets),
+	})
+
+	return err
+}
+
+// validateVBuckets ensures that the benchmarking bucket was created with the number of vBuckets from the blueprint,
+// limiting the number of vBuckets modifies 'ns_config' which may be silently ignored. There's nothing to validate when
+// the default is used, since it depends on the version and storage backend.
+func (c *Cluster) validateVBuckets() error {
+	expected := int(c.blueprint.Bucket.VBuckets)
+	if expected == 0 {
+		return nil
+	}
+
+	info, err := c.bucketInfo("default")
+	if err != nil {
+		return errors.Wrap(err, "failed to get bucket info")
+	}
+
+	actual := len(info.VBucketServerMap.VBucketMap)
+
+	log.WithFields(log.Fields{"expected": expected, "actual": actual}).Info("Validating number of vBuckets")
+
+	if actual != expected {
+		return errors.Errorf("bucket has %d vBuckets, expected %d", actual, expected)
+	}
+
+	return nil
+}
+
+// enableDeveloperPreviewMode enables the developer preview mode for the cluster.
+func (c *Cluster) enableDeveloperPreviewMode() error {
+	if !c.blueprint.DeveloperPreview {
+		return nil
+	}
+
+	log.WithField("hosts", c.hosts()).Info("Enabling developer preview mode")
+
+	// Using POST request instead of the related CLI command since it prompts for user input confirmation
+	_, err := c.rest.Execute(&restRequest{
+		Method:   http.MethodPost,
+		Endpoint: "/settings/developerPreview",
+		Body:     url.Values{"enabled": {"true"}}.Encode(),
+	})
+
+	return err
+}
+
+// setRebalanceMoves sets the number of concurrent vBucket moves per node used when rebalancing the cluster.
+func (c *Cluster) setRebalanceMoves() error {
+	if c.blueprint.RebalanceMovesPerNode == 0 {
+		return nil
+	}
+
+	log.WithField("moves", c.blueprint.RebalanceMovesPerNode).Info("Setting rebalance moves per node")
+
+	_, err := c.rest.Execute(&restRequest{
+		Method:   http.MethodPost,
+		Endpoint: "/settings/rebalance",
+		Body:     url.Values{"rebalanceMovesPerNode": {strconv.Itoa(c.blueprint.RebalanceMovesPerNode)}}.Encode(),
+	})
+
+	return err
+}
+
+// ApplyCompactionConcurrency applies the compaction concurrency to the benchmarking bucket, this is done at the start
+// of each run since it won't have persisted if the bucket has been restarted (e.g. nodes added by a topology change).
+func (c *Cluster) ApplyCompactionConcurrency() error {
+	return c.setCompactionConcurrency("default")
+}
+
+// setCompactionConcurrency sets the maximum ratio of writer threads which may be used to concurrently compact the
+// given buckets on each node.
+//
+// NOTE: This is an ep-engine parameter which isn't exposed via the REST API, so it's set using 'cbepctl'; it doesn't
+// persist if the bucket is recreated/restarted, so must be applied again afterwards.
+func (c *Cluster) setCompactionConcurrency(buckets ...string) error {
+	if c.blueprint.CompactionConcurrentRatio == 0 {
+		return nil
+	}
+
+	fields := log.Fields{"ratio": c.blueprint.CompactionConcurrentRatio, "buckets": buckets}
+	log.WithFields(fields).Info("Setting compaction concurrency")
+
+	return c.forNodes(c.dataNodes(), func(node *Node) error {
+		for _, bucket := range buckets {
+			_, err := node.client.ExecuteCommand(value.NewCommand(`cbepctl localhost:11210 -b %s %s \
+				set flush_param compaction_max_concurrent_ratio %g`,
+				bucket, c.credentials().Flags(), c.blueprint.CompactionConcurrentRatio))
+			if err != nil {
+				return errors.Wrapf(err, "failed to set compaction concurrency for bucket '%s'", bucket)
+			}
+		}
+
+		return nil
+	})
+}
+
+// createBucket creates a bucket with the given name on the remote cluster which by default uses the whole data service
+// quota, unless an explicit quota has been configured (or a target bucket is configured, in which case it's split).
+func (c *Cluster) createBucket(name string) error {
+	fields := log.Fields{
+		"name":                 name,
+		"type":                 c.blueprint.Bucket.Type,
+		"storage_backend":      c.blueprint.Bucket.StorageBackend,
+		"eviction_policy":      c.blueprint.Bucket.EvictionPolicy,
+		"pitr_enabled":         c.blueprint.Bucket.PiTREnabled,
+		"pitr_granularity":     c.blueprint.Bucket.PiTRGranularity,
+		"pitr_max_history_age": c.blueprint.Bucket.PiTRMaxHistoryAge,
+		"ram_quota_mb":         c.blueprint.Bucket.RAMQuotaMB,
+		"ram_quota_percentage": c.blueprint.Bucket.QuotaPercentage(),
+	}
+
+	log.WithFields(fields).Info("Creating bucket")
+
+	command := fmt.Sprintf(
+		`%s couchbase-cli bucket-create --bucket %s --bucket-type %s -c %s \
+			%s --bucket-ramsize $QUOTA --bucket-eviction-policy %s \
+			--bucket-replica 0 --enable-flush 1 --wait`,
+		c.bucketQuota(),
+		name,
+		c.blueprint.Bucket.Type,
+		c.address(),
+		c.cliFlags(),
+		c.blueprint.Bucket.EvictionPolicy,
+	)
+
+	command = c.addPiTRArgs(command)
+
+	if c.blueprint.Bucket.CompressionMode != "" {
+		command += fmt.Sprintf(" --compression-mode %s", c.blueprint.Bucket.CompressionMode)
+	}
+
+	if c.blueprint.Bucket.StorageBackend != "" {
+		command += fmt.Sprintf(" --storage-backend %s", c.blueprint.Bucket.StorageBackend)
+	}
+
+	_, err := c.controller().ExecuteCommand(value.NewCommand("%s", command))
+
+	return err
+}
+
+// SetCompressionMode changes the compression mode of the benchmarking bucket on the remote cluster.
+//
+// NOTE: Existing documents are only (de)compressed as they're accessed or by the item pager in 'active' mode, meaning
+// the change may not be fully reflected in the stored data immediately.
+func (c *Cluster) SetCompressionMode(mode string) error {
+	log.WithFields(log.Fields{"name": "default", "compression_mode": mode}).Info("Setting bucket compression mode")
+
+	_, err := c.controller().ExecuteCommand(value.NewCommand(`couchbase-cli bucket-edit -c %s \
+		%s --bucket default --compression-mode %s`, c.address(), c.cliFlags(), mode))
+
+	return err
+}
+
+// ResetCompressionMode sets the compression mode of the benchmarking bucket back to the one described by the bucket
+// blueprint, used once the compression modes have been compared.
+func (c *Cluster) ResetCompressionMode() error {
+	mode := c.blueprint.Bucket.CompressionMode
+	if mode == "" {
+		mode = defaultCompressionMode
+	}
+
+	return c.SetCompressionMode(mode)
+}
+
+// flushBucket flushes the bucket with the given name on the remote cluster.
+//
+// TODO (jamesl33) This looks to be a synchronous operation so for large buckets this operation may timeout and fail.
+func (c *Cluster) flushBucket(name string) error {
+	log.WithField("name", name).Info("Flushing bucket")
+
+	_, err := c.controller().ExecuteCommand(value.NewCommand(`couchbase-cli bucket-flush -c %s \
+		%s --bucket %s --force`, c.address(), c.cliFlags(), name))
+	if err != nil {
+		return err
+	}
+
+	// We've got to wait for things to complete, this isn't ideal but will have to do for now
+	time.Sleep(30 * time.Second)
+
+	return nil
+}
+
+// deleteBucket deletes the bucket with the given name from the remote cluster.
+func (c *Cluster) deleteBucket(name string) error {
+	log.WithField("name", name).Info("Deleting bucket")
+
+	_, err := c.controller().ExecuteCommand(value.NewCommand(`couchbase-cli bucket-delete -c %s \
+		%s --bucket %s`, c.address(), c.cliFlags(), name))
+
+	return err
+}
+
+// itemProgress returns a progress function which reports the number of items in the given bucket, relative to the
+// given total.
+func (c *Cluster) itemProgress(bucket string, total uint64) progressFunc {
+	return func() (uint64, uint64, error) {
+		info, err := c.bucketInfo(bucket)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if info.BasicStats == nil {
+			return 0, 0, errors.Errorf("bucket '%s' does not exist", bucket)
+		}
+
+		return info.BasicStats.ItemCount, total, nil
+	}
+}
+
+// restoreBucket returns the name of the bucket which restore benchmarks will restore into, this is the target bucket
+// when one is configured, otherwise the benchmarking bucket.
+func (c *Cluster) restoreBucket() string {
+	if c.blueprint.Bucket.TargetBucket != "" {
+		return c.blueprint.Bucket.TargetBucket
+	}
+
+	return "default"
+}
+
+// compactBucket compacts the benchmarking bucket on the remote cluster.
+func (c *Cluster) compactBucket() error {
+	log.WithField("name", "default").Info("Compacting bucket")
+
+	_, err := c.controller().ExecuteCommand(value.NewCommand(`couchbase-cli bucket-compact -c %s \
+		%s --bucket default`, c.address(), c.cliFlags()))
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	// We've got to wait for things to start, for example we need to wait for the compaction entry to be added to the
+	// running tasks.
+	time.Sleep(30 * time.Second)
+
+	timeout, err := poll(c.compactionComplete, 24*time.Hour)
+	if err != nil {
+		return errors.Wrap(err, "failed to poll until compaction completed")
+	}
+
+	if timeout {
+		return errors.New("timeout whilst waiting for bucket compaction to complete")
+	}
+
+	return nil
+}
+
+// runPreBenchmarkTasks performs any tasks which should be completed prior to running any benchmarks. For example, we
+// should flush the caches to avoid skewed results.
+func (c *Cluster) runPreBenchmarkTasks() error {
+	log.Info("Running cluster pre-benchmark tasks")
+
+	err := c.flushCaches()
+	if err != nil {
+		return errors.Wrap(err, "failed to flush caches")
+	}
+
+	return nil
+}
+
+// flushCaches flushes the caches on all the nodes in the cluster.
+func (c *Cluster) flushCaches() error {
+	if !c.blueprint.IsManaged() {
+		log.Warn("Skipping flushing caches, the cluster is unmanaged")
+		return nil
+	}
+
+	log.WithField("hosts", c.hosts()).Info("Flushing caches")
+
+	return c.forEachNode(func(node *Node) error { return node.client.FlushCaches() })
+}
+
+// forEachNode is a utility function which concurrently runs the provided function on each node in the cluster.
+func (c *Cluster) forEachNode(fn func(node *Node) error) error {
+	return c.forNodes(c.nodes, fn)
+}
+
+// dataNodes returns the nodes in the cluster which are running the data service, used for operations which interact
+// with the buckets directly on each node (e.g. 'cbstats').
+func (c *Cluster) dataNodes() []*Node {
+	nodes := make([]*Node, 0, len(c.nodes))
+
+	for _, node := range c.nodes {
+		if node.blueprint.HasService(value.ServiceData) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
+
+// forNodes concurrently runs the provided function on each of the given nodes, limited by the configured concurrency.
+func (c *Cluster) forNodes(nodes []*Node, fn func(node *Node) error) error {
+	pool := hofp.NewPool(hofp.Options{
+		Size: max(1, min(c.concurrency(), len(nodes))),
+	})
+
+	queue := func(node *Node) error { return pool.Queue(func(_ context.Context) error { return fn(node) }) }
+
+	for _, node := range nodes {
+		if queue(node) != nil {
+			break
+		}
+	}
+
+	return pool.Stop()
+}
+
+// concurrency returns the maximum number of nodes which should be operated on concurrently, defaulting to the number
+// of CPUs when not configured.
+func (c *Cluster) concurrency() int {
+	if c.blueprint.Concurrency <= 0 {
+		return system.NumCPU()
+	}
+
+	return c.blueprint.Concurrency
+}
+
+// modifyEvictionPercentages updates the eviction percentages on each data node in the cluster to the given value.
+func (c *Cluster) modifyEvictionPercentages(percentage int) error {
+	log.WithField("hosts", c.hosts()).Info("Modifying eviction percentages")
+
+	return c.forNodes(c.dataNodes(), func(node *Node) error { return c.modifyEvictionPercentage(node, percentage) })
+}
+
+// modifyEvictionPercentage updates the eviction percentage on the given node to the given value. For an unmanaged
+// cluster 'cbepctl' is run remotely from the controller.
+func (c *Cluster) modifyEvictionPercentage(node *Node, percentage int) error {
+	fields := log.Fields{"node": node.blueprint.Host, "percentage": percentage}
+	log.WithFields(fields).Info("Modifying eviction percentage on node")
+
+	executor, address := node.client, "localhost:11210"
+	if !c.blueprint.IsManaged() {
+		executor, address = c.controller(), net.JoinHostPort(node.blueprint.Host, "11210")
+	}
+
+	_, err := executor.ExecuteCommand(
+		value.NewCommand(`cbepctl %s -b default %s \
+			set flush_param item_eviction_age_percentage %d`, address, c.credentials().Flags(), percentage))
+
+	return err
+}
+
+// loadData runs the data loader specified in the config on each node in the cluster to generate the benchmarking
+// dataset; failed shards won't be retried once the given context is cancelled.
+func (c *Cluster) loadData(ctx context.Context) error {
+	data := c.blueprint.Bucket.Data
+
+	if data.KeySize > value.MaxKeySize {
+		return errors.Errorf("key size %d exceeds the maximum key size of %d", data.KeySize, value.MaxKeySize)
+	}
+
+	if data.UUIDKeys() && (data.DataLoader == value.Pillowfight || data.Durable()) {
+		return errors.New("uuid keys are only supported by the 'cbbackupmgr' data loader without durability")
+	}
+
+	if data.Collections != nil && data.DataLoader == value.Pillowfight {
+		return errors.New("collections aren't supported by the 'pillowfight' data loader")
+	}
+
+	if data.Collections != nil {
+		err := data.Collections.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid collections")
+		}
+	}
+
+	err := data.Workload.Validate()
+	if err != nil {
+		return errors.Wrap(err, "invalid pillowfight workload")
+	}
+
+	collections, err := c.collections()
+	if err != nil {
+		return errors.Wrap(err, "failed to get collections")
+	}
+
+	loader, idempotent, err := c.shardLoader()
+	if err != nil {
+		return err
+	}
+
+	shards := c.shards(collections)
+
+	err = c.loadShards(ctx, shards, loader, idempotent)
+	if err != nil {
+		return err
+	}
+
+	return c.reconcileShards(ctx, shards, loader, idempotent)
+}
+
+// shards splits the dataset between the nodes in the cluster (the remainder is loaded by the last node), then splits
+// the items for each node between the given collections. Shards are ordered by collection, so that concurrently loaded
+// shards are spread across the nodes.
+func (c *Cluster) shards(collections []*value.Collection) []*loadShard {
+	var (
+		items  = c.blueprint.Bucket.Data.Items
+		shares = make([][]int, len(c.nodes))
+		shards = make([]*loadShard, 0, len(c.nodes)*max(1, len(collections)))
+	)
+
+	for idx := range c.nodes {
+		share := items / len(c.nodes)
+
+		if idx == len(c.nodes)-1 {
+			share += items % len(c.nodes)
+		}
+
+		shares[idx] = []int{share}
 
-// limitVBuckets uses /diag/eval to limit the number of vBuckets in the cluster.
-func (c *Cluster) limitVBuckets() error {
-	// We're using a default number of vBuckets don't bother changing anything
-	if c.blueprint.Bucket.VBuckets == 0 || c.blueprint.Bucket.VBuckets == 1024 {
-		return nil
+		if len(collections) != 0 {
+			shares[idx] = c.blueprint.Bucket.Data.Collections.Distribute(share)
+		}
 	}
 
-	log.WithField("vbuckets", c.blueprint.Bucket.VBuckets).Info("Limiting number of vBuckets")
+	for collection := range max(1, len(collections)) {
+		for idx, node := range c.nodes {
+			shard := &loadShard{node: node, index: len(shards), items: shares[idx][collection], prefix: randomPrefix()}
 
-	_, err := c.nodes[0].client.ExecuteCommand(value.NewCommand(
-		`curl -X POST -u Administrator:asdasd localhost:8091/diag/eval -d \
-			"ns_config:set(couchbase_num_vbuckets_default, %d)."`, c.blueprint.Bucket.VBuckets))
+			if len(collections) != 0 {
+				shard.collection = collections[collection]
+			}
 
-	return err
-}
+			// Collections which don't receive any items needn't be loaded
+			if shard.collection != nil && shard.items == 0 {
+				continue
+			}
 
-// enableDeveloperPreviewMode enables the developer preview mode for the cluster.
-func (c *Cluster) enableDeveloperPreviewMode() error {
-	if !c.blueprint.DeveloperPreview {
-		return nil
+			shards = append(shards, shard)
+		}
 	}
 
-	log.WithField("hosts", c.hosts()).Info("Enabling developer preview mode")
+	return shards
+}
 
-	// Using POST request instead of the related CLI command since it prompts for user input confirmation
-	_, err := c.nodes[0].client.ExecuteCommand(value.NewCommand(`curl -X POST -u Administrator:asdasd \
-		localhost:8091/settings/developerPreview -d "enabled=true"`))
+// shardLoader returns the function used to load a shard using the configured data loader, along with whether it's
+// idempotent i.e. loading a shard again overwrites the same keys, rather than creating additional items.
+func (c *Cluster) shardLoader() (func(shard *loadShard) error, bool, error) {
+	data := c.blueprint.Bucket.Data
 
-	return err
-}
+	switch data.DataLoader {
+	case "", value.CBM:
+		// Neither 'cbbackupmgr' or 'cbc-pillowfight' support generating random keys, fallback to 'cbimport'
+		if data.UUIDKeys() {
+			return func(shard *loadShard) error {
+				return c.loadDataFromNodeUsingImport(shard.node, shard.collection, shard.items, shard.prefix)
+			}, false, nil
+		}
 
-// createBucket creates the benchmarking on the remote cluster which by default uses a quota of 80% of the total memory
-// on the cluster nodes.
-func (c *Cluster) createBucket() error {
-	fields := log.Fields{
-		"name":                 "default",
-		"type":                 c.blueprint.Bucket.Type,
-		"eviction_policy":      c.blueprint.Bucket.EvictionPolicy,
-		"pitr_enabled":         c.blueprint.Bucket.PiTREnabled,
-		"pitr_granularity":     c.blueprint.Bucket.PiTRGranularity,
-		"pitr_max_history_age": c.blueprint.Bucket.PiTRMaxHistoryAge,
+		// 'cbbackupmgr' doesn't support durable writes, fallback to populating the bucket using 'cbc-pillowfight'
+		if data.Durable() {
+			return func(shard *loadShard) error {
+				return c.populateFromNodeUsingPillowfight(shard.node, shard.collection, shard.items, data.Size,
+					data.Prefix(shard.prefix, randomPrefixLength, pillowfightKeyLength))
+			}, true, nil
+		}
+
+		return func(shard *loadShard) error {
+			return c.loadDataFromNodeUsingBackupMgr(shard.node, shard.collection, shard.items, shard.prefix)
+		}, true, nil
+	case value.Pillowfight:
+		// Mutations are made over time for each granularity period, rerunning a shard would extend the history
+		return func(shard *loadShard) error {
+			return c.loadDataFromNodeUsingPillowfight(shard.node, shard.items)
+		}, false, nil
 	}
 
-	log.WithFields(fields).Info("Creating bucket")
+	return nil, false, fmt.Errorf("unknown/unsupported data loader '%s'", data.DataLoader)
+}
 
-	command := fmt.Sprintf(
-		`%s couchbase-cli bucket-create --bucket default --bucket-type %s -c localhost:8091 \
-			-u Administrator -p asdasd --bucket-ramsize $QUOTA --bucket-eviction-policy %s \
-			--bucket-replica 0 --enable-flush 1 --wait`,
-		memInfo,
-		c.blueprint.Bucket.Type,
-		c.blueprint.Bucket.EvictionPolicy,
+// loadShards concurrently loads each of the given shards (limited by the configured concurrency), retrying failed
+// shards when the loader is idempotent. Failing to load a shard doesn't stop the remaining shards from being loaded,
+// the failed shards are reported once complete.
+func (c *Cluster) loadShards(ctx context.Context, shards []*loadShard, loader func(shard *loadShard) error,
+	idempotent bool,
+) error {
+	var (
+		lock     sync.Mutex
+		loaded   int
+		failed   []string
+		attempts = 1
 	)
 
-	command = c.addPiTRArgs(command)
+	if idempotent {
+		attempts = loadAttempts
+	}
 
-	_, err := c.nodes[0].client.ExecuteCommand(value.NewCommand(command))
+	load := func(shard *loadShard) error {
+		var (
+			node   = shard.node
+			fields = log.Fields{
+				"shard":      shard.index + 1,
+				"host":       node.blueprint.Host,
+				"collection": collectionName(shard.collection),
+				"items":      shard.items,
+			}
+			err error
+		)
 
-	return err
-}
+		for attempt := 1; attempt <= attempts && ctx.Err() == nil; attempt++ {
+			err = loader(shard)
+			if err == nil {
+				break
+			}
 
-// flushBucket flushes the benchmarking bucket on the remote cluster.
-//
-// TODO (jamesl33) This looks to be a synchronous operation so for large buckets this operation may timeout and fail.
-func (c *Cluster) flushBucket() error {
-	log.WithField("name", "default").Info("Flushing bucket")
+			log.WithFields(fields).Warnf("Failed to load shard (attempt %d/%d): %s", attempt, attempts, err)
+		}
 
-	_, err := c.nodes[0].client.ExecuteCommand(value.NewCommand(`couchbase-cli bucket-flush -c localhost:8091 \
-		-u Administrator -p asdasd --bucket default --force`))
-	if err != nil {
-		return err
-	}
+		lock.Lock()
+		defer lock.Unlock()
 
-	// We've got to wait for things to complete, this isn't ideal but will have to do for now
-	time.Sleep(30 * time.Second)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%d (%s)", shard.index+1, node.blueprint.Host))
+			return nil
+		}
 
-	return nil
-}
+		loaded++
 
-// compactBucket compacts the benchmarking bucket on the remote cluster.
-func (c *Cluster) compactBucket() error {
-	log.WithField("name", "default").Info("Compacting bucket")
+		log.WithFields(fields).Infof("Loaded shard, %d/%d complete", loaded, len(shards))
 
-	_, err := c.nodes[0].client.ExecuteCommand(value.NewCommand(`couchbase-cli bucket-compact -c localhost:8091 \
-		-u Administrator -p asdasd --bucket default`))
-	if err != nil {
-		return errors.Wrap(err, "")
+		return nil
 	}
 
-	// We've got to wait for things to start, for example we need to wait for the compaction entry to be added to the
-	// running tasks.
-	time.Sleep(30 * time.Second)
+	pool := hofp.NewPool(hofp.Options{
+		Size: max(1, min(c.concurrency(), len(shards))),
+	})
 
-	timeout, err := poll(c.compactionComplete, 24*time.Hour)
-	if err != nil {
-		return errors.Wrap(err, "failed to poll until compaction completed")
-	}
+	queue := func(shard *loadShard) error { return pool.Queue(func(_ context.Context) error { return load(shard) }) }
 
-	if timeout {
-		return errors.New("timeout whilst waiting for bucket compaction to complete")
+	for _, shard := range shards {
+		if queue(shard) != nil {
+			break
+		}
 	}
 
-	return nil
-}
-
-// runPreBenchmarkTasks performs any tasks which should be completed prior to running any benchmarks. For example, we
-// should flush the caches to avoid skewed results.
-func (c *Cluster) runPreBenchmarkTasks() error {
-	log.Info("Running cluster pre-benchmark tasks")
-
-	err := c.flushCaches()
+	err := pool.Stop()
 	if err != nil {
-		return errors.Wrap(err, "failed to flush caches")
+		return err
 	}
 
-	return nil
-}
+	if len(failed) == 0 {
+		return nil
+	}
 
-// flushCaches flushes the caches on all the nodes in the cluster.
-func (c *Cluster) flushCaches() error {
-	log.WithField("hosts", c.hosts()).Info("Flushing caches")
+	sort.Strings(failed)
 
-	return c.forEachNode(func(node *Node) error { return node.client.FlushCaches() })
+	return errors.Errorf("failed to load %d/%d shard(s): %s", len(failed), len(shards), strings.Join(failed, ", "))
 }
 
-// forEachNode is a utility function which concurrently runs the provided function on each node in the cluster.
-func (c *Cluster) forEachNode(fn func(node *Node) error) error {
-	pool := hofp.NewPool(hofp.Options{
-		Size: min(system.NumCPU(), len(c.nodes)),
-	})
+// reconcileShards loads every shard again when the bucket contains fewer items than expected once they've been loaded,
+// filling any gaps left by loaders which exited successfully without loading their entire shard. Shards can only be
+// reconciled when the loader is idempotent.
+func (c *Cluster) reconcileShards(ctx context.Context, shards []*loadShard, loader func(shard *loadShard) error,
+	idempotent bool,
+) error {
+	if !idempotent || ctx.Err() != nil {
+		return nil
+	}
 
-	queue := func(node *Node) error { return pool.Queue(func(_ context.Context) error { return fn(node) }) }
+	expected := c.blueprint.Bucket.Data.ExpectedItems()
 
-	for _, node := range c.nodes {
-		if queue(node) != nil {
-			break
-		}
+	count, reached, err := c.waitForItems(expected, reconcileTimeout)
+	if err != nil || reached {
+		return err
 	}
 
-	return pool.Stop()
+	log.WithFields(log.Fields{"items": count, "expected": expected}).
+		Warn("Bucket contains fewer items than expected, reloading shards")
+
+	return c.loadShards(ctx, shards, loader, idempotent)
 }
 
-// modifyEvictionPercentages updates the eviction percentages on each node in the cluster to the given value.
-func (c *Cluster) modifyEvictionPercentages(percentage int) error {
-	log.WithField("hosts", c.hosts()).Info("Modifying eviction percentages")
+// watchInterrupt starts a background watcher which terminates the data loaders running on each node, and removes any
+// temporary files they've created, once the given context is cancelled; the returned function stops the watcher,
+// returning the reason for aborting.
+func (c *Cluster) watchInterrupt(ctx context.Context) func() error {
+	var (
+		stop        = make(chan struct{})
+		done        = make(chan struct{})
+		interrupted error
+	)
 
-	return c.forEachNode(func(node *Node) error { return c.modifyEvictionPercentage(node, percentage) })
-}
+	go func() {
+		defer close(done)
 
-// modifyEvictionPercentage updates the eviction percentage on the given node to the given value.
-func (c *Cluster) modifyEvictionPercentage(node *Node, percentage int) error {
-	fields := log.Fields{"node": node.blueprint.Host, "percentage": percentage}
-	log.WithFields(fields).Info("Modifying eviction percentage on node")
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+		}
 
-	_, err := c.nodes[0].client.ExecuteCommand(
-		value.NewCommand(`cbepctl localhost:11210 -b default -u Administrator -p asdasd \
-			set flush_param item_eviction_age_percentage %d`, percentage))
+		interrupted = ctx.Err()
 
-	return err
-}
+		log.Warn("Terminating data loaders")
 
-// loadData runs the data loader specified in the config on each node in the cluster to generate the benchmarking
-// dataset.
-func (c *Cluster) loadData() error {
-	items := make(chan int, len(c.nodes))
+		err := c.forEachNode(func(node *Node) error {
+			// The bracketed patterns stop 'pkill' matching (and killing) the shell running this command
+			_, err := node.client.ExecuteCommand(value.NewCommand(
+				`pkill -f '[c]bbackupmgr generate|[c]bc-pillowfight|[c]bimport json'; rm -f %s-*.json; true`, importPath))
 
-	for i := 0; i < len(c.nodes)-1; i++ {
-		items <- c.blueprint.Bucket.Data.Items / len(c.nodes)
-	}
+			return errors.Wrapf(err, "failed to terminate data loaders on '%s'", node.blueprint.Host)
+		})
+		if err != nil {
+			log.Warnf("Failed to terminate data loaders: %s", err)
+		}
+	}()
 
-	items <- (c.blueprint.Bucket.Data.Items / len(c.nodes)) + (c.blueprint.Bucket.Data.Items % len(c.nodes))
+	return func() error {
+		close(stop)
+		<-done
 
-	var nodeDataLoadingFunc func(node *Node) error
+		return interrupted
+	}
+}
 
-	switch c.blueprint.Bucket.Data.DataLoader {
-	case "", value.CBM:
-		nodeDataLoadingFunc = func(node *Node) error { return c.loadDataFromNodeUsingBackupMgr(node, <-items) }
-	case value.Pillowfight:
-		nodeDataLoadingFunc = func(node *Node) error { return c.loadDataFromNodeUsingPillowfight(node, <-items) }
-	default:
-		return fmt.Errorf("unknown/unsupported data loader '%s'", c.blueprint.Bucket.Data.DataLoader)
+// randomPrefix returns a random key prefix, used when loading data from multiple nodes concurrently to avoid the
+// generated keys colliding.
+func randomPrefix() string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+	prefix := make([]byte, randomPrefixLength-2)
+	for idx := range prefix {
+		prefix[idx] = alphabet[rand.IntN(len(alphabet))]
 	}
 
-	return c.forEachNode(nodeDataLoadingFunc)
+	return string(prefix) + "::"
 }
 
 // loadDataFromNodeUsingBackupMgr runs 'cbbackupmgr' on the provided node to load the given number of items into the
-// benchmarking bucket.
-func (c *Cluster) loadDataFromNodeUsingBackupMgr(node *Node, items int) error {
+// given collection of the benchmarking bucket (nil being the default collection), using the given random key prefix.
+func (c *Cluster) loadDataFromNodeUsingBackupMgr(node *Node, collection *value.Collection, items int,
+	prefix string,
+) error {
 	fields := log.Fields{
-		"host":    node.blueprint.Host,
-		"bucket":  "default",
-		"items":   items,
-		"size":    c.blueprint.Bucket.Data.Size,
-		"threads": c.blueprint.Bucket.Data.LoadThreads,
+		"host":       node.blueprint.Host,
+		"bucket":     "default",
+		"collection": collectionName(collection),
+		"items":      items,
+		"size":       c.blueprint.Bucket.Data.Size,
+		"threads":    c.blueprint.Bucket.Data.LoadThreads,
 	}
 
 	log.WithFields(fields).Info("Running 'cbbackupmgr' to load data into bucket")
 
-	command := fmt.Sprintf(`cbbackupmgr generate --cluster localhost:8091 -u Administrator --password asdasd \
-		--bucket default --num-documents %d --prefix $(cat /dev/urandom | tr -dc 'a-z0-9' | fold -w 5 | head -n 1):: \
-		--size %d --no-progress-bar`,
+	command := fmt.Sprintf(`cbbackupmgr generate --cluster %s -u %s --password %s%s \
+		--bucket default --num-documents %d --prefix %s --size %d --no-progress-bar`,
+		c.address(),
+		c.credentials().QuotedUsername(),
+		c.credentials().QuotedPassword(),
+		c.noSSLVerifyFlag(),
 		items,
+		c.blueprint.Bucket.Data.Prefix(prefix, randomPrefixLength, len(strconv.Itoa(items))),
 		c.blueprint.Bucket.Data.Size,
 	)
 
@@ -567,7 +1931,9 @@ func (c *Cluster) loadDataFromNodeUsingBackupMgr(node *Node, items int) error {
 		command += " --low-compression"
 	}
 
-	_, err := node.client.ExecuteCommand(value.NewCommand(command))
+	command += generateCollectionArgs(collection)
+
+	_, err := node.client.ExecuteCommand(value.NewCommand("%s", command))
 
 	return err
 }
@@ -589,65 +1955,222 @@ func (c *Cluster) loadDataFromNodeUsingPillowfight(node *Node, items int) error
 	// Potential improvement/workaround is discussed in MB-51242.
 	cyclesNum := granularityPeriodsNum * int(c.blueprint.Bucket.PiTRGranularity)
 
+	var (
+		workload = c.blueprint.Bucket.Data.Workload
+		active   = c.blueprint.Bucket.Data.ActiveItems
+		expiring = workload.Expiring(active)
+	)
+
 	fields := log.Fields{
 		"host":         node.blueprint.Host,
 		"bucket":       "default",
 		"items":        items,
-		"active_items": c.blueprint.Bucket.Data.ActiveItems,
+		"active_items": active,
+		"expiring":     expiring,
 		"cycles":       cyclesNum,
 		"size":         c.blueprint.Bucket.Data.Size,
 		"threads":      c.blueprint.Bucket.Data.LoadThreads,
+		"sets":         workload.Sets(),
+		"random":       workload.RandomAccess,
 	}
 
 	log.WithFields(fields).Info("Running 'pillowfight' to load data into bucket")
 
-	command := fmt.Sprintf(`cbc-pillowfight -U localhost -u Administrator -P asdasd -B %d -I %d --num-cycles %d \
-		--rate-limit %d -m %d -M %d -r 100 -R --sequential`,
-		c.blueprint.Bucket.Data.ActiveItems,
-		c.blueprint.Bucket.Data.ActiveItems,
-		cyclesNum,
-		c.blueprint.Bucket.Data.ActiveItems,
-		c.blueprint.Bucket.Data.Size,
-		c.blueprint.Bucket.Data.Size,
+	// 'cbc-pillowfight' applies the same expiry to every item, so the expiring items are mutated by a second instance
+	// which starts at the first key not mutated by the first instance.
+	command := c.pillowfightCommand(active-expiring, 0, cyclesNum, 0)
+
+	switch {
+	case expiring == active:
+		command = c.pillowfightCommand(active, 0, cyclesNum, workload.Expiry)
+	case expiring != 0:
+		command = fmt.Sprintf(`%s & PID=$!; %s; STATUS=$?; wait $PID && exit $STATUS`,
+			command, c.pillowfightCommand(expiring, active-expiring, cyclesNum, workload.Expiry))
+	}
+
+	_, err := node.client.ExecuteCommand(value.NewCommand("%s", command))
+
+	return err
+}
+
+// pillowfightCommand returns a 'cbc-pillowfight' command which performs the configured workload against the given
+// number of items (starting at the given key) for the given number of cycles, writing items with the given expiry.
+func (c *Cluster) pillowfightCommand(items, start, cycles, expiry int) string {
+	data := c.blueprint.Bucket.Data
+
+	command := fmt.Sprintf(`cbc-pillowfight -U localhost -u %s -P %s -B %d -I %d --num-cycles %d \
+		--rate-limit %d -m %d -M %d -r %d -R`,
+		c.credentials().QuotedUsername(),
+		c.credentials().QuotedPassword(),
+		items,
+		items,
+		cycles,
+		items,
+		data.Size,
+		data.Size,
+		data.Workload.Sets(),
+	)
+
+	if !data.Workload.RandomAccess {
+		command += " --sequential"
+	}
+
+	if start != 0 {
+		command += fmt.Sprintf(" --start-at %d", start)
+	}
+
+	if expiry != 0 {
+		command += fmt.Sprintf(" --expiry %d", expiry)
+	}
+
+	if data.LoadThreads != 0 {
+		command += fmt.Sprintf(" --num-threads %d", data.LoadThreads)
+	}
+
+	if !data.Compressible {
+		command += " --compress"
+	}
+
+	if data.Durable() {
+		command += fmt.Sprintf(" --durability %s", data.Durability)
+	}
+
+	if prefix := data.Prefix("", 0, pillowfightKeyLength); prefix != "" {
+		command += fmt.Sprintf(" --key-prefix %s", prefix)
+	}
+
+	return command
+}
+
+// loadDataFromNodeUsingImport generates the given number of documents on the provided node then imports them into the
+// given collection of the benchmarking bucket using 'cbimport' (with keys using the given random prefix), which is used
+// when keys should be random UUIDs.
+func (c *Cluster) loadDataFromNodeUsingImport(node *Node, collection *value.Collection, items int,
+	prefix string,
+) error {
+	fields := log.Fields{
+		"host":       node.blueprint.Host,
+		"bucket":     "default",
+		"collection": collectionName(collection),
+		"items":      items,
+		"size":       c.blueprint.Bucket.Data.Size,
+		"threads":    c.blueprint.Bucket.Data.LoadThreads,
+	}
+
+	log.WithFields(fields).Info("Running 'cbimport' to load data into bucket")
+
+	// Account for the '{"body":""}' which wraps each value
+	size := max(1, c.blueprint.Bucket.Data.Size-11)
+
+	body := fmt.Sprintf(`base64 -w 0 /dev/urandom | fold -w %d | head -n %d`, size, items)
+	if c.blueprint.Bucket.Data.Compressible {
+		body = fmt.Sprintf(`yes $(head -c %d /dev/zero | tr '\0' 'a') | head -n %d`, size, items)
+	}
+
+	threads := "$(nproc)"
+	if c.blueprint.Bucket.Data.LoadThreads != 0 {
+		threads = strconv.Itoa(c.blueprint.Bucket.Data.LoadThreads)
+	}
+
+	command := fmt.Sprintf(`%[1]s | sed 's/.*/{"body":"&"}/' > %[2]s && cbimport json -c %[7]s \
+		%[5]s -b default -d file://%[2]s -f lines -g "%[3]s#UUID#" -t %[4]s%[6]s;
+		STATUS=$?; rm -f %[2]s; exit $STATUS`,
+		body,
+		importPath+"-"+strings.TrimSuffix(prefix, "::")+".json",
+		c.blueprint.Bucket.Data.Prefix(prefix, randomPrefixLength, uuidLength),
+		threads,
+		c.cliFlags(),
+		collectionExpArgs(collection, "--scope-collection-exp"),
+		c.address(),
+	)
+
+	_, err := node.client.ExecuteCommand(value.NewCommand("%s", command))
+
+	return err
+}
+
+// populateFromNodeUsingPillowfight runs 'cbc-pillowfight' in populate only mode on the given node to write the given
+// number of items of the given size into the given collection using the configured durability level; used in place of
+// 'cbbackupmgr' which can't perform durable writes.
+func (c *Cluster) populateFromNodeUsingPillowfight(node *Node, collection *value.Collection, items, size int,
+	prefix string,
+) error {
+	fields := log.Fields{
+		"host":       node.blueprint.Host,
+		"bucket":     "default",
+		"collection": collectionName(collection),
+		"items":      items,
+		"size":       size,
+		"threads":    c.blueprint.Bucket.Data.LoadThreads,
+		"durability": c.blueprint.Bucket.Data.Durability,
+	}
+
+	log.WithFields(fields).Info("Running 'pillowfight' to durably populate bucket")
+
+	command := fmt.Sprintf(`cbc-pillowfight -U localhost -u %s -P %s --populate-only -I %d \
+		--key-prefix %s -m %d -M %d --durability %s`,
+		c.credentials().QuotedUsername(),
+		c.credentials().QuotedPassword(),
+		items,
+		prefix,
+		size,
+		size,
+		c.blueprint.Bucket.Data.Durability,
 	)
 
 	if c.blueprint.Bucket.Data.LoadThreads != 0 {
 		command += fmt.Sprintf(" --num-threads %d", c.blueprint.Bucket.Data.LoadThreads)
+	} else {
+		command += " --num-threads $(nproc)"
 	}
 
 	if !c.blueprint.Bucket.Data.Compressible {
 		command += " --compress"
 	}
 
-	_, err := node.client.ExecuteCommand(value.NewCommand(command))
+	command += collectionExpArgs(collection, "--collection")
+
+	_, err := node.client.ExecuteCommand(value.NewCommand("%s", command))
 
 	return err
 }
 
-// clusterInit uses the CLI to initialize the cluster with an 80% ram quota and the standard cluster_run credentials.
+// clusterInit uses the CLI to initialize the cluster with the configured ram quota (80% by default), service quotas and
+// the configured credentials (the standard cluster_run credentials by default). The first node runs its configured
+// services.
 func (c *Cluster) clusterInit() error {
-	fields := log.Fields{"hosts": c.hosts(), "username": "Administrator", "password": "asdasd"}
+	fields := log.Fields{
+		"hosts":    c.hosts(),
+		"username": c.credentials().GetUsername(),
+		"services": c.nodes[0].blueprint.ServicesFlag(),
+	}
+
 	log.WithFields(fields).Info("Initializing cluster")
 
-	_, err := c.nodes[0].client.ExecuteCommand(value.NewCommand(`
-		%s couchbase-cli cluster-init -c localhost:8091 --cluster-username Administrator --cluster-password asdasd \
-			--cluster-ramsize $QUOTA`, memInfo))
+	_, err := c.controller().ExecuteCommand(value.NewCommand(`
+		%s couchbase-cli cluster-init -c %s --cluster-username %s --cluster-password %s \
+			--cluster-ramsize $CLUSTER_QUOTA --services %s%s%s`, c.clusterQuota(), c.address(),
+		c.credentials().QuotedUsername(), c.credentials().QuotedPassword(), c.nodes[0].blueprint.ServicesFlag(),
+		c.blueprint.ServiceQuotas.Flags(), c.noSSLVerifyFlag()))
 
 	return err
 }
 
-// serverAdd uses the CLI to add the given node into the cluster.
+// serverAdd uses the CLI to add the given node into the cluster, running its configured services.
 func (c *Cluster) serverAdd(node *Node) error {
-	log.WithField("host", node.blueprint.Host).Info("Adding node to cluster")
+	fields := log.Fields{"host": node.blueprint.Host, "services": node.blueprint.ServicesFlag()}
+	log.WithFields(fields).Info("Adding node to cluster")
 
 	// The first node is already in the cluster, there's nothing to do here
 	if c.nodes[0] == node {
 		return nil
 	}
 
-	_, err := c.nodes[0].client.ExecuteCommand(value.NewCommand(`
-		couchbase-cli server-add -c localhost:8091 -u Administrator -p asdasd --server-add %s \
-			--server-add-username Administrator --server-add-password asdasd --services data`, node.blueprint.Host))
+	_, err := c.controller().ExecuteCommand(value.NewCommand(`
+		couchbase-cli server-add -c %[6]s %[1]s --server-add %[2]s \
+			--server-add-username %[3]s --server-add-password %[4]s --services %[5]s`, c.cliFlags(),
+		node.blueprint.ManagementURL(c.blueprint.TLSEnabled()), c.credentials().QuotedUsername(),
+		c.credentials().QuotedPassword(), node.blueprint.ServicesFlag(), c.address()))
 
 	return err
 }
@@ -656,12 +2179,55 @@ func (c *Cluster) serverAdd(node *Node) error {
 func (c *Cluster) rebalance() error {
 	log.Info("Rebalancing cluster")
 
-	_, err := c.nodes[0].client.ExecuteCommand(
-		value.NewCommand(`couchbase-cli rebalance -c localhost:8091 -u Administrator -p asdasd`))
+	_, err := c.controller().ExecuteCommand(
+		value.NewCommand(`couchbase-cli rebalance -c %s %s`, c.address(), c.cliFlags()))
 
 	return err
 }
 
+// Scale rebalances every node after the first 'count' nodes out of the cluster, the dataset is redistributed between
+// the remaining nodes. The removed nodes are left uninitialized and should no longer be used by this cluster.
+func (c *Cluster) Scale(count int) error {
+	if !c.blueprint.IsManaged() {
+		return errUnmanaged
+	}
+
+	if count < 1 || count >= len(c.nodes) {
+		return errors.Errorf("can't scale cluster of %d node(s) down to %d node(s)", len(c.nodes), count)
+	}
+
+	removed := make([]string, 0, len(c.nodes)-count)
+
+	for _, node := range c.nodes[count:] {
+		removed = append(removed, node.blueprint.Host)
+	}
+
+	log.WithFields(log.Fields{"nodes": count, "removed": removed}).Info("Rebalancing nodes out of cluster")
+
+	return c.rebalanceWithProgress(removed)
+}
+
+// clusterQuota returns a prefix which sets '$CLUSTER_QUOTA' to the configured data service quota in megabytes, either
+// explicitly or as a percentage of the total memory on the node.
+func (c *Cluster) clusterQuota() string {
+	if c.blueprint.RAMQuotaMB != 0 {
+		return fmt.Sprintf("CLUSTER_QUOTA=%d;", c.blueprint.RAMQuotaMB)
+	}
+
+	return fmt.Sprintf(memInfo, c.blueprint.QuotaPercentage())
+}
+
+// bucketQuota returns a prefix which sets '$QUOTA' to the configured bucket quota in megabytes, either explicitly or as
+// a percentage of the data service quota.
+func (c *Cluster) bucketQuota() string {
+	if c.blueprint.Bucket.RAMQuotaMB != 0 {
+		return fmt.Sprintf("QUOTA=%d;", c.blueprint.Bucket.RAMQuotaMB)
+	}
+
+	return c.clusterQuota() + fmt.Sprintf(`QUOTA=$(echo $CLUSTER_QUOTA | awk '{ print int($0 * %d / 100) }');`,
+		c.blueprint.Bucket.QuotaPercentage())
+}
+
 // addPiTRArgs will conditionally add the PiTR flags to the given command.
 func (c *Cluster) addPiTRArgs(command string) string {
 	if c.blueprint.Bucket.PiTREnabled {
@@ -683,6 +2249,16 @@ func (c *Cluster) addPiTRArgs(command string) string {
 //
 // NOTE: We don't use a multi-node connection string currently since they're not supported until 7.0.0.
 func (c *Cluster) ConnectionString(tls bool) string {
+	// A non-default management port can't be provided in a 'couchbase://' connection string
+	if c.nodes[0].blueprint.Port != 0 {
+		schema := "http://"
+		if tls {
+			schema = "https://"
+		}
+
+		return schema + c.nodes[0].blueprint.ManagementAddress()
+	}
+
 	schema := "couchbase://"
 	if tls {
 		schema = "couchbases://"
@@ -695,6 +2271,79 @@ func (c *Cluster) ConnectionString(tls bool) string {
 	return schema + netutil.HostsToConnectionString(hosts)
 }
 
+// controller returns the machine used to run commands against the cluster as a whole (e.g. 'couchbase-cli'/REST API
+// requests); this is the first node for a managed cluster, otherwise it's the machine the cluster is attached to.
+func (c *Cluster) controller() *machine {
+	if !c.blueprint.IsManaged() {
+		return c.control
+	}
+
+	return c.nodes[0].client
+}
+
+// address returns the management address which should be used by commands run on the controller, for a managed cluster
+// this is also the address used by commands run on each node.
+func (c *Cluster) address() string {
+	if !c.blueprint.IsManaged() {
+		return c.nodes[0].blueprint.ManagementURL(c.blueprint.TLSEnabled())
+	}
+
+	return (&value.NodeBlueprint{Host: "localhost"}).ManagementURL(c.blueprint.TLSEnabled())
+}
+
+// cliFlags returns the flags which should be passed to 'couchbase-cli' to authenticate against the cluster.
+//
+// NOTE: When TLS is enabled, the certificate presented by the cluster isn't verified by 'couchbase-cli' since it's
+// connecting via 'localhost' (or the same host/port as the REST API, which doesn't verify it either).
+func (c *Cluster) cliFlags() string {
+	return c.credentials().Flags() + c.noSSLVerifyFlag()
+}
+
+// noSSLVerifyFlag returns the flag which disables certificate verification for 'couchbase-cli' when TLS is enabled.
+func (c *Cluster) noSSLVerifyFlag() string {
+	if !c.blueprint.TLSEnabled() {
+		return ""
+	}
+
+	return " --no-ssl-verify"
+}
+
+// restURL returns the base URL of the cluster manager REST API, relative to the controller.
+func (c *Cluster) restURL() string {
+	host, port := "localhost", c.blueprint.RESTConfig().GetPort()
+
+	if !c.blueprint.IsManaged() {
+		host = c.nodes[0].blueprint.Host
+
+		if c.nodes[0].blueprint.Port != 0 {
+			port = c.nodes[0].blueprint.Port
+		}
+	}
+
+	return c.blueprint.RESTConfig().Scheme() + "://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// dial connects to the given address from the controller.
+func (c *Cluster) dial(network, address string) (net.Conn, error) {
+	return c.controller().Dial(network, address)
+}
+
+// credentials returns the cluster administrator credentials.
+func (c *Cluster) credentials() *value.Credentials {
+	return c.blueprint.Credentials
+}
+
+// node returns the node in the cluster with the given host, or nil if there isn't one.
+func (c *Cluster) node(host string) *Node {
+	for _, node := range c.nodes {
+		if node.blueprint.Host == host {
+			return node
+		}
+	}
+
+	return nil
+}
+
 // hosts returns a slice of all the hostnames for the nodes in the cluster.
 func (c *Cluster) hosts() []string {
 	hosts := make([]string, 0, len(c.nodes))
@@ -707,6 +2356,13 @@ func (c *Cluster) hosts() []string {
 
 // Close releases any resources in use by the connection.
 func (c *Cluster) Close() error {
+	c.rest.Close()
+
+	// The controller of an unmanaged cluster is owned by the backup client
+	if !c.blueprint.IsManaged() {
+		return nil
+	}
+
 	return c.forEachNode(func(node *Node) error { return node.Close() })
 }
 
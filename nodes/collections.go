@@ -0,0 +1,175 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+)
+
+// createCollections creates the scopes/collections described by the data blueprint in the benchmarking bucket.
+func (c *Cluster) createCollections() error {
+	blueprint := c.blueprint.Bucket.Data.Collections
+	if blueprint == nil {
+		return nil
+	}
+
+	err := blueprint.Validate()
+	if err != nil {
+		return errors.Wrap(err, "invalid collections")
+	}
+
+	fields := log.Fields{"bucket": "default", "scopes": blueprint.Scopes, "collections": blueprint.Collections}
+	log.WithFields(fields).Info("Creating scopes/collections")
+
+	created := make(map[string]bool)
+
+	for _, collection := range blueprint.List() {
+		if !created[collection.Scope] {
+			_, err = c.rest.Execute(&restRequest{
+				Method:   http.MethodPost,
+				Endpoint: "/pools/default/buckets/default/scopes",
+				Body:     url.Values{"name": {collection.Scope}}.Encode(),
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to create scope '%s'", collection.Scope)
+			}
+
+			created[collection.Scope] = true
+		}
+
+		_, err = c.rest.Execute(&restRequest{
+			Method:   http.MethodPost,
+			Endpoint: "/pools/default/buckets/default/scopes/" + url.PathEscape(collection.Scope) + "/collections",
+			Body:     url.Values{"name": {collection.Name}}.Encode(),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create collection '%s'", collection)
+		}
+	}
+
+	return nil
+}
+
+// collections returns the collections described by the data blueprint with their UIDs resolved using the collection
+// manifest of the benchmarking bucket, returns nil when the dataset is loaded into the default collection.
+func (c *Cluster) collections() ([]*value.Collection, error) {
+	blueprint := c.blueprint.Bucket.Data.Collections
+	if blueprint == nil {
+		return nil, nil
+	}
+
+	output, err := c.rest.Execute(&restRequest{Endpoint: "/pools/default/buckets/default/scopes"})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get collection manifest")
+	}
+
+	type overlay struct {
+		Scopes []struct {
+			Name        string `json:"name"`
+			Collections []struct {
+				Name string `json:"name"`
+				UID  string `json:"uid"`
+			} `json:"collections"`
+		} `json:"scopes"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(output, &decoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal collection manifest")
+	}
+
+	uids := make(map[string]string)
+
+	for _, scope := range decoded.Scopes {
+		for _, collection := range scope.Collections {
+			uids[scope.Name+"."+collection.Name] = collection.UID
+		}
+	}
+
+	collections := blueprint.List()
+
+	for _, collection := range collections {
+		uid, ok := uids[collection.String()]
+		if !ok {
+			return nil, errors.Errorf("collection '%s' doesn't exist, the cluster must be reprovisioned", collection)
+		}
+
+		collection.UID = uid
+	}
+
+	return collections, nil
+}
+
+// forCollections distributes the given number of items between the given collections using the configured
+// distribution, then runs the provided function for each collection in turn; when no collections are given, the
+// function is run once for the default collection (which is passed as nil).
+func (c *Cluster) forCollections(collections []*value.Collection, items int,
+	fn func(collection *value.Collection, items int) error,
+) error {
+	if len(collections) == 0 {
+		return fn(nil, items)
+	}
+
+	for idx, share := range c.blueprint.Bucket.Data.Collections.Distribute(items) {
+		if share == 0 {
+			continue
+		}
+
+		err := fn(collections[idx], share)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load collection '%s'", collections[idx])
+		}
+	}
+
+	return nil
+}
+
+// collectionName returns the name of the given collection, used when logging.
+func collectionName(collection *value.Collection) string {
+	if collection == nil {
+		return "_default._default"
+	}
+
+	return collection.String()
+}
+
+// generateCollectionArgs returns the arguments which limit 'cbbackupmgr generate' to the given collection, which is
+// identified by its UID.
+func generateCollectionArgs(collection *value.Collection) string {
+	if collection == nil {
+		return ""
+	}
+
+	return " --collection-id " + collection.UID
+}
+
+// collectionExpArgs returns the given flag along with the 'scope.collection' expression for the given collection, for
+// tools which accept collections by name (e.g. 'cbimport'/'cbc-pillowfight').
+func collectionExpArgs(collection *value.Collection, flag string) string {
+	if collection == nil {
+		return ""
+	}
+
+	return " " + flag + " " + collection.String()
+}
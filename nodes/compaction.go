@@ -0,0 +1,332 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+)
+
+// ConfigureAutoCompaction applies the auto-compaction settings from the bucket blueprint, so that background compaction
+// doesn't fire mid-iteration. Returns the effective settings (read back from the cluster, nil if there's no blueprint)
+// and a function which restores the settings which were in place beforehand.
+func (c *Cluster) ConfigureAutoCompaction() (*value.AutoCompactionSettings, func(), error) {
+	config := c.blueprint.Bucket.AutoCompaction
+	if config == nil {
+		return nil, func() {}, nil
+	}
+
+	err := config.Validate()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid auto-compaction settings")
+	}
+
+	original, err := c.autoCompactionSettings()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get auto-compaction settings")
+	}
+
+	log.WithField("disabled", config.Disabled).Info("Configuring auto-compaction")
+
+	err = c.setAutoCompaction(newCompactionSettings(config, original))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to set auto-compaction settings")
+	}
+
+	restore := func() {
+		log.Info("Restoring auto-compaction settings")
+
+		if err := c.setAutoCompaction(original); err != nil {
+			log.Warnf("Failed to restore auto-compaction settings: %s", err)
+		}
+	}
+
+	effective, err := c.autoCompactionSettings()
+	if err != nil {
+		restore()
+		return nil, nil, errors.Wrap(err, "failed to get effective auto-compaction settings")
+	}
+
+	return effective.value(), restore, nil
+}
+
+// compactionSettings are the cluster-wide auto-compaction settings in the form used by ns_server; a nil threshold
+// indicates that it's disabled and sizes are in bytes.
+type compactionSettings struct {
+	databasePercentage *uint64
+	databaseSize       *uint64
+	viewPercentage     *uint64
+	viewSize           *uint64
+	window             *compactionWindow
+	parallel           bool
+
+	// purgeInterval/index aren't described by the blueprint, they're carried over from the original settings since
+	// ns_server resets any settings which aren't posted.
+	purgeInterval *float64
+	index         *indexCompaction
+}
+
+// indexCompaction are the auto-compaction settings for the index service, in the form used by ns_server.
+type indexCompaction struct {
+	mode       string
+	percentage *uint64
+	days       string
+	interval   *compactionWindow
+}
+
+// compactionWindow is the period of the day in which auto-compaction is allowed to run, in the form used by ns_server.
+type compactionWindow struct {
+	FromHour     int  `json:"fromHour"`
+	FromMinute   int  `json:"fromMinute"`
+	ToHour       int  `json:"toHour"`
+	ToMinute     int  `json:"toMinute"`
+	AbortOutside bool `json:"abortOutside"`
+}
+
+// newCompactionSettings converts the given auto-compaction blueprint into the form used by ns_server, settings which
+// aren't described by the blueprint are taken from the original settings.
+func newCompactionSettings(config *value.AutoCompactionBlueprint, original *compactionSettings) *compactionSettings {
+	settings := &compactionSettings{
+		parallel:      config.ParallelCompaction,
+		purgeInterval: original.purgeInterval,
+		index:         original.index,
+	}
+
+	if !config.Disabled {
+		settings.databasePercentage, settings.databaseSize = thresholdSettings(config.DatabaseThreshold())
+		settings.viewPercentage, settings.viewSize = thresholdSettings(config.ViewThreshold())
+	}
+
+	if config.TimeWindow != nil {
+		settings.window = &compactionWindow{AbortOutside: config.TimeWindow.AbortOutside}
+		settings.window.FromHour, settings.window.FromMinute = config.TimeWindow.FromTime()
+		settings.window.ToHour, settings.window.ToMinute = config.TimeWindow.ToTime()
+	}
+
+	return settings
+}
+
+// thresholdSettings returns the percentage/size (in bytes) for the given threshold, unset values are returned as nil.
+func thresholdSettings(threshold *value.FragmentationThreshold) (*uint64, *uint64) {
+	var percentage, size *uint64
+
+	if threshold.Percentage != 0 {
+		percentage = new(uint64)
+		*percentage = uint64(threshold.Percentage)
+	}
+
+	if threshold.SizeMB != 0 {
+		size = new(uint64)
+		*size = threshold.SizeMB * 1024 * 1024
+	}
+
+	return percentage, size
+}
+
+// form returns the form which should be posted to ns_server to apply the settings.
+func (s *compactionSettings) form() url.Values {
+	form := url.Values{"parallelDBAndViewCompaction": {strconv.FormatBool(s.parallel)}}
+
+	for name, threshold := range map[string]*uint64{
+		"databaseFragmentationThreshold[percentage]": s.databasePercentage,
+		"databaseFragmentationThreshold[size]":       s.databaseSize,
+		"viewFragmentationThreshold[percentage]":     s.viewPercentage,
+		"viewFragmentationThreshold[size]":           s.viewSize,
+	} {
+		if threshold != nil {
+			form.Set(name, strconv.FormatUint(*threshold, 10))
+		}
+	}
+
+	if s.window != nil {
+		form.Set("allowedTimePeriod[fromHour]", strconv.Itoa(s.window.FromHour))
+		form.Set("allowedTimePeriod[fromMinute]", strconv.Itoa(s.window.FromMinute))
+		form.Set("allowedTimePeriod[toHour]", strconv.Itoa(s.window.ToHour))
+		form.Set("allowedTimePeriod[toMinute]", strconv.Itoa(s.window.ToMinute))
+		form.Set("allowedTimePeriod[abortOutside]", strconv.FormatBool(s.window.AbortOutside))
+	}
+
+	if s.purgeInterval != nil {
+		form.Set("purgeInterval", strconv.FormatFloat(*s.purgeInterval, 'f', -1, 64))
+	}
+
+	if s.index == nil {
+		return form
+	}
+
+	if s.index.mode != "" {
+		form.Set("indexCompactionMode", s.index.mode)
+	}
+
+	if s.index.percentage != nil {
+		form.Set("indexFragmentationThreshold[percentage]", strconv.FormatUint(*s.index.percentage, 10))
+	}
+
+	if s.index.days != "" {
+		form.Set("indexCircularCompaction[daysOfWeek]", s.index.days)
+	}
+
+	if s.index.interval != nil {
+		form.Set("indexCircularCompaction[interval][fromHour]", strconv.Itoa(s.index.interval.FromHour))
+		form.Set("indexCircularCompaction[interval][fromMinute]", strconv.Itoa(s.index.interval.FromMinute))
+		form.Set("indexCircularCompaction[interval][toHour]", strconv.Itoa(s.index.interval.ToHour))
+		form.Set("indexCircularCompaction[interval][toMinute]", strconv.Itoa(s.index.interval.ToMinute))
+		form.Set("indexCircularCompaction[interval][abortOutside]", strconv.FormatBool(s.index.interval.AbortOutside))
+	}
+
+	return form
+}
+
+// value returns the settings in the form included in the report.
+func (s *compactionSettings) value() *value.AutoCompactionSettings {
+	threshold := func(percentage, size *uint64) *value.FragmentationThreshold {
+		if percentage == nil && size == nil {
+			return nil
+		}
+
+		threshold := &value.FragmentationThreshold{}
+
+		if percentage != nil {
+			threshold.Percentage = int(*percentage)
+		}
+
+		if size != nil {
+			threshold.SizeMB = *size / 1024 / 1024
+		}
+
+		return threshold
+	}
+
+	settings := &value.AutoCompactionSettings{
+		Database: threshold(s.databasePercentage, s.databaseSize),
+		View:     threshold(s.viewPercentage, s.viewSize),
+		Parallel: s.parallel,
+	}
+
+	if s.window != nil {
+		settings.TimeWindow = &value.CompactionTimeWindow{
+			From:         fmt.Sprintf("%02d:%02d", s.window.FromHour, s.window.FromMinute),
+			To:           fmt.Sprintf("%02d:%02d", s.window.ToHour, s.window.ToMinute),
+			AbortOutside: s.window.AbortOutside,
+		}
+	}
+
+	if s.purgeInterval != nil {
+		settings.PurgeInterval = *s.purgeInterval
+	}
+
+	if s.index != nil {
+		settings.IndexMode = s.index.mode
+
+		if s.index.percentage != nil {
+			settings.IndexPercentage = int(*s.index.percentage)
+		}
+	}
+
+	return settings
+}
+
+// autoCompactionSettings returns the current cluster-wide auto-compaction settings.
+func (c *Cluster) autoCompactionSettings() (*compactionSettings, error) {
+	output, err := c.rest.Execute(&restRequest{Endpoint: "/settings/autoCompaction"})
+	if err != nil {
+		return nil, err
+	}
+
+	// Thresholds are either a number, or the string "undefined" when they're disabled
+	type threshold struct {
+		Percentage json.RawMessage `json:"percentage"`
+		Size       json.RawMessage `json:"size"`
+	}
+
+	type overlay struct {
+		Settings struct {
+			Database      threshold         `json:"databaseFragmentationThreshold"`
+			View          threshold         `json:"viewFragmentationThreshold"`
+			Window        *compactionWindow `json:"allowedTimePeriod"`
+			Parallel      bool              `json:"parallelDBAndViewCompaction"`
+			IndexMode     string            `json:"indexCompactionMode"`
+			IndexFrag     *threshold        `json:"indexFragmentationThreshold"`
+			IndexCircular *struct {
+				Days     string            `json:"daysOfWeek"`
+				Interval *compactionWindow `json:"interval"`
+			} `json:"indexCircularCompaction"`
+		} `json:"autoCompactionSettings"`
+		PurgeInterval *float64 `json:"purgeInterval"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(output, &decoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal response")
+	}
+
+	number := func(raw json.RawMessage) *uint64 {
+		var parsed uint64
+		if json.Unmarshal(raw, &parsed) != nil {
+			return nil
+		}
+
+		return &parsed
+	}
+
+	settings := &compactionSettings{
+		databasePercentage: number(decoded.Settings.Database.Percentage),
+		databaseSize:       number(decoded.Settings.Database.Size),
+		viewPercentage:     number(decoded.Settings.View.Percentage),
+		viewSize:           number(decoded.Settings.View.Size),
+		window:             decoded.Settings.Window,
+		parallel:           decoded.Settings.Parallel,
+		purgeInterval:      decoded.PurgeInterval,
+	}
+
+	// The index settings are only returned by clusters which support them
+	if decoded.Settings.IndexMode == "" && decoded.Settings.IndexFrag == nil && decoded.Settings.IndexCircular == nil {
+		return settings, nil
+	}
+
+	settings.index = &indexCompaction{mode: decoded.Settings.IndexMode}
+
+	if decoded.Settings.IndexFrag != nil {
+		settings.index.percentage = number(decoded.Settings.IndexFrag.Percentage)
+	}
+
+	if decoded.Settings.IndexCircular != nil {
+		settings.index.days = decoded.Settings.IndexCircular.Days
+		settings.index.interval = decoded.Settings.IndexCircular.Interval
+	}
+
+	return settings, nil
+}
+
+// setAutoCompaction sets the cluster-wide auto-compaction settings, replacing any existing settings.
+func (c *Cluster) setAutoCompaction(settings *compactionSettings) error {
+	_, err := c.rest.Execute(&restRequest{
+		Method:   http.MethodPost,
+		Endpoint: "/controller/setAutoCompaction",
+		Body:     settings.form().Encode(),
+	})
+
+	return err
+}
@@ -0,0 +1,283 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/couchbase/tools-common/strings/format"
+	"github.com/pkg/errors"
+)
+
+// maxClockSkew is the maximum difference between the clock on a host and the machine running 'cbtools-autobench',
+// skewed clocks make correlating the logs/results difficult (and object stores reject requests which are very skewed).
+const maxClockSkew = 5 * time.Second
+
+// doctor runs the preflight checks against a single host, recording the result of each check.
+type doctor struct {
+	host   string
+	checks value.Checks
+}
+
+// record the result of the named check, a nil error indicates that the check passed.
+func (d *doctor) record(name, detail string, err error) {
+	check := &value.Check{Host: d.host, Name: name, Status: value.CheckPassed, Detail: detail}
+
+	if err != nil {
+		check.Status, check.Detail = value.CheckFailed, err.Error()
+	}
+
+	d.checks = append(d.checks, check)
+}
+
+// skip records that the named checks weren't run, along with the reason why.
+func (d *doctor) skip(reason string, names ...string) {
+	for _, name := range names {
+		d.checks = append(d.checks, &value.Check{Host: d.host, Name: name, Status: value.CheckSkipped, Detail: reason})
+	}
+}
+
+// Diagnose runs the preflight checks against every host described by the given blueprint, returning the result of each
+// check. Failing checks don't prevent the remaining checks from being run, so that every problem is reported at once.
+//
+// NOTE: The hosts of an unmanaged cluster aren't checked, since they're never connected to directly.
+func Diagnose(config *value.SSHConfig, blueprint *value.Blueprint, benchmark *value.BenchmarkConfig) value.Checks {
+	var (
+		checks    value.Checks
+		connected = make(map[*value.NodeBlueprint]*Node)
+	)
+
+	defer func() {
+		for _, node := range connected {
+			_ = node.Close()
+		}
+	}()
+
+	if blueprint.Cluster != nil && blueprint.Cluster.IsManaged() {
+		required := datasetSize(blueprint.Cluster) / uint64(max(1, len(blueprint.Cluster.Nodes)))
+
+		for _, nodeBlueprint := range blueprint.Cluster.Nodes {
+			d := &doctor{host: hostName(nodeBlueprint.Host)}
+
+			nodeBlueprint.Paths = blueprint.Cluster.CBPaths
+
+			node := d.connect(config, nodeBlueprint)
+			if node != nil {
+				connected[nodeBlueprint] = node
+
+				path := nodeBlueprint.DataDirectory()
+				if path == "" {
+					path = nodeBlueprint.Paths.Install()
+				}
+
+				d.checkFreeSpace(node, "data path", path, required)
+			}
+
+			checks = append(checks, d.checks...)
+		}
+	}
+
+	if blueprint.BackupClient != nil {
+		checks = append(checks, diagnoseBackupClient(config, blueprint, benchmark, connected)...)
+	}
+
+	return checks
+}
+
+// diagnoseBackupClient runs the preflight checks against the backup client, the connection to a co-located cluster
+// node is reused (meaning the host level checks aren't repeated).
+func diagnoseBackupClient(config *value.SSHConfig, blueprint *value.Blueprint, benchmark *value.BenchmarkConfig,
+	connected map[*value.NodeBlueprint]*Node,
+) value.Checks {
+	var (
+		d      = &doctor{host: hostName(blueprint.BackupClient.Host)}
+		client = &BackupClient{blueprint: blueprint.BackupClient}
+	)
+
+	if colocated := blueprint.Colocated(); colocated != nil && connected[colocated] != nil {
+		client.node = connected[colocated]
+	} else {
+		client.node = d.connect(config, &value.NodeBlueprint{
+			Host:  blueprint.BackupClient.Host,
+			Local: blueprint.BackupClient.Local,
+			SSH:   blueprint.BackupClient.SSH,
+			Paths: blueprint.BackupClient.CBPaths,
+		})
+
+		if client.node != nil {
+			defer client.node.Close()
+		}
+	}
+
+	if benchmark == nil || benchmark.CBMConfig == nil {
+		return d.checks
+	}
+
+	if client.node == nil {
+		d.skip("host is unreachable", "archive")
+		return d.checks
+	}
+
+	for _, task := range benchmark.Tasks() {
+		if strings.HasPrefix(task.CBMConfig.Archive, "s3://") {
+			d.record("object store credentials", task.CBMConfig.Archive, client.validateObjectStore(task))
+			continue
+		}
+
+		d.checkFreeSpace(client.node, "archive", task.CBMConfig.Archive, datasetSize(blueprint.Cluster))
+	}
+
+	if benchmark.CBMConfig.ObjStagingDirectory != "" {
+		d.checkFreeSpace(client.node, "staging directory", benchmark.CBMConfig.ObjStagingDirectory, 0)
+	}
+
+	return d.checks
+}
+
+// hostName returns the name used to identify the given host in the checks, local machines don't require a host.
+func hostName(host string) string {
+	if host == "" {
+		return "localhost"
+	}
+
+	return host
+}
+
+// connect to the host described by the given blueprint then run the host level checks, returns nil if the connection
+// failed (in which case the remaining host level checks are skipped). Note that connecting fails for unsupported
+// platforms, so a successful connection also indicates the platform is supported.
+func (d *doctor) connect(config *value.SSHConfig, blueprint *value.NodeBlueprint) *Node {
+	node, err := NewNode(config, blueprint)
+	if err != nil {
+		d.record("connection", "", err)
+		d.skip("host is unreachable", "root access", "clock skew")
+
+		return nil
+	}
+
+	d.record("connection", fmt.Sprintf("platform '%s'", node.client.Platform()), nil)
+	d.record("root access", "", checkRoot(node))
+
+	skew, err := measureClockSkew(node)
+	if err == nil && (skew > maxClockSkew || skew < -maxClockSkew) {
+		err = errors.Errorf("clock is skewed by %s, which exceeds the maximum of %s", skew, maxClockSkew)
+	}
+
+	d.record("clock skew", fmt.Sprintf("skewed by %s", skew), err)
+
+	return node
+}
+
+// checkRoot returns an error if commands on the given node aren't run as root, which is required to install packages.
+func checkRoot(node *Node) error {
+	output, err := node.client.ExecuteCommand(value.NewCommand("id -u"))
+	if err != nil {
+		return errors.Wrap(err, "failed to determine user")
+	}
+
+	if uid := strings.TrimSpace(string(output)); uid != "0" {
+		return errors.Errorf("commands are run as uid %s, ssh as root (sudo is not used)", uid)
+	}
+
+	return nil
+}
+
+// measureClockSkew returns the difference between the clock on the given node and the local clock, accounting for the
+// round trip time of the command.
+func measureClockSkew(node *Node) (time.Duration, error) {
+	before := time.Now()
+
+	output, err := node.client.ExecuteCommand(value.NewCommand("date +%%s%%N"))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read clock")
+	}
+
+	after := time.Now()
+
+	nanos, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to parse clock")
+	}
+
+	return time.Unix(0, nanos).Sub(before.Add(after.Sub(before) / 2)).Round(time.Millisecond), nil
+}
+
+// checkFreeSpace records whether the filesystem for the given path on the node has at least the required free space,
+// the path doesn't need to exist yet (its closest existing parent is checked instead).
+func (d *doctor) checkFreeSpace(node *Node, name, path string, required uint64) {
+	name = fmt.Sprintf("%s free space (%s)", name, path)
+
+	output, err := node.client.ExecuteCommand(value.NewCommand(
+		`p=%s; while [ ! -e "$p" ]; do p=$(dirname "$p"); done; df --output=avail -B1 "$p" | tail -1`, path))
+	if err != nil {
+		d.record(name, "", errors.Wrap(err, "failed to run 'df'"))
+		return
+	}
+
+	free, err := strconv.ParseUint(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		d.record(name, "", errors.Wrap(err, "failed to parse free space"))
+		return
+	}
+
+	detail := fmt.Sprintf("%s free, %s required", format.Bytes(free), format.Bytes(required))
+
+	if free < required {
+		err = errors.New(detail)
+	}
+
+	d.record(name, detail, err)
+}
+
+// datasetSize returns the size of the dataset which will be loaded into the cluster, used to estimate the space which
+// will be used by the cluster/archive.
+func datasetSize(blueprint *value.ClusterBlueprint) uint64 {
+	if blueprint == nil || blueprint.Bucket == nil || blueprint.Bucket.Data == nil {
+		return 0
+	}
+
+	return uint64(blueprint.Bucket.Data.Items * blueprint.Bucket.Data.Size)
+}
+
+// validateObjectStore returns an error if the backup client is unable to access the bucket for the given task's
+// archive using the configured credentials.
+func (b *BackupClient) validateObjectStore(config *value.BenchmarkConfig) error {
+	if config.CBMConfig.ObjAuthByInstanceMetadata {
+		return b.ValidateInstanceProfile(config)
+	}
+
+	command := awsEnvironment(config.CBMConfig) + "aws s3api head-bucket --bucket " +
+		strings.SplitN(strings.TrimPrefix(config.CBMConfig.Archive, "s3://"), "/", 2)[0]
+
+	if config.CBMConfig.ObjEndpoint != "" {
+		command += " --endpoint-url " + config.CBMConfig.ObjEndpoint
+	}
+
+	if config.CBMConfig.ObjNoSSLVerify {
+		command += " --no-verify-ssl"
+	}
+
+	_, err := b.node.client.ExecuteCommand(value.NewCommand("%s", command))
+	if err != nil {
+		return errors.New("unable to access the bucket using the configured credentials")
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"context"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/couchbase/tools-common/sync/v2/hofp"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ExecHostsCluster runs ad-hoc commands on the cluster nodes.
+	ExecHostsCluster = "cluster"
+
+	// ExecHostsClient runs ad-hoc commands on the backup client.
+	ExecHostsClient = "client"
+
+	// ExecHostsAll runs ad-hoc commands on the cluster nodes and the backup client.
+	ExecHostsAll = "all"
+)
+
+// ExecResult is the result of running an ad-hoc command on a single host.
+type ExecResult struct {
+	Host   string
+	Output []byte
+	Err    error
+}
+
+// Exec runs the given command concurrently on the selected hosts (see 'ExecHosts*') described by the blueprint,
+// returning the result for each host in the order they're described. A backup client which is co-located with a
+// cluster node is only run against once.
+//
+// NOTE: Failing to connect to/run the command on a host is recorded in its result, rather than returned.
+func Exec(config *value.SSHConfig, blueprint *value.Blueprint, hosts, command string) ([]*ExecResult, error) {
+	targets, err := execTargets(blueprint, hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		pool    = hofp.NewPool(hofp.Options{Size: max(1, len(targets))})
+		results = make([]*ExecResult, len(targets))
+	)
+
+	run := func(idx int, target *value.NodeBlueprint) {
+		results[idx] = &ExecResult{Host: hostName(target.Host)}
+
+		node, err := NewNode(config, target)
+		if err != nil {
+			results[idx].Err = errors.Wrap(err, "failed to connect")
+			return
+		}
+		defer node.Close()
+
+		// The command is run as given, since 'NewCommand' would strip the newlines/tabs from a multi-line command
+		results[idx].Output, results[idx].Err = node.client.ExecuteCommand(value.Command(command))
+	}
+
+	queue := func(idx int, target *value.NodeBlueprint) error {
+		return pool.Queue(func(_ context.Context) error {
+			run(idx, target)
+			return nil
+		})
+	}
+
+	for idx, target := range targets {
+		if queue(idx, target) != nil {
+			break
+		}
+	}
+
+	err = pool.Stop()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to stop pool")
+	}
+
+	return results, nil
+}
+
+// execTargets returns the blueprints of the hosts which ad-hoc commands should be run against, the nodes of an
+// unmanaged cluster are never connected to directly so are skipped when running against all the hosts.
+func execTargets(blueprint *value.Blueprint, hosts string) ([]*value.NodeBlueprint, error) {
+	if hosts != ExecHostsCluster && hosts != ExecHostsClient && hosts != ExecHostsAll {
+		return nil, errors.Errorf("unknown hosts '%s', expected one of cluster/client/all", hosts)
+	}
+
+	var (
+		managed = blueprint.Cluster != nil && blueprint.Cluster.IsManaged()
+		targets []*value.NodeBlueprint
+	)
+
+	if hosts == ExecHostsCluster && !managed {
+		return nil, errors.New("commands can't be run on the nodes of an unmanaged cluster")
+	}
+
+	if hosts != ExecHostsClient && managed {
+		for _, node := range blueprint.Cluster.Nodes {
+			node.Paths = blueprint.Cluster.CBPaths
+			targets = append(targets, node)
+		}
+	}
+
+	if hosts == ExecHostsCluster || (hosts == ExecHostsAll && managed && blueprint.Colocated() != nil) {
+		return targets, nil
+	}
+
+	if blueprint.BackupClient == nil {
+		return nil, errors.New("config does not describe a backup client")
+	}
+
+	return append(targets, &value.NodeBlueprint{
+		Host:  blueprint.BackupClient.Host,
+		Local: blueprint.BackupClient.Local,
+		SSH:   blueprint.BackupClient.SSH,
+		Paths: blueprint.BackupClient.CBPaths,
+	}), nil
+}
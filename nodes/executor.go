@@ -0,0 +1,223 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/pkg/errors"
+)
+
+// Executor is the interface used to interact with a machine, this allows nodes to be driven by different backends (for
+// example ssh, Docker or a local machine) without changing how provisioning/benchmarking is performed.
+type Executor interface {
+	// Platform returns the platform of the machine that commands are being run against.
+	Platform() value.Platform
+
+	// ExecuteCommand executes the given command on the machine, returning the combined output.
+	ExecuteCommand(command value.Command) ([]byte, error)
+
+	// SecureUpload copies the local file at the source path onto the machine at the sink path.
+	SecureUpload(source, sink string) error
+
+	// SecureDownload copies the file at the source path on the machine to the local sink path.
+	SecureDownload(source, sink string) error
+
+	// Dial connects to the given address from the machine, allowing access to services which are only reachable from
+	// the machine itself (e.g. the REST API of a node listening on localhost).
+	Dial(network, address string) (net.Conn, error)
+
+	// Close releases any resources in use by the executor.
+	Close() error
+}
+
+// machine wraps an executor and exposes some higher level functionality required when setting up/performing
+// benchmarks, these are implemented using the operations provided by the executor.
+type machine struct {
+	Executor
+}
+
+// InstallPackageAt installs the package at the provided path on the machine.
+func (m *machine) InstallPackageAt(path string) error {
+	_, err := m.ExecuteCommand(m.Platform().CommandInstallPackageAt(path))
+	return err
+}
+
+// InstallPackages uses the platform specific package manager to install the given packages, any packages which are
+// already installed are skipped (meaning nothing is fetched when all the packages are installed).
+func (m *machine) InstallPackages(packages ...string) error {
+	missing := make([]string, 0, len(packages))
+
+	for _, name := range packages {
+		if !m.PackageInstalled(name) {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	_, err := m.ExecuteCommand(m.Platform().CommandInstallPackages(missing...))
+
+	return err
+}
+
+// InstallPackagesFrom installs all the packages in the given directory on the machine, without using any remote
+// repositories.
+func (m *machine) InstallPackagesFrom(dir string) error {
+	_, err := m.ExecuteCommand(m.Platform().CommandInstallPackagesFrom(dir))
+	return err
+}
+
+// PackageInstalled returns a boolean indicating whether the package with the given name is installed on the machine.
+func (m *machine) PackageInstalled(name string) bool {
+	_, err := m.ExecuteCommand(m.Platform().CommandPackageInstalled(name))
+	return err == nil
+}
+
+// UninstallPackages uses the platform specific package manager to uninstall the given package.
+func (m *machine) UninstallPackages(packages ...string) error {
+	_, err := m.ExecuteCommand(m.Platform().CommandUninstallPackages(packages...))
+	return err
+}
+
+// FileExists returns a boolean indicating whether a file with the given path exists on the machine.
+func (m *machine) FileExists(path string) bool {
+	_, err := m.ExecuteCommand(value.NewCommand("test -e %s", path))
+	return err == nil
+}
+
+// CommandExists returns a boolean indicating whether the given command is available on the remote machine.
+func (m *machine) CommandExists(name string) bool {
+	_, err := m.ExecuteCommand(value.NewCommand("command -v %s", name))
+	return err == nil
+}
+
+// DirectorySize returns the size in bytes of the directory at the given path on the machine, as reported by 'du'.
+func (m *machine) DirectorySize(path string) (uint64, error) {
+	output, err := m.ExecuteCommand(value.NewCommand("du -sb %s | cut -f1", path))
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(string(output)), 10, 64)
+}
+
+// CountFiles returns the number of files in the directory at the given path on the machine, the filter may be used to
+// pass additional tests to 'find' e.g. "-name '*.sqlite'".
+func (m *machine) CountFiles(path, filter string) (int, error) {
+	output, err := m.ExecuteCommand(value.NewCommand("find %s -type f %s | wc -l", path, filter))
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// CPUTime returns the total time the CPUs on the machine have spent busy (i.e. not idle/waiting for I/O) since boot, as
+// reported by '/proc/stat'.
+func (m *machine) CPUTime() (time.Duration, error) {
+	output, err := m.ExecuteCommand(value.NewCommand("getconf CLK_TCK; head -n 1 /proc/stat"))
+	if err != nil {
+		return 0, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 2 {
+		return 0, errors.Errorf("unexpected output '%s'", output)
+	}
+
+	ticks, err := strconv.ParseUint(strings.TrimSpace(lines[0]), 10, 64)
+	if err != nil || ticks == 0 {
+		return 0, errors.Errorf("invalid clock ticks '%s'", lines[0])
+	}
+
+	// The aggregate line is 'cpu user nice system idle iowait irq softirq steal ...'
+	fields := strings.Fields(lines[1])
+	if len(fields) < 9 {
+		return 0, errors.Errorf("unexpected cpu stats '%s'", lines[1])
+	}
+
+	var busy uint64
+
+	for _, idx := range []int{1, 2, 3, 6, 7, 8} {
+		jiffies, err := strconv.ParseUint(fields[idx], 10, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to parse cpu stat '%s'", fields[idx])
+		}
+
+		busy += jiffies
+	}
+
+	return time.Duration(busy) * time.Second / time.Duration(ticks), nil
+}
+
+// RemoveFile removes the file at the given path on the machine.
+func (m *machine) RemoveFile(path string) error {
+	_, err := m.ExecuteCommand(value.NewCommand("rm %s", path))
+	return err
+}
+
+// WriteFile writes the given data to the file at the given path on the machine, this is done by uploading a temporary
+// local copy of the file.
+func (m *machine) WriteFile(path string, data []byte) error {
+	dir, err := os.MkdirTemp("", "autobench-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary directory")
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	local := filepath.Join(dir, filepath.Base(path))
+
+	err = os.WriteFile(local, data, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write temporary file")
+	}
+
+	return m.SecureUpload(local, path)
+}
+
+// RemoveDirectory removes the directory at the given path on the machine.
+func (m *machine) RemoveDirectory(path string) error {
+	_, err := m.ExecuteCommand(value.NewCommand("rm -rf %s", path))
+	return err
+}
+
+// EmptyDirectory removes the contents of the directory at the given path on the machine, leaving the directory itself
+// in place (for example, when it's a mount point).
+func (m *machine) EmptyDirectory(path string) error {
+	_, err := m.ExecuteCommand(value.NewCommand("test ! -e %[1]s || find %[1]s -mindepth 1 -delete", path))
+	return err
+}
+
+// Sync runs 'sync' on the machine ensuring all dirty package are written to disk.
+func (m *machine) Sync() error {
+	_, err := m.ExecuteCommand(value.NewCommand("sync"))
+	return err
+}
+
+// FlushCaches sync then flushes the caches on the machine; this allows for more consistent benchmark results.
+func (m *machine) FlushCaches() error {
+	_, err := m.ExecuteCommand(value.NewCommand("sync; echo 3 > /proc/sys/vm/drop_caches"))
+	return err
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/couchbase/tools-common/strings/format"
+)
+
+// heartbeatInterval is the interval at which a heartbeat is logged whilst running long operations.
+const heartbeatInterval = time.Minute
+
+// progressFunc returns the current progress of an operation along with the expected total, a zero total indicates that
+// it's unknown.
+type progressFunc func() (uint64, uint64, error)
+
+// heartbeat periodically logs the elapsed time of the given operation so that a slow operation can be distinguished
+// from one which has hung. When a progress function is provided, the current progress and a rough ETA are included. The
+// returned function stops the heartbeat.
+func heartbeat(operation string, progress progressFunc, formatter func(uint64) string) func() {
+	var (
+		start   = time.Now()
+		done    = make(chan struct{})
+		stopped = make(chan struct{})
+	)
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			elapsed := time.Since(start)
+			fields := log.Fields{"elapsed": format.Duration(elapsed)}
+
+			if progress != nil {
+				addProgress(fields, progress, formatter, elapsed)
+			}
+
+			log.WithFields(fields).Infof("%s, still running", operation)
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// addProgress adds the current progress/ETA of an operation to the given fields, failing to get the progress isn't
+// fatal since it's purely informational.
+func addProgress(fields log.Fields, progress progressFunc, formatter func(uint64) string, elapsed time.Duration) {
+	current, total, err := progress()
+	if err != nil {
+		log.Debugf("Failed to get progress: %s", err)
+		return
+	}
+
+	fields["progress"] = formatter(current)
+
+	if total == 0 {
+		return
+	}
+
+	fields["progress"] = fmt.Sprintf("%s/%s", formatter(current), formatter(total))
+
+	if current == 0 || current >= total {
+		return
+	}
+
+	fields["eta"] = format.Duration(time.Duration(float64(elapsed) * float64(total-current) / float64(current)))
+}
+
+// formatCount returns the given count as a string, used when reporting progress in items.
+func formatCount(count uint64) string {
+	return fmt.Sprint(count)
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// integrityBaseline is the path on the first cluster node to the export of the benchmarking bucket once the last
+	// backup has been created.
+	integrityBaseline = "/tmp/autobench-integrity-baseline.json"
+
+	// integrityRestored is the path on the first cluster node to the export of the bucket after being restored.
+	integrityRestored = "/tmp/autobench-integrity-restored.json"
+
+	// exportKey is the field which the key of each document is added to when exported, see 'exportDocuments'.
+	exportKey = "__autobench_key"
+
+	// integrityExamples is the maximum number of mismatched keys included in the report.
+	integrityExamples = 10
+)
+
+// recordIntegrity exports the documents in the benchmarking bucket, so that restored documents may later be verified
+// using 'verifyIntegrity'; the returned function removes the export.
+func (c *Cluster) recordIntegrity() (func(), error) {
+	log.WithField("bucket", "default").Info("Recording documents to verify integrity")
+
+	err := c.exportDocuments("default", integrityBaseline)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to export documents")
+	}
+
+	return func() { _ = c.controller().RemoveFile(integrityBaseline) }, nil
+}
+
+// verifyIntegrity exports the documents in the bucket which was restored into, then compares them with the documents
+// recorded by 'recordIntegrity'.
+func (c *Cluster) verifyIntegrity() (*value.Integrity, error) {
+	log.WithField("bucket", c.restoreBucket()).Info("Verifying integrity of restored documents")
+
+	err := c.exportDocuments(c.restoreBucket(), integrityRestored)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to export documents")
+	}
+
+	defer func() { _ = c.controller().RemoveFile(integrityRestored) }()
+
+	// Documents which differ appear on both sides of the diff, so the keys of the mismatched documents are deduplicated
+	output, err := c.controller().ExecuteCommand(value.NewCommand(`wc -l < %[1]s; wc -l < %[2]s;
+		LC_ALL=C comm -3 %[1]s %[2]s | grep -o '"%[3]s":"[^"]*"' | cut -d '"' -f 4 | LC_ALL=C sort -u > %[2]s.keys;
+		wc -l < %[2]s.keys; head -n %[4]d %[2]s.keys; rm -f %[2]s.keys`,
+		integrityRestored, integrityBaseline, exportKey, integrityExamples))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compare documents")
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) < 3 {
+		return nil, errors.Errorf("unexpected output '%s'", output)
+	}
+
+	counts := make([]uint64, 3)
+
+	for idx := range counts {
+		counts[idx], err = strconv.ParseUint(strings.TrimSpace(lines[idx]), 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse count '%s'", lines[idx])
+		}
+	}
+
+	integrity := &value.Integrity{
+		Documents:  counts[0],
+		Expected:   counts[1],
+		Mismatched: counts[2],
+		Examples:   lines[3:],
+	}
+
+	fields := log.Fields{"documents": integrity.Documents, "expected": integrity.Expected}
+
+	if !integrity.Verified() {
+		log.WithFields(fields).Errorf("Restored documents do not match, %d mismatched", integrity.Mismatched)
+		return integrity, nil
+	}
+
+	log.WithFields(fields).Info("Verified integrity of restored documents")
+
+	return integrity, nil
+}
+
+// exportDocuments uses 'cbexport' to export the documents in the given bucket (including their keys) to the given path,
+// one per line. The export is sorted, so that exports may be compared line by line.
+func (c *Cluster) exportDocuments(bucket, path string) error {
+	_, err := c.controller().ExecuteCommand(value.NewCommand(`cbexport json -c %[5]s %[4]s \
+		-b %[1]s -f lines -o %[2]s.unsorted --include-key %[3]s -t $(nproc) &&
+		LC_ALL=C sort -o %[2]s %[2]s.unsorted; STATUS=$?; rm -f %[2]s.unsorted; exit $STATUS`,
+		bucket, path, exportKey, c.cliFlags(), c.address()))
+
+	return err
+}
@@ -15,11 +15,16 @@
 package nodes
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/jamesl33/cbtools-autobench/local"
 	"github.com/jamesl33/cbtools-autobench/ssh"
 	"github.com/jamesl33/cbtools-autobench/value"
 
@@ -27,25 +32,70 @@ import (
 	"github.com/pkg/errors"
 )
 
+// awsRegion is a prefix which will be added to 'aws' commands which sets REGION to the region of the current instance
+// using the instance metadata service (IMDSv2).
+const awsRegion = `
+	TOKEN=$(curl -s -X PUT http://169.254.169.254/latest/api/token -H 'X-aws-ec2-metadata-token-ttl-seconds: 60');
+	REGION=$(curl -s -H "X-aws-ec2-metadata-token: $TOKEN" http://169.254.169.254/latest/meta-data/placement/region);
+`
+
 // Node represents a connection to a remote Couchbase Server node (note that the node may or may not be setup yet).
 type Node struct {
 	blueprint *value.NodeBlueprint
-	client    *ssh.Client
+	client    *machine
+}
+
+// Connector creates the executor which will be used to interact with the machine described by the given blueprint.
+type Connector func(config *value.SSHConfig, blueprint *value.NodeBlueprint) (Executor, error)
+
+// connector is the connector used when creating nodes, see 'SetConnector'.
+var connector Connector = connect
+
+// SetConnector replaces the connector used when creating nodes, allowing them to be driven by a different backend (for
+// example an in-process fake when running in mock mode). Must be called prior to connecting to any nodes.
+func SetConnector(c Connector) {
+	connector = c
 }
 
-// NewNode creates a connection to the remote node using the provided ssh config.
+// NewNode creates a connection to the node described by the given blueprint using the current connector.
 func NewNode(config *value.SSHConfig, blueprint *value.NodeBlueprint) (*Node, error) {
-	client, err := ssh.NewClient(blueprint.Host, config)
+	executor, err := connector(config, blueprint)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewNodeWithExecutor(executor, blueprint), nil
+}
+
+// connect is the default connector, it creates a connection to the remote node using the provided ssh config
+// (overridden by any node specific ssh config), or executes commands directly when the node is the local machine.
+func connect(config *value.SSHConfig, blueprint *value.NodeBlueprint) (Executor, error) {
+	if blueprint.Local {
+		client, err := local.NewClient(blueprint.Paths.Bin())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create local client")
+		}
+
+		return client, nil
+	}
+
+	client, err := ssh.NewClient(blueprint.Host, config.Override(blueprint.SSH), blueprint.Paths.Bin())
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create ssh client")
 	}
 
-	return &Node{blueprint: blueprint, client: client}, nil
+	return client, nil
 }
 
-// provision the node by installing the required dependencies (including Couchbase Server).
-func (n *Node) provision(path string) error {
-	err := n.installDeps()
+// NewNodeWithExecutor creates a node which will use the provided executor to interact with the underlying machine.
+func NewNodeWithExecutor(executor Executor, blueprint *value.NodeBlueprint) *Node {
+	return &Node{blueprint: blueprint, client: &machine{Executor: executor}}
+}
+
+// provision the node by installing the required dependencies (including Couchbase Server). When a dependencies path is
+// provided, the dependencies will be installed offline using the packages in that directory.
+func (n *Node) provision(path, dependencies string) error {
+	err := n.installDeps(dependencies)
 	if err != nil {
 		return errors.Wrap(err, "failed to install dependencies")
 	}
@@ -67,10 +117,58 @@ func (n *Node) provision(path string) error {
 }
 
 // installDeps installs any required platform specific dependencies which are missing on the remote machine.
-func (n *Node) installDeps() error {
+func (n *Node) installDeps(dependencies string) error {
+	if dependencies != "" {
+		return n.installOfflineDeps(dependencies)
+	}
+
 	log.WithField("host", n.blueprint.Host).Info("Installing dependencies")
 
-	return n.client.InstallPackages(n.client.Platform.Dependencies()...)
+	return n.client.InstallPackages(n.client.Platform().Dependencies()...)
+}
+
+// installOfflineDeps uploads the packages in the given local directory to the remote machine then installs them without
+// using any remote repositories, allowing provisioning machines without internet access.
+//
+// NOTE: The uploaded packages will be removed upon completion.
+func (n *Node) installOfflineDeps(dependencies string) error {
+	fields := log.Fields{"host": n.blueprint.Host, "path": dependencies}
+	log.WithFields(fields).Info("Installing dependencies offline")
+
+	packages, err := filepath.Glob(filepath.Join(dependencies, "*."+n.client.Platform().PackageExtension()))
+	if err != nil {
+		return errors.Wrap(err, "failed to list dependency packages")
+	}
+
+	if len(packages) == 0 {
+		return errors.Errorf("no '%s' packages found in '%s'", n.client.Platform().PackageExtension(), dependencies)
+	}
+
+	remoteDir := filepath.Join(os.TempDir(), "autobench-dependencies")
+
+	_, err = n.client.ExecuteCommand(value.NewCommand("rm -rf %[1]s && mkdir -p %[1]s", remoteDir))
+	if err != nil {
+		return errors.Wrap(err, "failed to create remote dependencies directory")
+	}
+
+	for _, path := range packages {
+		err = n.client.SecureUpload(path, filepath.Join(remoteDir, filepath.Base(path)))
+		if err != nil {
+			return errors.Wrapf(err, "failed to upload package '%s'", filepath.Base(path))
+		}
+	}
+
+	err = n.client.InstallPackagesFrom(remoteDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to install dependency packages")
+	}
+
+	err = n.client.RemoveDirectory(remoteDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to cleanup dependency packages")
+	}
+
+	return nil
 }
 
 // uninstallCB will uninstall Couchbase Server from the remote node ensuring a clean slate.
@@ -84,25 +182,22 @@ func (n *Node) uninstallCB() error {
 
 	log.WithField("host", n.blueprint.Host).Info("Purging install directory")
 
-	err = n.client.RemoveDirectory(value.CBInstallDirectory)
+	err = n.client.RemoveDirectory(n.blueprint.Paths.Install())
 	if err != nil {
-		return errors.Wrapf(err, "failed to cleanup install directory at '%s'", value.CBInstallDirectory)
+		return errors.Wrapf(err, "failed to cleanup install directory at '%s'", n.blueprint.Paths.Install())
 	}
 
 	return nil
 }
 
-// installCB uploads the Couchbase Server install package to the remote machine and installs it.
+// installCB uploads the Couchbase Server install package to the remote machine and installs it. The package is cached
+// on the remote machine, so re-provisioning using the same package skips the upload.
 //
-// NOTE: The package archive will be removed upon completion.
+// NOTE: When the package can't be cached, the package archive will be removed upon completion.
 func (n *Node) installCB(localPath string) error {
-	remotePath := filepath.Join(os.TempDir(), filepath.Base(localPath))
-
-	log.WithField("host", n.blueprint.Host).Info("Uploading package archive")
-
-	err := n.client.SecureUpload(localPath, remotePath)
+	remotePath, cached, err := n.uploadPackage(localPath)
 	if err != nil {
-		return errors.Wrap(err, "failed to upload package archive")
+		return err
 	}
 
 	log.WithField("host", n.blueprint.Host).Info("Installing 'couchbase-server'")
@@ -112,6 +207,10 @@ func (n *Node) installCB(localPath string) error {
 		return errors.Wrap(err, "failed to install 'couchbase-server'")
 	}
 
+	if cached {
+		return nil
+	}
+
 	log.WithField("host", n.blueprint.Host).Info("Cleaning up package archive")
 
 	err = n.client.RemoveFile(remotePath)
@@ -122,20 +221,44 @@ func (n *Node) installCB(localPath string) error {
 	return nil
 }
 
+// uploadPackage uploads the Couchbase Server install package to the remote machine, returning its remote path and
+// whether it's been cached. Packages which can't be checksummed are uploaded to a temporary location instead.
+func (n *Node) uploadPackage(localPath string) (string, bool, error) {
+	sum, sumErr := checksum(localPath)
+	if sumErr == nil {
+		remotePath, err := n.uploadCachedPackage(localPath, sum)
+		return remotePath, true, err
+	}
+
+	log.WithField("host", n.blueprint.Host).Warnf("Failed to checksum package, it will not be cached: %s", sumErr)
+
+	remotePath := filepath.Join(os.TempDir(), filepath.Base(localPath))
+
+	log.WithField("host", n.blueprint.Host).Info("Uploading package archive")
+
+	err := n.client.SecureUpload(localPath, remotePath)
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to upload package archive")
+	}
+
+	return remotePath, false, nil
+}
+
 // createDataPath ensures that the users chosen data path exists on the remote machine.
 func (n *Node) createDataPath() error {
-	if n.blueprint.DataPath == "" {
+	path := n.blueprint.DataDirectory()
+	if path == "" {
 		return nil
 	}
 
 	log.WithField("host", n.blueprint.Host).Info("Creating/configuring data path")
 
-	_, err := n.client.ExecuteCommand(value.NewCommand("mkdir -p %s", n.blueprint.DataPath))
+	_, err := n.client.ExecuteCommand(value.NewCommand("mkdir -p %s", path))
 	if err != nil {
 		return errors.Wrap(err, "failed to create remote data directory")
 	}
 
-	_, err = n.client.ExecuteCommand(value.NewCommand("chown -R couchbase:couchbase %s", n.blueprint.DataPath))
+	_, err = n.client.ExecuteCommand(value.NewCommand("chown -R couchbase:couchbase %s", path))
 	if err != nil {
 		return errors.Wrap(err, "failed to chown remote data directory")
 	}
@@ -143,17 +266,20 @@ func (n *Node) createDataPath() error {
 	return nil
 }
 
-// initializeCB will perform node level initialization of Couchbase Server.
-func (n *Node) initializeCB() error {
-	fields := log.Fields{"host": n.blueprint.Host, "data_path": n.blueprint.DataPath}
+// initializeCB will perform node level initialization of Couchbase Server, connecting to the given address using the
+// provided 'couchbase-cli' flags (e.g. credentials).
+func (n *Node) initializeCB(address, flags string) error {
+	path := n.blueprint.DataDirectory()
+
+	fields := log.Fields{"host": n.blueprint.Host, "data_path": path}
 	log.WithFields(fields).Info("Initializing node")
 
-	init := "couchbase-cli node-init -c localhost:8091 -u Administrator -p asdasd"
-	if n.blueprint.DataPath != "" {
-		init += fmt.Sprintf(" --node-init-data-path %s", n.blueprint.DataPath)
+	init := fmt.Sprintf("couchbase-cli node-init -c %s %s", address, flags)
+	if path != "" {
+		init += fmt.Sprintf(" --node-init-data-path %s", path)
 	}
 
-	_, err := n.client.ExecuteCommand(value.NewCommand(init))
+	_, err := n.client.ExecuteCommand(value.NewCommand("%s", init))
 
 	return err
 }
@@ -163,7 +289,196 @@ func (n *Node) initializeCB() error {
 func (n *Node) disableCB() error {
 	log.WithField("host", n.blueprint.Host).Info("Disabling 'couchbase-server'")
 
-	_, err := n.client.ExecuteCommand(n.client.Platform.CommandDisableCouchbase())
+	_, err := n.client.ExecuteCommand(n.client.Platform().CommandDisableCouchbase())
+
+	return err
+}
+
+// ping measures the average round trip time from the node to the given host.
+func (n *Node) ping(host string) (time.Duration, error) {
+	output, err := n.client.ExecuteCommand(value.NewCommand("ping -c 10 -i 0.2 -q %s", host))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to run 'ping'")
+	}
+
+	match := regexp.MustCompile(value.RegexPingRTT).FindStringSubmatch(string(output))
+	if match == nil {
+		return 0, errors.Errorf("failed to find round trip time in output '%s'", strings.TrimSpace(string(output)))
+	}
+
+	rtt, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to parse round trip time")
+	}
+
+	return time.Duration(rtt * float64(time.Millisecond)), nil
+}
+
+// configureVolumes validates the AWS EBS volumes backing the paths described by the given blueprints, volumes which
+// don't match their blueprint will either be modified or cause an error to be returned.
+func (n *Node) configureVolumes(blueprints []*value.VolumeBlueprint) error {
+	for _, blueprint := range blueprints {
+		volume, err := n.describeVolume(blueprint.Path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to describe volume for '%s'", blueprint.Path)
+		}
+
+		if blueprint.Matches(volume) {
+			continue
+		}
+
+		if !blueprint.Modify {
+			return errors.Errorf("volume '%s' for '%s' is a %s volume with %d IOPS and %dMiB/s throughput, expected %s "+
+				"with %d IOPS and %dMiB/s throughput", volume.ID, blueprint.Path, volume.Type, volume.IOPS,
+				volume.Throughput, blueprint.Type, blueprint.IOPS, blueprint.Throughput)
+		}
+
+		err = n.modifyVolume(volume, blueprint)
+		if err != nil {
+			return errors.Wrapf(err, "failed to modify volume '%s'", volume.ID)
+		}
+	}
+
+	return nil
+}
+
+// describeVolumes returns the characteristics of the AWS EBS volumes backing the paths described by the blueprints.
+func (n *Node) describeVolumes(blueprints []*value.VolumeBlueprint) ([]*value.Volume, error) {
+	volumes := make([]*value.Volume, 0, len(blueprints))
+
+	for _, blueprint := range blueprints {
+		volume, err := n.describeVolume(blueprint.Path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to describe volume for '%s'", blueprint.Path)
+		}
+
+		volumes = append(volumes, volume)
+	}
+
+	return volumes, nil
+}
+
+// describeHardware returns the number of CPUs, total memory and operating system of the node.
+func (n *Node) describeHardware() (*value.Hardware, error) {
+	output, err := n.client.ExecuteCommand(value.NewCommand(
+		`nproc; awk '/^MemTotal:/ { print $2 }' /proc/meminfo; (. /etc/os-release && echo "$PRETTY_NAME")`))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe hardware")
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 3 {
+		return nil, errors.Errorf("unexpected output '%s'", output)
+	}
+
+	cpus, err := strconv.ParseUint(strings.TrimSpace(lines[0]), 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse number of CPUs '%s'", lines[0])
+	}
+
+	memory, err := strconv.ParseUint(strings.TrimSpace(lines[1]), 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse total memory '%s'", lines[1])
+	}
+
+	return &value.Hardware{
+		Host:   n.blueprint.Host,
+		CPUs:   cpus,
+		Memory: memory,
+		OS:     strings.TrimSpace(lines[2]),
+	}, nil
+}
+
+// describeVolume returns the characteristics of the AWS EBS volume backing the given path, note that this relies upon
+// the 'aws' cli having permission to describe volumes (e.g. using an instance profile).
+func (n *Node) describeVolume(path string) (*value.Volume, error) {
+	id, err := n.volumeID(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to determine volume id")
+	}
+
+	output, err := n.client.ExecuteCommand(
+		value.NewCommand("%s aws ec2 describe-volumes --region $REGION --volume-ids %s --output json", awsRegion, id))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe volume")
+	}
+
+	type overlay struct {
+		Volumes []struct {
+			Type       string `json:"VolumeType"`
+			Size       uint64 `json:"Size"`
+			IOPS       uint64 `json:"Iops"`
+			Throughput uint64 `json:"Throughput"`
+		} `json:"Volumes"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(output, &decoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode volume description")
+	}
+
+	if len(decoded.Volumes) == 0 {
+		return nil, errors.Errorf("volume '%s' not found", id)
+	}
+
+	return &value.Volume{
+		Host:       n.blueprint.Host,
+		Path:       path,
+		ID:         id,
+		Type:       decoded.Volumes[0].Type,
+		Size:       decoded.Volumes[0].Size,
+		IOPS:       decoded.Volumes[0].IOPS,
+		Throughput: decoded.Volumes[0].Throughput,
+	}, nil
+}
+
+// volumeID returns the id of the AWS EBS volume backing the given path. Nitro instances expose EBS volumes as NVMe
+// devices whose serial number is the volume id without the hyphen e.g. 'vol0123456789abcdef0'.
+func (n *Node) volumeID(path string) (string, error) {
+	output, err := n.client.ExecuteCommand(value.NewCommand(`DEVICE=$(findmnt -n -o SOURCE --target %s);
+		PARENT=$(lsblk -n -d -o PKNAME $DEVICE); lsblk -n -d -o SERIAL /dev/${PARENT:-${DEVICE#/dev/}}`, path))
+	if err != nil {
+		return "", err
+	}
+
+	serial := strings.TrimSpace(string(output))
+	if !strings.HasPrefix(serial, "vol") {
+		return "", errors.Errorf("path is not backed by an EBS volume, device has serial '%s'", serial)
+	}
+
+	return "vol-" + strings.TrimPrefix(strings.TrimPrefix(serial, "vol"), "-"), nil
+}
+
+// modifyVolume modifies the given AWS EBS volume to match the blueprint, note that the modification is applied
+// asynchronously by AWS and the volume may perform inconsistently until it has completed.
+func (n *Node) modifyVolume(volume *value.Volume, blueprint *value.VolumeBlueprint) error {
+	fields := log.Fields{
+		"host":       n.blueprint.Host,
+		"id":         volume.ID,
+		"type":       blueprint.Type,
+		"iops":       blueprint.IOPS,
+		"throughput": blueprint.Throughput,
+	}
+
+	log.WithFields(fields).Info("Modifying volume")
+
+	command := fmt.Sprintf("%s aws ec2 modify-volume --region $REGION --volume-id %s", awsRegion, volume.ID)
+
+	if blueprint.Type != "" {
+		command += fmt.Sprintf(" --volume-type %s", blueprint.Type)
+	}
+
+	if blueprint.IOPS != 0 {
+		command += fmt.Sprintf(" --iops %d", blueprint.IOPS)
+	}
+
+	if blueprint.Throughput != 0 {
+		command += fmt.Sprintf(" --throughput %d", blueprint.Throughput)
+	}
+
+	_, err := n.client.ExecuteCommand(value.NewCommand("%s", command))
 
 	return err
 }
@@ -0,0 +1,143 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+)
+
+// packageCacheDirectory is the directory on each machine where uploaded packages are cached, each package is stored in
+// a sub-directory named after its checksum so that packages with the same name (e.g. rebuilt packages) don't collide.
+const packageCacheDirectory = "/var/cache/autobench/packages"
+
+// packageCacheSize is the maximum number of packages cached on each machine, the least recently used packages are
+// evicted once it's exceeded.
+const packageCacheSize = 3
+
+// checksumKey identifies a version of a local package, a package which is replaced at the same path will have a
+// different size/modification time so won't reuse the memoized checksum.
+type checksumKey struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// checksums memoizes the checksum of each local package, since the same package is uploaded to every node.
+var checksums = struct {
+	sync.Mutex
+	keys map[checksumKey]string
+}{keys: make(map[checksumKey]string)}
+
+// checksum returns the hex encoded SHA256 checksum of the local file at the given path.
+//
+// NOTE: The lock is held whilst hashing, so that nodes provisioned concurrently only hash the package once.
+func checksum(path string) (string, error) {
+	checksums.Lock()
+	defer checksums.Unlock()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open file")
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to stat file")
+	}
+
+	key := checksumKey{path: path, size: stat.Size(), modTime: stat.ModTime()}
+
+	if sum, ok := checksums.keys[key]; ok {
+		return sum, nil
+	}
+
+	hash := sha256.New()
+
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read file")
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	checksums.keys[key] = sum
+
+	return sum, nil
+}
+
+// uploadCachedPackage uploads the local package at the given path to the package cache on the machine, returning its
+// remote path. The upload is skipped when the cache already contains a package with the same checksum.
+func (n *Node) uploadCachedPackage(localPath, sum string) (string, error) {
+	var (
+		remoteDir  = filepath.Join(packageCacheDirectory, sum)
+		remotePath = filepath.Join(remoteDir, filepath.Base(localPath))
+		fields     = log.Fields{"host": n.blueprint.Host, "checksum": sum}
+	)
+
+	if n.client.FileExists(remotePath) {
+		log.WithFields(fields).Info("Using cached package archive")
+		n.evictCachedPackages(remoteDir)
+
+		return remotePath, nil
+	}
+
+	log.WithFields(fields).Info("Uploading package archive to cache")
+
+	_, err := n.client.ExecuteCommand(value.NewCommand("mkdir -p %s", remoteDir))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create remote cache directory")
+	}
+
+	// Packages are uploaded under a temporary name then renamed, so an interrupted upload is never treated as cached
+	partial := remotePath + ".partial"
+
+	err = n.client.SecureUpload(localPath, partial)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to upload package archive")
+	}
+
+	_, err = n.client.ExecuteCommand(value.NewCommand("mv %s %s", partial, remotePath))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to move package archive into cache")
+	}
+
+	n.evictCachedPackages(remoteDir)
+
+	return remotePath, nil
+}
+
+// evictCachedPackages marks the given cache directory as recently used, then removes the least recently used packages
+// from the cache leaving at most 'packageCacheSize' packages.
+//
+// NOTE: Failing to evict packages isn't fatal, it only means the cache uses more space than expected.
+func (n *Node) evictCachedPackages(used string) {
+	_, err := n.client.ExecuteCommand(value.NewCommand(
+		"touch %s && cd %s && ls -1t | tail -n +%d | xargs -r rm -rf", used, packageCacheDirectory, packageCacheSize+1))
+	if err != nil {
+		log.Warnf("Failed to evict cached packages on '%s': %s", n.blueprint.Host, err)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+)
+
+// restRequest describes a request to the cluster manager (ns_server) REST API.
+type restRequest struct {
+	// Method is the HTTP method of the request, defaults to GET.
+	Method string
+
+	// Endpoint is the path (including any query) of the request e.g. '/pools/default/tasks'.
+	Endpoint string
+
+	// Body is the form encoded body of the request, if any.
+	Body string
+}
+
+// method returns the HTTP method of the request.
+func (r *restRequest) method() string {
+	if r.Method == "" {
+		return http.MethodGet
+	}
+
+	return r.Method
+}
+
+// idempotent returns a boolean indicating whether the request may safely be sent more than once e.g. a POST which
+// timed out may have already been applied by the cluster.
+func (r *restRequest) idempotent() bool {
+	switch r.method() {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// restClient is a client for the cluster manager REST API. Connections are dialed using the given function, allowing
+// requests to be made from the machine running the cluster commands (e.g. tunnelled over ssh) so that the REST API
+// needn't be reachable from the machine running autobench.
+type restClient struct {
+	base        string
+	credentials *value.Credentials
+	client      *http.Client
+	retries     int
+}
+
+// newRESTClient creates a new REST client which will make requests to the given base URL, dialing connections using
+// the provided function.
+func newRESTClient(base string, config *value.RESTConfig, credentials *value.Credentials,
+	dial func(network, address string) (net.Conn, error),
+) *restClient {
+	transport := &http.Transport{
+		DialContext: func(_ context.Context, network, address string) (net.Conn, error) {
+			return dial(network, address)
+		},
+		// The cluster will generally be using a self-signed certificate
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+
+	return &restClient{
+		base:        base,
+		credentials: credentials,
+		client:      &http.Client{Transport: transport, Timeout: config.GetTimeout()},
+		retries:     config.GetRetries(),
+	}
+}
+
+// Execute the given request returning the body of the response, idempotent requests which fail due to a network/server
+// error are retried with a linear backoff.
+func (r *restClient) Execute(request *restRequest) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		body, status, err := r.execute(request)
+		if err == nil {
+			return body, nil
+		}
+
+		// A previous attempt may have deleted the resource before failing e.g. the response was lost
+		if attempt > 1 && request.method() == http.MethodDelete && status == http.StatusNotFound {
+			return nil, nil
+		}
+
+		retryable := status == 0 || status >= http.StatusInternalServerError
+		if !retryable || !request.idempotent() || attempt > r.retries {
+			return nil, err
+		}
+
+		fields := log.Fields{"method": request.method(), "endpoint": request.Endpoint, "attempt": attempt}
+		log.WithFields(fields).Warnf("REST request failed, retrying: %s", err)
+
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// execute performs a single attempt at the given request, returning the status code of the response which will be zero
+// if no response was received.
+func (r *restClient) execute(request *restRequest) ([]byte, int, error) {
+	req, err := http.NewRequest(request.method(), r.base+request.Endpoint, strings.NewReader(request.Body))
+	if err != nil {
+		return nil, -1, errors.Wrap(err, "failed to create request")
+	}
+
+	req.SetBasicAuth(r.credentials.GetUsername(), r.credentials.GetPassword())
+
+	if request.Body != "" {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to send request")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to read response body")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, resp.StatusCode, errors.Errorf("unexpected status code %d for '%s %s': %s",
+			resp.StatusCode, request.method(), request.Endpoint, bytes.TrimSpace(body))
+	}
+
+	return body, resp.StatusCode, nil
+}
+
+// Close releases any idle connections, this should be done before the underlying connection to the machine is closed.
+func (r *restClient) Close() {
+	r.client.CloseIdleConnections()
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+)
+
+// throttle limits the throughput of the data service using the given config, so that the cluster (rather than the
+// backup client) is the bottleneck. Returns a function which restores the settings in place beforehand.
+func (c *Cluster) throttle(config *value.ThrottleConfig) (func(), error) {
+	if config == nil || config.WriterThreads == 0 {
+		return func() {}, nil
+	}
+
+	original, err := c.writerThreads()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get writer threads")
+	}
+
+	log.WithFields(log.Fields{"writer_threads": config.WriterThreads, "original": original}).Info("Throttling cluster")
+
+	err = c.setWriterThreads(strconv.Itoa(config.WriterThreads))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set writer threads")
+	}
+
+	return func() {
+		log.WithField("writer_threads", original).Info("Removing cluster throttling")
+
+		if err := c.setWriterThreads(original); err != nil {
+			log.Warnf("Failed to restore writer threads: %s", err)
+		}
+	}, nil
+}
+
+// writerThreads returns the number of KV writer threads used by every node in the cluster, either a number of threads
+// or one of the values accepted by Couchbase Server e.g. 'default'.
+func (c *Cluster) writerThreads() (string, error) {
+	output, err := c.rest.Execute(&restRequest{Endpoint: "/pools/default/settings/memcached/global"})
+	if err != nil {
+		return "", err
+	}
+
+	var decoded map[string]json.RawMessage
+
+	err = json.Unmarshal(output, &decoded)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal response")
+	}
+
+	raw, ok := decoded["num_writer_threads"]
+	if !ok {
+		return "default", nil
+	}
+
+	// The setting is either a string (e.g. 'default'/'disk_io_optimized') or a number of threads
+	var threads string
+	if json.Unmarshal(raw, &threads) == nil {
+		return threads, nil
+	}
+
+	return string(raw), nil
+}
+
+// setWriterThreads sets the number of KV writer threads used by every node in the cluster, the given value may either
+// be a number of threads or one of the values accepted by Couchbase Server e.g. 'default'.
+func (c *Cluster) setWriterThreads(threads string) error {
+	_, err := c.rest.Execute(&restRequest{
+		Method:   http.MethodPost,
+		Endpoint: "/pools/default/settings/memcached/global",
+		Body:     url.Values{"num_writer_threads": {threads}}.Encode(),
+	})
+
+	return err
+}
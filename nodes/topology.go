@@ -0,0 +1,246 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+)
+
+// rebalanceTimeout is how long we'll wait for a rebalance to complete when changing the cluster topology.
+const rebalanceTimeout = 24 * time.Hour
+
+// rebalanceTask is the rebalance task returned by the '/pools/default/tasks' endpoint.
+type rebalanceTask struct {
+	Type         string  `json:"type"`
+	Status       string  `json:"status"`
+	Progress     float64 `json:"progress"`
+	ErrorMessage string  `json:"errorMessage"`
+
+	// LastReportURI identifies the report of the last rebalance, which changes once a rebalance has completed.
+	LastReportURI string `json:"lastReportURI"`
+}
+
+// ChangeTopology provisions and adds the given nodes into the cluster, removes the given hosts, then rebalances the
+// cluster polling its progress until complete. The dataset is redistributed rather than being reloaded, so benchmarks
+// may be run against the new topology without reprovisioning.
+func (c *Cluster) ChangeTopology(config *value.SSHConfig, change *value.TopologyChange) error {
+	if !c.blueprint.IsManaged() {
+		return errUnmanaged
+	}
+
+	err := c.validateTopologyChange(change)
+	if err != nil {
+		return errors.Wrap(err, "invalid topology change")
+	}
+
+	added := make([]*Node, 0, len(change.Add))
+
+	for _, blueprint := range change.Add {
+		blueprint.Paths = c.blueprint.CBPaths
+
+		node, err := NewNode(config, blueprint)
+		if err != nil {
+			return errors.Wrapf(err, "failed to connect to node '%s'", blueprint.Host)
+		}
+
+		added = append(added, node)
+	}
+
+	err = c.forNodes(added, c.provisionNode)
+	if err != nil {
+		return errors.Wrap(err, "failed to provision nodes")
+	}
+
+	for _, node := range added {
+		err = c.serverAdd(node)
+		if err != nil {
+			return errors.Wrapf(err, "failed to add node '%s'", node.blueprint.Host)
+		}
+	}
+
+	err = c.configureAddedCertificates(added)
+	if err != nil {
+		return errors.Wrap(err, "failed to configure certificates")
+	}
+
+	err = c.rebalanceWithProgress(change.Remove)
+	if err != nil {
+		return errors.Wrap(err, "failed to rebalance cluster")
+	}
+
+	var removed []*Node
+
+	c.nodes = slices.DeleteFunc(c.nodes, func(node *Node) bool {
+		if slices.Contains(change.Remove, node.blueprint.Host) {
+			removed = append(removed, node)
+			return true
+		}
+
+		return false
+	})
+
+	c.nodes = append(c.nodes, added...)
+
+	for _, node := range removed {
+		_ = node.Close()
+	}
+
+	return nil
+}
+
+// validateTopologyChange returns an error if the given change can't be applied to the cluster.
+func (c *Cluster) validateTopologyChange(change *value.TopologyChange) error {
+	hosts := c.hosts()
+
+	for _, host := range change.Remove {
+		if !slices.Contains(hosts, host) {
+			return errors.Errorf("can't remove '%s' which is not in the cluster", host)
+		}
+
+		if host == c.nodes[0].blueprint.Host {
+			return errors.Errorf("can't remove '%s' which is used to orchestrate the cluster", host)
+		}
+	}
+
+	if len(change.Remove) >= len(c.nodes)+len(change.Add) {
+		return errors.New("can't remove every node from the cluster")
+	}
+
+	for _, blueprint := range change.Add {
+		if slices.Contains(hosts, blueprint.Host) {
+			return errors.Errorf("can't add '%s' which is already in the cluster", blueprint.Host)
+		}
+	}
+
+	// The generated CA is discarded once the cluster is provisioned, so there's nothing to sign the new certificates
+	if len(change.Add) != 0 && c.blueprint.TLS != nil && c.blueprint.TLS.GeneratedCA() {
+		return errors.New("can't add nodes to a cluster using a generated CA, configure 'ca_cert_path'/'ca_key_path'")
+	}
+
+	remaining := slices.Clone(change.Add)
+
+	for _, node := range c.nodes {
+		if !slices.Contains(change.Remove, node.blueprint.Host) {
+			remaining = append(remaining, node.blueprint)
+		}
+	}
+
+	return value.ValidateServices(remaining)
+}
+
+// rebalanceWithProgress starts a rebalance which removes the given hosts from the cluster, then polls the rebalance
+// task until it completes, logging its progress.
+//
+// NOTE: The task may not have been updated by the time it's first polled, so the rebalance is only considered complete
+// once it's been seen running or the report of the last rebalance has changed (i.e. it completed between polls).
+func (c *Cluster) rebalanceWithProgress(remove []string) error {
+	log.WithField("remove", remove).Info("Rebalancing cluster")
+
+	previous, err := c.rebalanceTask()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rebalance status")
+	}
+
+	command := "couchbase-cli rebalance -c " + c.address() + " " + c.cliFlags() + " --no-wait"
+	if len(remove) != 0 {
+		command += fmt.Sprintf(" --server-remove %s", strings.Join(remove, ","))
+	}
+
+	_, err = c.controller().ExecuteCommand(value.NewCommand("%s", command))
+	if err != nil {
+		return errors.Wrap(err, "failed to start rebalance")
+	}
+
+	progress := func() (uint64, uint64, error) {
+		task, err := c.rebalanceTask()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return uint64(math.Round(task.Progress)), 100, nil
+	}
+
+	stop := heartbeat("Rebalancing cluster", progress, formatCount)
+	defer stop()
+
+	var (
+		task    *rebalanceTask
+		started bool
+	)
+
+	timeout, err := poll(func() (bool, error) {
+		var err error
+
+		task, err = c.rebalanceTask()
+		if err != nil {
+			return false, err
+		}
+
+		if task.Status == "running" {
+			started = true
+			return false, nil
+		}
+
+		return started || task.LastReportURI != previous.LastReportURI, nil
+	}, rebalanceTimeout)
+	if err != nil {
+		return errors.Wrap(err, "failed to get rebalance status")
+	}
+
+	if timeout {
+		return errors.Errorf("rebalance did not complete within %s", rebalanceTimeout)
+	}
+
+	if task.ErrorMessage != "" {
+		return errors.Errorf("rebalance failed: %s", task.ErrorMessage)
+	}
+
+	log.Info("Rebalance complete")
+
+	return nil
+}
+
+// rebalanceTask returns the current state of the rebalance task.
+func (c *Cluster) rebalanceTask() (*rebalanceTask, error) {
+	output, err := c.rest.Execute(&restRequest{Endpoint: "/pools/default/tasks"})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute request")
+	}
+
+	var tasks []*rebalanceTask
+
+	err = json.Unmarshal(output, &tasks)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal response")
+	}
+
+	for _, task := range tasks {
+		if task.Type == "rebalance" {
+			return task, nil
+		}
+	}
+
+	return nil, errors.New("rebalance task not found")
+}
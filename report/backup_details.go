@@ -0,0 +1,43 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import "github.com/jamesl33/cbtools-autobench/value"
+
+// backupDetailsResult encapsulates the structured details of the backup(s) created by a single benchmark iteration.
+type backupDetailsResult struct {
+	Iteration int                    `json:"iteration"`
+	Backups   []*value.BackupDetails `json:"backups"`
+}
+
+// BackupDetails is a component which exposes the structured details of the backups created by each iteration (shard and
+// file counts, per-bucket breakdowns etc.) for downstream analysis; it's only included in the JSON report.
+type BackupDetails []*backupDetailsResult
+
+// NewBackupDetails creates a new 'BackupDetails' component with the provided options, returns nil if no backups were
+// created by the benchmarks.
+func NewBackupDetails(options Options) BackupDetails {
+	var results []*backupDetailsResult
+
+	for iteration, result := range options.Results {
+		if len(result.Backups) == 0 {
+			continue
+		}
+
+		results = append(results, &backupDetailsResult{Iteration: iteration + 1, Backups: result.Backups})
+	}
+
+	return results
+}
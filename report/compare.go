@@ -0,0 +1,101 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+// ReportComparison compares two previously generated JSON reports side-by-side, flagging any differences between the
+// environments they were run in so that apples-to-oranges comparisons are caught.
+type ReportComparison struct {
+	Environment EnvironmentDiff `json:"environment_differences,omitempty"`
+	Baseline    *Overview       `json:"baseline,omitempty"`
+	Candidate   *Overview       `json:"candidate,omitempty"`
+}
+
+// NewReportComparison creates a new 'ReportComparison' from the given JSON reports.
+func NewReportComparison(baseline, candidate []byte) (*ReportComparison, error) {
+	var (
+		decoded   = make([]map[string]any, 2)
+		overviews = make([]*Overview, 2)
+	)
+
+	for idx, data := range [][]byte{baseline, candidate} {
+		err := json.Unmarshal(data, &decoded[idx])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode report")
+		}
+
+		var overlay struct {
+			Overview *Overview `json:"overview"`
+		}
+
+		err = json.Unmarshal(data, &overlay)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode report overview")
+		}
+
+		overviews[idx] = overlay.Overview
+	}
+
+	return &ReportComparison{
+		Environment: NewEnvironmentDiff(decoded[0], decoded[1]),
+		Baseline:    overviews[0],
+		Candidate:   overviews[1],
+	}, nil
+}
+
+// Comparable returns a boolean indicating whether the reports were generated using identical environments.
+func (r *ReportComparison) Comparable() bool {
+	return len(r.Environment) == 0
+}
+
+// String returns a string representation of the comparison, the environment differences are output first so that they
+// aren't missed.
+func (r *ReportComparison) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintf(buffer, "%s\n\n", r.Environment)
+
+	fmt.Fprintln(buffer, "| Results\n| -------")
+	fmt.Fprintf(writer, "| Report\t Avg Duration\t Avg Size (ADS)\t Avg Transfer Rate (ADS)\t\n")
+
+	for _, report := range []struct {
+		name     string
+		overview *Overview
+	}{{"baseline", r.Baseline}, {"candidate", r.Candidate}} {
+		if report.overview == nil {
+			fmt.Fprintf(writer, "| %s\t N/A\t N/A\t N/A\t\n", report.name)
+			continue
+		}
+
+		fmt.Fprintf(writer, "| %s\t %s\t %s\t %s/s\t\n", report.name, report.overview.AvgDuration,
+			report.overview.AvgADS, report.overview.AvgTransferRateADS)
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
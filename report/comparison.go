@@ -0,0 +1,144 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/couchbase/tools-common/strings/format"
+)
+
+// comparisonResult encapsulates the averages for a single variant, along with the difference to the baseline variant.
+type comparisonResult struct {
+	Variant                string `json:"variant"`
+	Iterations             int    `json:"iterations"`
+	AvgDuration            string `json:"avg_duration,omitempty"`
+	DurationDelta          string `json:"duration_delta,omitempty"`
+	DurationDiff           string `json:"duration_diff,omitempty"`
+	AvgTransferRateADS     string `json:"avg_transfer_rate_ads,omitempty"`
+	AvgTransferRateADSDiff string `json:"avg_transfer_rate_ads_diff,omitempty"`
+	AvgADS                 string `json:"avg_ads,omitempty"`
+	AvgADSDiff             string `json:"avg_ads_diff,omitempty"`
+}
+
+// Comparison is a component which compares the results of multiple variants of a benchmark side-by-side, the first
+// variant is used as the baseline.
+type Comparison []*comparisonResult
+
+// NewComparison creates a new 'Comparison' component with the provided options, returns nil if multiple variants
+// weren't benchmarked.
+func NewComparison(options Options) Comparison {
+	variants := options.Results.Variants()
+	if len(variants) < 2 {
+		return nil
+	}
+
+	var (
+		baseline = options.Results.Variant(variants[0])
+		results  = make([]*comparisonResult, 0, len(variants))
+	)
+
+	for idx, variant := range variants {
+		variantResults := options.Results.Variant(variant)
+
+		result := &comparisonResult{
+			Variant:            variant,
+			Iterations:         len(variantResults),
+			AvgDuration:        format.Duration(variantResults.AvgDuration()),
+			AvgTransferRateADS: format.Bytes(variantResults.AvgTransferRateADS()),
+			AvgADS:             format.Bytes(variantResults.AvgADS()),
+		}
+
+		if idx != 0 {
+			result.DurationDelta = durationDelta(baseline.AvgDuration(), variantResults.AvgDuration())
+
+			result.DurationDiff = percentageDiff(
+				float64(baseline.AvgDuration()),
+				float64(variantResults.AvgDuration()),
+			)
+
+			result.AvgTransferRateADSDiff = percentageDiff(
+				float64(baseline.AvgTransferRateADS()),
+				float64(variantResults.AvgTransferRateADS()),
+			)
+
+			result.AvgADSDiff = percentageDiff(float64(baseline.AvgADS()), float64(variantResults.AvgADS()))
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// String returns a string representation of the 'Comparison' component which will be output in the report.
+func (c Comparison) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Comparison\n| ----------")
+	fmt.Fprintf(writer, "| Variant\t Iterations\t Avg Duration\t Delta\t Diff\t Avg Transfer Rate (ADS)\t Diff\t "+
+		"Avg ADS\t Diff\t\n")
+
+	for _, result := range c {
+		fmt.Fprintf(writer, "| %s\t %d\t %s\t %s\t %s\t %s/s\t %s\t %s\t %s\t\n",
+			result.Variant,
+			result.Iterations,
+			result.AvgDuration,
+			orBaseline(result.DurationDelta),
+			orBaseline(result.DurationDiff),
+			result.AvgTransferRateADS,
+			orBaseline(result.AvgTransferRateADSDiff),
+			result.AvgADS,
+			orBaseline(result.AvgADSDiff))
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
+
+// percentageDiff returns a formatted percentage difference of the given value relative to the baseline.
+func percentageDiff(baseline, value float64) string {
+	if baseline == 0 {
+		return "N/A"
+	}
+
+	return fmt.Sprintf("%+.2f%%", (value-baseline)/baseline*100)
+}
+
+// durationDelta returns the formatted absolute difference of the given duration relative to the baseline.
+func durationDelta(baseline, value time.Duration) string {
+	if value < baseline {
+		return "-" + format.Duration(baseline-value)
+	}
+
+	return "+" + format.Duration(value-baseline)
+}
+
+// orBaseline returns the given difference, or a placeholder for the baseline which has no difference.
+func orBaseline(diff string) string {
+	if diff == "" {
+		return "baseline"
+	}
+
+	return diff
+}
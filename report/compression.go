@@ -0,0 +1,116 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/couchbase/tools-common/strings/format"
+)
+
+// compressionResult encapsulates the sizes of the data for a single benchmark iteration, along with the ratios between
+// them.
+type compressionResult struct {
+	Iteration int    `json:"iteration"`
+	GDS       string `json:"gds"`
+	ADS       string `json:"ads"`
+	DiskSize  string `json:"disk_size,omitempty"`
+	GDSToADS  string `json:"gds_to_ads"`
+	ADSToDisk string `json:"ads_to_disk,omitempty"`
+	GDSToDisk string `json:"gds_to_disk,omitempty"`
+}
+
+// Compression is a component which displays the ratios between the generated data size, the actual data size and the
+// size of the backup on disk for each iteration, making the effectiveness of compression/deduplication visible.
+type Compression []*compressionResult
+
+// NewCompression creates a new 'Compression' component with the provided options.
+func NewCompression(options Options) Compression {
+	if len(options.Results) == 0 {
+		return nil
+	}
+
+	var (
+		gds     = uint64(options.Blueprint.Cluster.Bucket.Data.Items * options.Blueprint.Cluster.Bucket.Data.Size)
+		results = make([]*compressionResult, 0, len(options.Results))
+	)
+
+	for iteration, result := range options.Results {
+		compression := &compressionResult{
+			Iteration: iteration + 1,
+			GDS:       format.Bytes(gds),
+			ADS:       format.Bytes(result.ADS),
+			GDSToADS:  ratio(gds, result.ADS),
+		}
+
+		if result.DiskSize != 0 {
+			compression.DiskSize = format.Bytes(result.DiskSize)
+			compression.ADSToDisk = ratio(result.ADS, result.DiskSize)
+			compression.GDSToDisk = ratio(gds, result.DiskSize)
+		}
+
+		results = append(results, compression)
+	}
+
+	return results
+}
+
+// String returns a string representation of the 'Compression' component which will be output in the report.
+func (c Compression) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Compression\n| -----------")
+	fmt.Fprintf(writer, "| Iteration\t GDS\t ADS\t On Disk\t GDS:ADS\t ADS:Disk\t GDS:Disk\t\n")
+
+	for _, result := range c {
+		fmt.Fprintf(writer, "| %d\t %s\t %s\t %s\t %s\t %s\t %s\t\n",
+			result.Iteration,
+			result.GDS,
+			result.ADS,
+			orNA(result.DiskSize),
+			result.GDSToADS,
+			orNA(result.ADSToDisk),
+			orNA(result.GDSToDisk))
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
+
+// ratio returns the formatted ratio between the given sizes e.g. a ratio of '2.50x' indicates that the first size is
+// two and a half times larger than the second.
+func ratio(a, b uint64) string {
+	if b == 0 {
+		return "N/A"
+	}
+
+	return fmt.Sprintf("%.2fx", float64(a)/float64(b))
+}
+
+// orNA returns the given value, or "N/A" when it's empty.
+func orNA(value string) string {
+	if value == "" {
+		return "N/A"
+	}
+
+	return value
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+)
+
+// Config is a component which contains the canonicalised config (with secrets redacted) used to run the benchmarks,
+// along with its hash. This allows results to be reproduced, and ensures results with differing configs aren't
+// accidentally compared.
+type Config struct {
+	Hash   string `json:"sha256"`
+	Config string `json:"config"`
+}
+
+// NewConfig creates a new 'Config' component with the provided options, returns nil if no config was provided.
+func NewConfig(options Options) *Config {
+	if options.Config == nil {
+		return nil
+	}
+
+	config, hash, err := options.Config.Canonical()
+	if err != nil {
+		// The config is purely informational, failing to encode it shouldn't cause us to lose the benchmark results
+		return nil
+	}
+
+	return &Config{Hash: hash, Config: config}
+}
+
+// String returns a string representation of the 'Config' component which will be output in the report, only the hash
+// is included; the full config is available in the JSON report.
+func (c *Config) String() string {
+	return fmt.Sprintf("| Config\n| ------\n| SHA-256: %s", c.Hash)
+}
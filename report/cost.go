@@ -0,0 +1,107 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/couchbase/tools-common/strings/format"
+)
+
+// costResult encapsulates the estimated object store costs for a single benchmark iteration.
+type costResult struct {
+	Iteration    int    `json:"iteration"`
+	PutRequests  uint64 `json:"put_requests"`
+	GetRequests  uint64 `json:"get_requests"`
+	ListRequests uint64 `json:"list_requests"`
+	Stored       string `json:"stored,omitempty"`
+	RequestCost  string `json:"request_cost"`
+	StorageCost  string `json:"storage_cost_per_month,omitempty"`
+}
+
+// Cost is a component which displays the estimated object store request/storage costs for each iteration, only
+// populated when benchmarking using an object store with pricing configured.
+//
+// NOTE: Unless requests are being counted, the number of requests is estimated using the number of objects
+// written/read, therefore, it doesn't include multipart uploads, listing or other metadata requests.
+type Cost []*costResult
+
+// NewCost creates a new 'Cost' component with the provided options.
+func NewCost(options Options) Cost {
+	if options.Config == nil || options.Config.BenchmarkConfig.CloudPricing == nil {
+		return nil
+	}
+
+	var (
+		pricing = options.Config.BenchmarkConfig.CloudPricing
+		results = make([]*costResult, 0, len(options.Results))
+	)
+
+	for iteration, result := range options.Results {
+		if result.PutRequests == 0 && result.GetRequests == 0 && result.ListRequests == 0 {
+			continue
+		}
+
+		cost := &costResult{
+			Iteration:    iteration + 1,
+			PutRequests:  result.PutRequests,
+			GetRequests:  result.GetRequests,
+			ListRequests: result.ListRequests,
+			RequestCost:  fmt.Sprintf("%.4f", pricing.RequestCost(result)),
+		}
+
+		if result.DiskSize != 0 {
+			cost.Stored = format.Bytes(result.DiskSize)
+			cost.StorageCost = fmt.Sprintf("%.4f", pricing.StorageCost(result))
+		}
+
+		results = append(results, cost)
+	}
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	return results
+}
+
+// String returns a string representation of the 'Cost' component which will be output in the report.
+func (c Cost) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Estimated Cost\n| --------------")
+	fmt.Fprintf(writer, "| Iteration\t PUTs\t GETs\t LISTs\t Stored\t Request Cost\t Storage Cost (Monthly)\t\n")
+
+	for _, result := range c {
+		fmt.Fprintf(writer, "| %d\t %d\t %d\t %d\t %s\t %s\t %s\t\n",
+			result.Iteration,
+			result.PutRequests,
+			result.GetRequests,
+			result.ListRequests,
+			orNA(result.Stored),
+			result.RequestCost,
+			orNA(result.StorageCost))
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/couchbase/tools-common/strings/format"
+)
+
+// dcpResult encapsulates the summary of the DCP stats sampled during a single backup iteration.
+type dcpResult struct {
+	Iteration         int    `json:"iteration"`
+	Samples           int    `json:"samples"`
+	AvgItemsRemaining uint64 `json:"avg_items_remaining"`
+	MaxItemsRemaining uint64 `json:"max_items_remaining"`
+	AvgBackfillBytes  string `json:"avg_backfill_bytes,omitempty"`
+	MaxBackfillBytes  string `json:"max_backfill_bytes,omitempty"`
+
+	MaxConnections          uint64 `json:"max_connections"`
+	MaxStreams              uint64 `json:"max_streams"`
+	MaxMemcachedConnections uint64 `json:"max_memcached_connections"`
+}
+
+// DCP is a component which summarizes the DCP stats for the backup connection(s) sampled during each backup iteration,
+// this helps to distinguish cluster-side streaming limits from client-side bottlenecks. The peak connection/stream
+// counts help to identify changes in stream fan-out.
+type DCP []*dcpResult
+
+// NewDCP creates a new 'DCP' component with the provided options, returns nil if the DCP stats weren't sampled.
+func NewDCP(options Options) DCP {
+	var results []*dcpResult
+
+	for iteration, result := range options.Results {
+		if result.DCP == nil {
+			continue
+		}
+
+		results = append(results, &dcpResult{
+			Iteration:         iteration + 1,
+			Samples:           result.DCP.Samples,
+			AvgItemsRemaining: result.DCP.AvgItemsRemaining(),
+			MaxItemsRemaining: result.DCP.MaxItemsRemaining,
+			AvgBackfillBytes:  format.Bytes(result.DCP.AvgBackfillBytes()),
+			MaxBackfillBytes:  format.Bytes(result.DCP.MaxBackfillBytes),
+
+			MaxConnections:          result.DCP.MaxConnections,
+			MaxStreams:              result.DCP.MaxStreams,
+			MaxMemcachedConnections: result.DCP.MaxMemcachedConnections,
+		})
+	}
+
+	return results
+}
+
+// String returns a string representation of the 'DCP' component which will be output in the report.
+func (d DCP) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| DCP\n| ---")
+	fmt.Fprintf(writer, "| Iteration\t Samples\t Avg Items Remaining\t Max Items Remaining\t Avg Backfill Size\t "+
+		"Max Backfill Size\t Max Connections\t Max Streams\t Max Memcached Connections\t\n")
+
+	for _, result := range d {
+		fmt.Fprintf(writer, "| %d\t %d\t %d\t %d\t %s\t %s\t %d\t %d\t %d\t\n",
+			result.Iteration,
+			result.Samples,
+			result.AvgItemsRemaining,
+			result.MaxItemsRemaining,
+			result.AvgBackfillBytes,
+			result.MaxBackfillBytes,
+			result.MaxConnections,
+			result.MaxStreams,
+			result.MaxMemcachedConnections)
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
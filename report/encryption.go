@@ -0,0 +1,90 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"strings"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+)
+
+// encryptionPrefix is the prefix of the part of a variant name which describes its encryption algorithm.
+const encryptionPrefix = "encryption="
+
+// encryptionOverhead is the overhead introduced by encryption, relative to an otherwise identical unencrypted variant.
+type encryptionOverhead struct {
+	Variant  string `json:"variant"`
+	Baseline string `json:"baseline"`
+	Duration string `json:"duration"`
+	CPU      string `json:"cpu"`
+}
+
+// newEncryptionOverheads returns the overhead of each encrypted variant, relative to the unencrypted variant which only
+// differs by its encryption algorithm. Returns nil if an encrypted/unencrypted pair wasn't benchmarked.
+func newEncryptionOverheads(results value.BenchmarkResults) []*encryptionOverhead {
+	var (
+		variants  = results.Variants()
+		baselines = make(map[string]string)
+	)
+
+	for _, variant := range variants {
+		if group, algo, ok := encryptionGroup(variant); ok && algo == value.EncryptionNone {
+			baselines[group] = variant
+		}
+	}
+
+	var overheads []*encryptionOverhead
+
+	for _, variant := range variants {
+		group, algo, ok := encryptionGroup(variant)
+		if !ok || algo == value.EncryptionNone {
+			continue
+		}
+
+		baseline, ok := baselines[group]
+		if !ok {
+			continue
+		}
+
+		var (
+			plain     = results.Variant(baseline)
+			encrypted = results.Variant(variant)
+		)
+
+		overheads = append(overheads, &encryptionOverhead{
+			Variant:  variant,
+			Baseline: baseline,
+			Duration: percentageDiff(float64(plain.AvgDuration()), float64(encrypted.AvgDuration())),
+			CPU:      percentageDiff(float64(plain.AvgCPUTime()), float64(encrypted.AvgCPUTime())),
+		})
+	}
+
+	return overheads
+}
+
+// encryptionGroup returns the name of the given variant with its encryption algorithm replaced, allowing variants which
+// only differ by their encryption algorithm to be grouped, along with the algorithm itself.
+func encryptionGroup(variant string) (string, string, bool) {
+	parts := strings.Split(variant, ", ")
+
+	for idx, part := range parts {
+		if algo, ok := strings.CutPrefix(part, encryptionPrefix); ok {
+			parts[idx] = encryptionPrefix + "*"
+			return strings.Join(parts, ", "), algo, true
+		}
+	}
+
+	return "", "", false
+}
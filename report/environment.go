@@ -0,0 +1,146 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// environmentSections are the sections of the JSON report which describe the environment the benchmarks were run in,
+// for example the hardware, bucket/data blueprint and the 'cbbackupmgr' flags.
+var environmentSections = []string{"cluster", "backup_client", "cbbackupmgr", "volumes", "auto_compaction", "hardware", "autobench"}
+
+// identityFields are fields which identify (rather than describe) the environment, they're expected to differ between
+// runs on otherwise identical environments so are ignored.
+var identityFields = []string{"host", "id"}
+
+// environmentDifference is a single field which differs between the environments of two reports.
+type environmentDifference struct {
+	Field     string `json:"field"`
+	Baseline  string `json:"baseline"`
+	Candidate string `json:"candidate"`
+}
+
+// EnvironmentDiff is a component which lists the differences between the environments described by two JSON reports.
+type EnvironmentDiff []*environmentDifference
+
+// NewEnvironmentDiff creates a new 'EnvironmentDiff' component from the given decoded JSON reports, returns nil if the
+// environments are identical.
+func NewEnvironmentDiff(baseline, candidate map[string]any) EnvironmentDiff {
+	var (
+		before = make(map[string]string)
+		after  = make(map[string]string)
+	)
+
+	for _, section := range environmentSections {
+		flatten(section, baseline[section], before)
+		flatten(section, candidate[section], after)
+	}
+
+	fields := make([]string, 0, len(before)+len(after))
+
+	for field := range before {
+		fields = append(fields, field)
+	}
+
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+
+	slices.Sort(fields)
+
+	var diff EnvironmentDiff
+
+	for _, field := range fields {
+		if before[field] == after[field] {
+			continue
+		}
+
+		diff = append(diff, &environmentDifference{
+			Field:     field,
+			Baseline:  orMissing(before[field]),
+			Candidate: orMissing(after[field]),
+		})
+	}
+
+	return diff
+}
+
+// flatten populates the given map with the leaf values of the given decoded JSON value, keyed by their dotted path.
+// Slices also record their length, so that (for example) clusters with differing numbers of nodes are detected even
+// when the nodes are otherwise identical.
+func flatten(path string, decoded any, leaves map[string]string) {
+	switch decoded := decoded.(type) {
+	case nil:
+	case map[string]any:
+		for key, value := range decoded {
+			if slices.Contains(identityFields, key) {
+				continue
+			}
+
+			flatten(path+"."+key, value, leaves)
+		}
+	case []any:
+		leaves[path] = fmt.Sprintf("%d item(s)", len(decoded))
+
+		for idx, value := range decoded {
+			flatten(fmt.Sprintf("%s[%d]", path, idx), value, leaves)
+		}
+	default:
+		encoded, _ := json.Marshal(decoded)
+		leaves[path] = string(encoded)
+	}
+}
+
+// orMissing returns the given value, or a placeholder for a field which is missing from one of the reports.
+func orMissing(value string) string {
+	if value == "" {
+		return "missing"
+	}
+
+	return value
+}
+
+// String returns a string representation of the 'EnvironmentDiff' component which will be output in the comparison.
+func (e EnvironmentDiff) String() string {
+	if len(e) == 0 {
+		return "| Environment\n| -----------\n| The environments are identical"
+	}
+
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Environment\n| -----------")
+	fmt.Fprintf(buffer, "| WARNING: %d field(s) differ between the environments, the results may not be comparable\n|\n",
+		len(e))
+	fmt.Fprintf(writer, "| Field\t Baseline\t Candidate\t\n")
+
+	for _, difference := range e {
+		fmt.Fprintf(writer, "| %s\t %s\t %s\t\n", difference.Field, difference.Baseline, difference.Candidate)
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
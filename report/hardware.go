@@ -0,0 +1,50 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+)
+
+// Hardware is a component which displays the CPUs, memory and operating system of the cluster nodes/backup client.
+type Hardware []*value.Hardware
+
+// String returns a string representation of the 'Hardware' component which will be output in the report.
+func (h Hardware) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Hardware\n| --------")
+	fmt.Fprintf(writer, "| Host\t CPUs\t Memory\t OS\t\n")
+
+	for _, hardware := range h {
+		fmt.Fprintf(writer, "| %s\t %d\t %.1fGiB\t %s\t\n",
+			hardware.Host,
+			hardware.CPUs,
+			float64(hardware.Memory)/(1024*1024),
+			hardware.OS)
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+
+	"github.com/jamesl33/cbtools-autobench/utilities"
+)
+
+// Harness is a component which contains the version of cbtools-autobench which produced the report, allowing changes
+// in the harness itself to be distinguished from changes in the builds being benchmarked.
+type Harness struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+}
+
+// NewHarness creates a new 'Harness' component describing the running version of cbtools-autobench.
+func NewHarness() *Harness {
+	version, commit := utilities.BuildVersion()
+
+	return &Harness{Version: version, Commit: commit}
+}
+
+// String returns a string representation of the 'Harness' component which will be output in the report.
+func (h *Harness) String() string {
+	return fmt.Sprintf("| Autobench\n| ---------\n| Version: %s (%s)", h.Version, h.Commit)
+}
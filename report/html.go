@@ -0,0 +1,57 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"html/template"
+
+	fsutil "github.com/couchbase/tools-common/fs/util"
+
+	"github.com/pkg/errors"
+)
+
+// htmlTemplate is the template used to render the report as a standalone HTML page.
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>cbtools-autobench report</title>
+<style>body { font-family: sans-serif; } pre { background: #f4f4f4; padding: 1em; }</style>
+</head>
+<body>
+<h1>cbtools-autobench report</h1>
+<pre>{{ . }}</pre>
+</body>
+</html>
+`))
+
+// HTMLSink writes a standalone HTML version of the report to a file on the local machine, any existing file will be
+// overwritten.
+type HTMLSink struct {
+	Path string
+}
+
+// Write implements the 'Sink' interface.
+func (h *HTMLSink) Write(report *Report) error {
+	buffer := &bytes.Buffer{}
+
+	err := htmlTemplate.Execute(buffer, report.String())
+	if err != nil {
+		return errors.Wrap(err, "failed to render template")
+	}
+
+	return fsutil.WriteFile(h.Path, buffer.Bytes(), 0)
+}
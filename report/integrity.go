@@ -0,0 +1,78 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// integrityResult encapsulates the outcome of verifying the restored documents for a single benchmark iteration.
+type integrityResult struct {
+	Iteration  int      `json:"iteration"`
+	Verified   bool     `json:"verified"`
+	Documents  uint64   `json:"documents"`
+	Expected   uint64   `json:"expected"`
+	Mismatched uint64   `json:"mismatched"`
+	Examples   []string `json:"examples,omitempty"`
+}
+
+// Integrity is a component which displays whether the documents restored by each iteration matched those which were
+// backed up. Only populated when verifying integrity.
+type Integrity []*integrityResult
+
+// NewIntegrity creates a new 'Integrity' component with the provided options.
+func NewIntegrity(options Options) Integrity {
+	var results []*integrityResult
+
+	for iteration, result := range options.Results {
+		if result.Integrity == nil {
+			continue
+		}
+
+		results = append(results, &integrityResult{
+			Iteration:  iteration + 1,
+			Verified:   result.Integrity.Verified(),
+			Documents:  result.Integrity.Documents,
+			Expected:   result.Integrity.Expected,
+			Mismatched: result.Integrity.Mismatched,
+			Examples:   result.Integrity.Examples,
+		})
+	}
+
+	return results
+}
+
+// String returns a string representation of the 'Integrity' component which will be output in the report.
+func (i Integrity) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Integrity\n| ---------")
+	fmt.Fprintf(writer, "| Iteration\t Verified\t Documents\t Expected\t Mismatched\t Examples\t\n")
+
+	for _, result := range i {
+		fmt.Fprintf(writer, "| %d\t %t\t %d\t %d\t %d\t %s\t\n", result.Iteration, result.Verified, result.Documents,
+			result.Expected, result.Mismatched, strings.Join(result.Examples, ", "))
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
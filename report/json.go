@@ -0,0 +1,29 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	fsutil "github.com/couchbase/tools-common/fs/util"
+)
+
+// JSONSink writes the JSON report to a file on the local machine, any existing file will be overwritten.
+type JSONSink struct {
+	Path string
+}
+
+// Write implements the 'Sink' interface.
+func (j *JSONSink) Write(report *Report) error {
+	return fsutil.WriteJSONFile(j.Path, report, 0)
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// latencyRow encapsulates the round trip times measured from a single host to each of the cluster nodes.
+type latencyRow struct {
+	Source string            `json:"source"`
+	RTT    map[string]string `json:"rtt"`
+}
+
+// Latency is a component which displays a matrix of the round trip times measured between the backup client/cluster
+// nodes prior to benchmarking, allowing benchmarks which were accidentally run across regions to be identified.
+type Latency struct {
+	Targets []string      `json:"targets"`
+	Rows    []*latencyRow `json:"rows"`
+}
+
+// NewLatency creates a new 'Latency' component with the provided options, returns nil if the latency wasn't measured.
+func NewLatency(options Options) *Latency {
+	if len(options.Latency) == 0 {
+		return nil
+	}
+
+	latency := &Latency{}
+
+	for _, measured := range options.Latency {
+		if !slices.Contains(latency.Targets, measured.Target) {
+			latency.Targets = append(latency.Targets, measured.Target)
+		}
+
+		idx := slices.IndexFunc(latency.Rows, func(row *latencyRow) bool { return row.Source == measured.Source })
+		if idx == -1 {
+			latency.Rows = append(latency.Rows, &latencyRow{Source: measured.Source, RTT: make(map[string]string)})
+			idx = len(latency.Rows) - 1
+		}
+
+		latency.Rows[idx].RTT[measured.Target] = formatRTT(measured.RTT)
+	}
+
+	return latency
+}
+
+// String returns a string representation of the 'Latency' component which will be output in the report.
+func (l *Latency) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Latency\n| -------")
+	fmt.Fprintf(writer, "| Source/Target\t %s\t\n", strings.Join(l.Targets, "\t "))
+
+	for _, row := range l.Rows {
+		cells := make([]string, 0, len(l.Targets))
+
+		// The latency from a host to itself isn't measured
+		for _, target := range l.Targets {
+			rtt, ok := row.RTT[target]
+			if !ok {
+				rtt = "-"
+			}
+
+			cells = append(cells, rtt)
+		}
+
+		fmt.Fprintf(writer, "| %s\t %s\t\n", row.Source, strings.Join(cells, "\t "))
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
+
+// formatRTT returns a human readable representation of the given round trip time, with microsecond precision.
+func formatRTT(rtt time.Duration) string {
+	return fmt.Sprintf("%.3fms", float64(rtt)/float64(time.Millisecond))
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/couchbase/tools-common/strings/format"
+)
+
+// matrixResult encapsulates the outcome of benchmarking a single cluster/cbbackupmgr version pair.
+type matrixResult struct {
+	ClusterVersion     string `json:"cluster_version"`
+	BackupVersion      string `json:"backup_version"`
+	Compatible         bool   `json:"compatible"`
+	Iterations         int    `json:"iterations,omitempty"`
+	AvgDuration        string `json:"avg_duration,omitempty"`
+	AvgTransferRateADS string `json:"avg_transfer_rate_ads,omitempty"`
+	Error              string `json:"error,omitempty"`
+}
+
+// Matrix is a component which shows the compatibility/performance of each cluster/cbbackupmgr version pair which was
+// benchmarked by the 'matrix' sub-command.
+type Matrix []*matrixResult
+
+// NewMatrix creates a new 'Matrix' component with the provided options, returns nil if a matrix wasn't run.
+func NewMatrix(options Options) Matrix {
+	var results []*matrixResult
+
+	for _, pair := range options.Matrix {
+		result := &matrixResult{
+			ClusterVersion: pair.Pair.ClusterVersion(),
+			BackupVersion:  pair.Pair.BackupVersion(),
+			Compatible:     pair.Err == nil && len(pair.Results) != 0,
+		}
+
+		if pair.Err != nil {
+			result.Error = pair.Err.Error()
+		}
+
+		if len(pair.Results) != 0 {
+			result.Iterations = len(pair.Results)
+			result.AvgDuration = format.Duration(pair.Results.AvgDuration())
+			result.AvgTransferRateADS = format.Bytes(pair.Results.AvgTransferRateADS())
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// String returns a string representation of the 'Matrix' component which will be output in the report, each row is a
+// cluster version and each column is a 'cbbackupmgr' version.
+func (m Matrix) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+
+		clusters = make([]string, 0)
+		backups  = make([]string, 0)
+		cells    = make(map[[2]string]*matrixResult)
+	)
+
+	for _, result := range m {
+		key := [2]string{result.ClusterVersion, result.BackupVersion}
+
+		if _, ok := cells[key]; ok {
+			continue
+		}
+
+		if !slices.Contains(clusters, result.ClusterVersion) {
+			clusters = append(clusters, result.ClusterVersion)
+		}
+
+		if !slices.Contains(backups, result.BackupVersion) {
+			backups = append(backups, result.BackupVersion)
+		}
+
+		cells[key] = result
+	}
+
+	fmt.Fprintln(buffer, "| Compatibility Matrix\n| --------------------")
+	fmt.Fprintf(writer, "| Cluster \\ cbbackupmgr (Avg Transfer Rate (ADS))\t %s\t\n", strings.Join(backups, "\t "))
+
+	for _, cluster := range clusters {
+		row := make([]string, 0, len(backups))
+
+		for _, backup := range backups {
+			row = append(row, cells[[2]string{cluster, backup}].cell())
+		}
+
+		fmt.Fprintf(writer, "| %s\t %s\t\n", cluster, strings.Join(row, "\t "))
+	}
+
+	_ = writer.Flush()
+
+	for _, result := range m {
+		if result.Error != "" {
+			fmt.Fprintf(buffer, "\n| %s/%s: %s", result.ClusterVersion, result.BackupVersion, result.Error)
+		}
+	}
+
+	return strings.TrimSpace(buffer.String())
+}
+
+// cell returns the value displayed in the matrix for this pair.
+func (m *matrixResult) cell() string {
+	switch {
+	case m == nil:
+		return "-"
+	case !m.Compatible:
+		return "failed"
+	default:
+		return m.AvgTransferRateADS + "/s"
+	}
+}
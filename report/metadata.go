@@ -0,0 +1,106 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+)
+
+// metadataResult encapsulates the metadata recorded by 'cbbackupmgr' for a backup created by a single iteration.
+type metadataResult struct {
+	Iteration int                   `json:"iteration"`
+	Date      string                `json:"date"`
+	Metadata  *value.BackupMetadata `json:"metadata"`
+}
+
+// Metadata is a component which displays what 'cbbackupmgr' recorded about each backup (storage format, shard count
+// and the flags actually in effect), allowing it to be checked against what was requested in the config.
+type Metadata []*metadataResult
+
+// NewMetadata creates a new 'Metadata' component with the provided options, returns nil if no metadata was read.
+func NewMetadata(options Options) Metadata {
+	var results []*metadataResult
+
+	for iteration, result := range options.Results {
+		for _, backup := range result.Backups {
+			if backup.Metadata == nil {
+				continue
+			}
+
+			results = append(results, &metadataResult{
+				Iteration: iteration + 1,
+				Date:      backup.Date,
+				Metadata:  backup.Metadata,
+			})
+		}
+	}
+
+	return results
+}
+
+// String returns a string representation of the 'Metadata' component which will be output in the report.
+func (m Metadata) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Backup Metadata\n| ---------------")
+	fmt.Fprintf(writer, "| Iteration\t Backup\t Storage\t Version\t Shards\t Encrypted\t Compressed\t PiTR\t\n")
+
+	for _, result := range m {
+		fmt.Fprintf(writer, "| %d\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t\n",
+			result.Iteration,
+			result.Date,
+			orNA(result.Metadata.Storage),
+			orNA(formatInt(result.Metadata.StorageVersion)),
+			orNA(formatInt(result.Metadata.Shards)),
+			formatFlag(result.Metadata.Encrypted),
+			formatFlag(result.Metadata.Compressed),
+			formatFlag(result.Metadata.PointInTime))
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
+
+// formatInt returns the given value as a string, or an empty string when it's zero (i.e. unknown).
+func formatInt(value int) string {
+	if value == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(value)
+}
+
+// formatFlag returns "yes"/"no" for the given flag, or "N/A" when it wasn't present in the metadata.
+func formatFlag(flag *bool) string {
+	if flag == nil {
+		return "N/A"
+	}
+
+	if *flag {
+		return "yes"
+	}
+
+	return "no"
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/couchbase/tools-common/strings/format"
+)
+
+// bandwidthResult encapsulates the network bandwidth measured between the backup client and a single cluster node.
+type bandwidthResult struct {
+	Host          string `json:"host"`
+	BitsPerSecond uint64 `json:"bits_per_second"`
+	Bandwidth     string `json:"bandwidth"`
+}
+
+// Network is a component which displays the raw network bandwidth measured between the backup client and each cluster
+// node prior to benchmarking, allowing transfer rates to be judged against the speed of the link.
+type Network []*bandwidthResult
+
+// NewNetwork creates a new 'Network' component with the provided options, returns nil if the bandwidth wasn't measured.
+func NewNetwork(options Options) Network {
+	var results []*bandwidthResult
+
+	for _, bandwidth := range options.Bandwidth {
+		results = append(results, &bandwidthResult{
+			Host:          bandwidth.Host,
+			BitsPerSecond: bandwidth.BitsPerSecond,
+			Bandwidth:     format.Bytes(bandwidth.BytesPerSecond()) + "/s",
+		})
+	}
+
+	return results
+}
+
+// String returns a string representation of the 'Network' component which will be output in the report.
+func (n Network) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Network\n| -------")
+	fmt.Fprintf(writer, "| Host\t Bandwidth\t Bits/s\t\n")
+
+	for _, result := range n {
+		fmt.Fprintf(writer, "| %s\t %s\t %d\t\n", result.Host, result.Bandwidth, result.BitsPerSecond)
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
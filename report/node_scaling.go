@@ -0,0 +1,132 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/couchbase/tools-common/strings/format"
+)
+
+// nodeScalingResult encapsulates the throughput for a single cluster size, along with how well it scales relative to
+// the smallest cluster size.
+type nodeScalingResult struct {
+	Nodes              int    `json:"nodes"`
+	Iterations         int    `json:"iterations,omitempty"`
+	AvgDuration        string `json:"avg_duration,omitempty"`
+	AvgTransferRateADS string `json:"avg_transfer_rate_ads,omitempty"`
+	Speedup            string `json:"speedup,omitempty"`
+	Efficiency         string `json:"efficiency,omitempty"`
+	Error              string `json:"error,omitempty"`
+}
+
+// NodeScaling is a component which shows how the throughput of 'cbbackupmgr' scales with the number of nodes in the
+// cluster, for each cluster size benchmarked by the 'scale' sub-command.
+type NodeScaling []*nodeScalingResult
+
+// NewNodeScaling creates a new 'NodeScaling' component with the provided options, returns nil if a node count sweep
+// wasn't run.
+func NewNodeScaling(options Options) NodeScaling {
+	if len(options.NodeScaling) == 0 {
+		return nil
+	}
+
+	// Sizes are run largest first, the baseline is the smallest size which was successfully benchmarked
+	var baseline *nodeScalingBaseline
+
+	for _, size := range options.NodeScaling {
+		if len(size.Results) != 0 && (baseline == nil || size.Nodes < baseline.nodes) {
+			baseline = &nodeScalingBaseline{nodes: size.Nodes, rate: float64(size.Results.AvgTransferRateADS())}
+		}
+	}
+
+	scaling := make(NodeScaling, 0, len(options.NodeScaling))
+
+	for idx := len(options.NodeScaling) - 1; idx >= 0; idx-- {
+		var (
+			size   = options.NodeScaling[idx]
+			result = &nodeScalingResult{Nodes: size.Nodes, Speedup: "N/A", Efficiency: "N/A"}
+		)
+
+		if size.Err != nil {
+			result.Error = size.Err.Error()
+		}
+
+		if len(size.Results) != 0 {
+			result.Iterations = len(size.Results)
+			result.AvgDuration = format.Duration(size.Results.AvgDuration())
+			result.AvgTransferRateADS = format.Bytes(size.Results.AvgTransferRateADS())
+		}
+
+		if len(size.Results) != 0 && baseline.rate != 0 {
+			var (
+				speedup = float64(size.Results.AvgTransferRateADS()) / baseline.rate
+				linear  = float64(size.Nodes) / float64(baseline.nodes)
+			)
+
+			result.Speedup = fmt.Sprintf("%.2fx", speedup)
+			result.Efficiency = fmt.Sprintf("%.2f%%", speedup/linear*100)
+		}
+
+		scaling = append(scaling, result)
+	}
+
+	return scaling
+}
+
+// nodeScalingBaseline is the cluster size which the remaining sizes are compared against.
+type nodeScalingBaseline struct {
+	nodes int
+	rate  float64
+}
+
+// String returns a string representation of the 'NodeScaling' component which will be output in the report.
+func (n NodeScaling) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Node Scaling\n| ------------")
+	fmt.Fprintf(writer, "| Nodes\t Iterations\t Avg Duration\t Avg Transfer Rate (ADS)\t Speedup\t Efficiency\t\n")
+
+	for _, result := range n {
+		if result.Error != "" {
+			fmt.Fprintf(writer, "| %d\t -\t -\t failed\t N/A\t N/A\t\n", result.Nodes)
+			continue
+		}
+
+		fmt.Fprintf(writer, "| %d\t %d\t %s\t %s/s\t %s\t %s\t\n",
+			result.Nodes,
+			result.Iterations,
+			result.AvgDuration,
+			result.AvgTransferRateADS,
+			result.Speedup,
+			result.Efficiency)
+	}
+
+	_ = writer.Flush()
+
+	for _, result := range n {
+		if result.Error != "" {
+			fmt.Fprintf(buffer, "\n| %d node(s): %s", result.Nodes, result.Error)
+		}
+	}
+
+	return strings.TrimSpace(buffer.String())
+}
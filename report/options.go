@@ -27,4 +27,36 @@ type Options struct {
 	Results     value.BenchmarkResults
 	ClusterLogs []string
 	BackupLogs  string
+
+	// Bandwidth is the network bandwidth measured between the backup client and each cluster node, nil when the network
+	// preflight is disabled.
+	Bandwidth []*value.Bandwidth
+
+	// Latency is the round trip time measured between the backup client/cluster nodes, nil when the latency preflight is
+	// disabled.
+	Latency []*value.Latency
+
+	// Load is the summary of the last data load recorded on the cluster, nil if one wasn't recorded.
+	Load *value.LoadSummary
+
+	// Volumes are the characteristics of the AWS EBS volumes described by the cluster/backup client blueprints.
+	Volumes []*value.Volume
+
+	// AutoCompaction are the effective auto-compaction settings whilst benchmarking, nil when they weren't configured.
+	AutoCompaction *value.AutoCompactionSettings
+
+	// Hardware describes the machines of the cluster nodes/backup client, nil if they couldn't be described.
+	Hardware []*value.Hardware
+
+	// Matrix is the outcome of each cluster/backup client version pair, nil unless run using the 'matrix' sub-command.
+	Matrix []*value.MatrixResult
+
+	// NodeScaling is the outcome of each cluster size, nil unless run using the 'scale' sub-command.
+	NodeScaling []*value.NodeScalingResult
+
+	// Modes are the benchmarks which were run e.g. backup/restore.
+	Modes []string
+
+	// Config is the config used to run the benchmarks, it will be redacted before being included in the report.
+	Config *value.AutobenchConfig
 }
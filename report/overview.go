@@ -32,10 +32,24 @@ type Overview struct {
 	AvgGDS             string `json:"avg_gds,omitempty"`
 	AvgTransferRateADS string `json:"avg_transfer_rate_ads,omitempty"`
 	AvgTransferRateGDS string `json:"avg_transfer_rate_gds,omitempty"`
+
+	// Speedup/efficiency of the largest configuration relative to the smallest, only populated when the benchmarked
+	// variants differ by their thread count.
+	ScalingVariant string `json:"scaling_variant,omitempty"`
+	Speedup        string `json:"speedup,omitempty"`
+	Efficiency     string `json:"efficiency,omitempty"`
+
+	// Encryption is the overhead introduced by each encryption algorithm, only populated when encrypted/unencrypted
+	// variants were benchmarked.
+	Encryption []*encryptionOverhead `json:"encryption_overhead,omitempty"`
 }
 
-// NewOverview creates a new overview component with the provided options.
+// NewOverview creates a new overview component with the provided options, returns nil if there are no results.
 func NewOverview(options Options) *Overview {
+	if len(options.Results) == 0 {
+		return nil
+	}
+
 	var (
 		duration        time.Duration
 		ads             uint64
@@ -52,13 +66,23 @@ func NewOverview(options Options) *Overview {
 		transferRateGDS += result.AvgTransferRateGDS(options.Blueprint.Cluster.Bucket.Data)
 	}
 
-	return &Overview{
+	overview := &Overview{
 		AvgDuration:        format.Duration(time.Duration(int64(duration) / int64(len(options.Results)))),
 		AvgADS:             format.Bytes(ads / uint64(len(options.Results))),
 		AvgGDS:             format.Bytes(gds / uint64(len(options.Results))),
 		AvgTransferRateADS: format.Bytes(transferRateADS / uint64(len(options.Results))),
 		AvgTransferRateGDS: format.Bytes(transferRateGDS / uint64(len(options.Results))),
 	}
+
+	if worst := NewScaling(options).worst(); worst != nil {
+		overview.ScalingVariant = worst.Variant
+		overview.Speedup = worst.Speedup
+		overview.Efficiency = worst.Efficiency
+	}
+
+	overview.Encryption = newEncryptionOverheads(options.Results)
+
+	return overview
 }
 
 // String returns a string representation of the 'Logs' component which will be output in the report.
@@ -80,5 +104,18 @@ func (o *Overview) String() string {
 
 	_ = writer.Flush()
 
+	if o.ScalingVariant != "" {
+		fmt.Fprintf(buffer, "\n| Scaling (%s): %s speedup, %s efficiency\n", o.ScalingVariant, o.Speedup, o.Efficiency)
+	}
+
+	if len(o.Encryption) != 0 {
+		fmt.Fprintln(buffer)
+	}
+
+	for _, overhead := range o.Encryption {
+		fmt.Fprintf(buffer, "| Encryption overhead (%s): %s duration, %s CPU\n", overhead.Variant, overhead.Duration,
+			overhead.CPU)
+	}
+
 	return strings.TrimSpace(buffer.String())
 }
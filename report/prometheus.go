@@ -0,0 +1,81 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+
+	fsutil "github.com/couchbase/tools-common/fs/util"
+	"github.com/jamesl33/cbtools-autobench/value"
+)
+
+// prometheusMetric describes a single gauge which will be output for each benchmark iteration.
+type prometheusMetric struct {
+	name  string
+	help  string
+	value func(result *value.BenchmarkResult) float64
+}
+
+// prometheusMetrics are the metrics which will be written by the Prometheus sink.
+var prometheusMetrics = []prometheusMetric{
+	{
+		name:  "cbtools_autobench_duration_seconds",
+		help:  "The time taken to complete the benchmark iteration.",
+		value: func(result *value.BenchmarkResult) float64 { return result.Duration.Seconds() },
+	},
+	{
+		name:  "cbtools_autobench_items",
+		help:  "The actual number of items transferred during the benchmark iteration.",
+		value: func(result *value.BenchmarkResult) float64 { return float64(result.AIN) },
+	},
+	{
+		name:  "cbtools_autobench_size_bytes",
+		help:  "The actual size of the data transferred during the benchmark iteration.",
+		value: func(result *value.BenchmarkResult) float64 { return float64(result.ADS) },
+	},
+	{
+		name:  "cbtools_autobench_transfer_rate_bytes",
+		help:  "The transfer rate per second of the benchmark iteration calculated using the actual data size.",
+		value: func(result *value.BenchmarkResult) float64 { return float64(result.AvgTransferRateADS()) },
+	},
+}
+
+// PrometheusSink writes the report results in the Prometheus text exposition format, this is intended to be picked up
+// by the node exporter textfile collector.
+type PrometheusSink struct {
+	Path string
+}
+
+// Write implements the 'Sink' interface.
+func (p *PrometheusSink) Write(report *Report) error {
+	buffer := &bytes.Buffer{}
+
+	for _, metric := range prometheusMetrics {
+		fmt.Fprintf(buffer, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(buffer, "# TYPE %s gauge\n", metric.name)
+
+		for index, result := range report.results {
+			labels := fmt.Sprintf("iteration=\"%d\"", index+1)
+			if result.Variant != "" {
+				labels += fmt.Sprintf(",variant=\"%s\"", result.Variant)
+			}
+
+			fmt.Fprintf(buffer, "%s{%s} %g\n", metric.name, labels, metric.value(result))
+		}
+	}
+
+	return fsutil.WriteFile(p.Path, buffer.Bytes(), 0)
+}
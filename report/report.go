@@ -16,7 +16,6 @@ package report
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -27,33 +26,92 @@ import (
 
 // Report is the benchmark report which will be printed to stdout upon completion of the benchmarks.
 type Report struct {
-	Cluster      *value.ClusterBlueprint      `json:"cluster,omitempty"`
-	BackupClient *value.BackupClientBlueprint `json:"backup_client,omitempty"`
-	CBM          *value.CBMConfig             `json:"cbbackupmgr,omitempty"`
-	Stats        *value.Stats                 `json:"bucket_stats,omitempty"`
-	Overview     *Overview                    `json:"overview,omitempty"`
-	Rundown      Rundown                      `json:"rundown,omitempty"`
-	Logs         *Logs                        `json:"logs,omitempty"`
+	// SchemaVersion is the version of the JSON report, see 'SchemaVersion'.
+	SchemaVersion int `json:"schema_version"`
+
+	Warnings     Warnings                      `json:"warnings,omitempty"`
+	Cluster      *value.ClusterBlueprint       `json:"cluster,omitempty"`
+	BackupClient *value.BackupClientBlueprint  `json:"backup_client,omitempty"`
+	CBM          *value.CBMConfig              `json:"cbbackupmgr,omitempty"`
+	Network      Network                       `json:"network,omitempty"`
+	Latency      *Latency                      `json:"latency,omitempty"`
+	Volumes      Volumes                       `json:"volumes,omitempty"`
+	Compaction   *value.AutoCompactionSettings `json:"auto_compaction,omitempty"`
+	Hardware     Hardware                      `json:"hardware,omitempty"`
+	Stats        *value.Stats                  `json:"bucket_stats,omitempty"`
+	Load         *value.LoadSummary            `json:"data_load,omitempty"`
+	Matrix       Matrix                        `json:"matrix,omitempty"`
+	NodeScaling  NodeScaling                   `json:"node_scaling,omitempty"`
+	Overview     *Overview                     `json:"overview,omitempty"`
+	Comparison   Comparison                    `json:"comparison,omitempty"`
+	Scaling      Scaling                       `json:"scaling,omitempty"`
+	Rundown      Rundown                       `json:"rundown,omitempty"`
+	Tasks        Tasks                         `json:"tasks,omitempty"`
+	Compression  Compression                   `json:"compression,omitempty"`
+	Metadata     Metadata                      `json:"backup_metadata,omitempty"`
+	Requests     Requests                      `json:"requests,omitempty"`
+	Integrity    Integrity                     `json:"integrity,omitempty"`
+	Cost         Cost                          `json:"cost,omitempty"`
+	DCP          DCP                           `json:"dcp,omitempty"`
+	Backups      BackupDetails                 `json:"backup_details,omitempty"`
+	Logs         *Logs                         `json:"logs,omitempty"`
+	Config       *Config                       `json:"config,omitempty"`
+	Harness      *Harness                      `json:"autobench,omitempty"`
+
+	// results are the raw benchmark results, these are used by sinks which require unformatted values.
+	results value.BenchmarkResults
 }
 
 // NewReport creates a new report with the provided options.
 func NewReport(options Options) *Report {
 	return &Report{
-		Cluster:      options.Blueprint.Cluster,
-		Stats:        options.Stats,
-		BackupClient: options.Blueprint.BackupClient,
-		CBM:          options.CBMConfig,
-		Overview:     NewOverview(options),
-		Rundown:      NewRundown(options),
-		Logs:         NewLogs(options),
+		SchemaVersion: SchemaVersion,
+		Warnings:      NewWarnings(options),
+		Cluster:       options.Blueprint.Cluster,
+		Stats:         options.Stats,
+		Load:          options.Load,
+		BackupClient:  options.Blueprint.BackupClient,
+		CBM:           options.CBMConfig,
+		Network:       NewNetwork(options),
+		Latency:       NewLatency(options),
+		Volumes:       options.Volumes,
+		Compaction:    options.AutoCompaction,
+		Hardware:      options.Hardware,
+		Matrix:        NewMatrix(options),
+		NodeScaling:   NewNodeScaling(options),
+		Overview:      NewOverview(options),
+		Comparison:    NewComparison(options),
+		Scaling:       NewScaling(options),
+		Rundown:       NewRundown(options),
+		Tasks:         NewTasks(options),
+		Compression:   NewCompression(options),
+		Metadata:      NewMetadata(options),
+		Requests:      NewRequests(options),
+		Integrity:     NewIntegrity(options),
+		Cost:          NewCost(options),
+		DCP:           NewDCP(options),
+		Backups:       NewBackupDetails(options),
+		Logs:          NewLogs(options),
+		Config:        NewConfig(options),
+		Harness:       NewHarness(),
+		results:       options.Results,
 	}
 }
 
+// Results returns the raw benchmark results used to generate the report.
+func (r *Report) Results() value.BenchmarkResults {
+	return r.results
+}
+
 // String returns a string representation of the report. Components which are empty/unused will be omitted in a similar
 // fashion to that of the 'omitempty' tag.
 func (r *Report) String() string {
 	buffer := &bytes.Buffer{}
 
+	if r.Warnings != nil {
+		fmt.Fprintf(buffer, "%s\n", r.Warnings)
+	}
+
 	if r.Cluster != nil {
 		fmt.Fprintf(buffer, "%s\n\n", r.Cluster)
 	}
@@ -62,6 +120,10 @@ func (r *Report) String() string {
 		fmt.Fprintf(buffer, "%s\n\n", r.Stats)
 	}
 
+	if r.Load != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Load)
+	}
+
 	if r.BackupClient != nil {
 		fmt.Fprintf(buffer, "%s\n\n", r.BackupClient)
 	}
@@ -70,34 +132,89 @@ func (r *Report) String() string {
 		fmt.Fprintf(buffer, "%s\n\n", r.CBM)
 	}
 
+	if r.Network != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Network)
+	}
+
+	if r.Latency != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Latency)
+	}
+
+	if r.Volumes != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Volumes)
+	}
+
+	if r.Compaction != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Compaction)
+	}
+
+	if r.Hardware != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Hardware)
+	}
+
+	if r.Matrix != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Matrix)
+	}
+
+	if r.NodeScaling != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.NodeScaling)
+	}
+
 	if r.Overview != nil {
 		fmt.Fprintf(buffer, "%s\n\n", r.Overview)
 	}
 
+	if r.Comparison != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Comparison)
+	}
+
+	if r.Scaling != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Scaling)
+	}
+
 	if r.Rundown != nil {
 		fmt.Fprintf(buffer, "%s\n\n", r.Rundown)
 	}
 
-	if r.Logs != nil {
-		fmt.Fprintf(buffer, "%s\n", r.Logs)
+	if r.Tasks != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Tasks)
 	}
 
-	return strings.TrimSpace(buffer.String())
-}
+	if r.Compression != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Compression)
+	}
+
+	if r.Metadata != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Metadata)
+	}
+
+	if r.Requests != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Requests)
+	}
+
+	if r.Integrity != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Integrity)
+	}
+
+	if r.Cost != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Cost)
+	}
 
-// Print displays a string representation of the report, this is either a human readable form or standard JSON.
-func (r *Report) Print(jsonOut bool) error {
-	if !jsonOut {
-		fmt.Printf("%s\n", r)
-		return nil
+	if r.DCP != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.DCP)
 	}
 
-	rJSON, err := json.Marshal(r)
-	if err != nil {
-		return err
+	if r.Logs != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Logs)
 	}
 
-	fmt.Printf("%s\n", rJSON)
+	if r.Config != nil {
+		fmt.Fprintf(buffer, "%s\n\n", r.Config)
+	}
 
-	return nil
+	if r.Harness != nil {
+		fmt.Fprintf(buffer, "%s\n", r.Harness)
+	}
+
+	return strings.TrimSpace(buffer.String())
 }
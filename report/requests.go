@@ -0,0 +1,76 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// requestsResult encapsulates the number of requests made to the object store during a single benchmark iteration.
+type requestsResult struct {
+	Iteration int    `json:"iteration"`
+	Put       uint64 `json:"put"`
+	Get       uint64 `json:"get"`
+	List      uint64 `json:"list"`
+	Other     uint64 `json:"other"`
+}
+
+// Requests is a component which displays the number of requests made to the object store during each iteration,
+// counted using the S3 SDK debug logs. Only populated when counting requests.
+type Requests []*requestsResult
+
+// NewRequests creates a new 'Requests' component with the provided options.
+func NewRequests(options Options) Requests {
+	var results []*requestsResult
+
+	for iteration, result := range options.Results {
+		if result.Requests == nil {
+			continue
+		}
+
+		results = append(results, &requestsResult{
+			Iteration: iteration + 1,
+			Put:       result.Requests.Put(),
+			Get:       result.Requests.Get(),
+			List:      result.Requests.List(),
+			Other:     result.Requests.Other(),
+		})
+	}
+
+	return results
+}
+
+// String returns a string representation of the 'Requests' component which will be output in the report.
+func (r Requests) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Object Store Requests\n| ---------------------")
+	fmt.Fprintf(writer, "| Iteration\t PUT\t GET\t LIST\t Other\t\n")
+
+	for _, result := range r {
+		fmt.Fprintf(writer, "| %d\t %d\t %d\t %d\t %d\t\n", result.Iteration, result.Put, result.Get, result.List,
+			result.Other)
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
@@ -19,18 +19,39 @@ import (
 	"fmt"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/couchbase/tools-common/strings/format"
+	"github.com/jamesl33/cbtools-autobench/value"
 )
 
 // rundownResult encapsulates the information for a single benchmark iteration.
 type rundownResult struct {
+	Variant            string `json:"variant,omitempty"`
+	Range              string `json:"range,omitempty"`
+	Start              string `json:"start,omitempty"`
+	End                string `json:"end,omitempty"`
 	Duration           string `json:"duration,omitempty"`
 	AIN                string `json:"ain,omitempty"`
 	ADS                string `json:"ads,omitempty"`
 	GDS                string `json:"gds,omitempty"`
 	AvgTransferRateADS string `json:"avg_transfer_rate_ads,omitempty"`
 	AvgTransferRateGDS string `json:"avg_transfer_rate_gds,omitempty"`
+	DiskWriteQueue     string `json:"disk_write_queue_delta,omitempty"`
+	DCPBacklog         string `json:"dcp_backlog_delta,omitempty"`
+	BGFetched          string `json:"bg_fetched_delta,omitempty"`
+	Compaction         string `json:"compaction,omitempty"`
+
+	// Buckets is the per-bucket breakdown of the iteration, only populated when more than one bucket was backed up.
+	Buckets []*rundownBucket `json:"buckets,omitempty"`
+}
+
+// rundownBucket encapsulates the information for a single bucket backed up during a benchmark iteration.
+type rundownBucket struct {
+	Name            string `json:"name"`
+	Items           string `json:"items"`
+	Size            string `json:"size"`
+	AvgTransferRate string `json:"avg_transfer_rate"`
 }
 
 // Rundown is a component which contains the detailed rundown for each benchmark that was executed.
@@ -40,7 +61,16 @@ type Rundown []*rundownResult
 func NewRundown(options Options) Rundown {
 	results := make([]*rundownResult, 0, len(options.Results))
 	for _, result := range options.Results {
+		var (
+			before = result.KVStatsBefore
+			after  = result.KVStatsAfter
+		)
+
 		results = append(results, &rundownResult{
+			Variant:  result.Variant,
+			Range:    result.Range,
+			Start:    formatTime(result.Start),
+			End:      formatTime(result.End),
 			Duration: format.Duration(result.Duration),
 			AIN:      fmt.Sprint(result.AIN),
 			ADS:      format.Bytes(result.ADS),
@@ -48,6 +78,56 @@ func NewRundown(options Options) Rundown {
 				options.Blueprint.Cluster.Bucket.Data.Size)),
 			AvgTransferRateADS: format.Bytes(result.AvgTransferRateADS()),
 			AvgTransferRateGDS: format.Bytes(result.AvgTransferRateGDS(options.Blueprint.Cluster.Bucket.Data)),
+			DiskWriteQueue:     kvStatDelta(before, after, func(s *value.KVStats) uint64 { return s.DiskWriteQueue }),
+			DCPBacklog:         kvStatDelta(before, after, func(s *value.KVStats) uint64 { return s.DCPBacklog }),
+			BGFetched:          kvStatDelta(before, after, func(s *value.KVStats) uint64 { return s.BGFetched }),
+			Compaction:         compactionState(before, after),
+			Buckets:            newRundownBuckets(result),
+		})
+	}
+
+	return results
+}
+
+// newRundownBuckets returns the per-bucket breakdown for the given result, aggregated across all the backups created by
+// the iteration. Returns nil unless more than one bucket was backed up, since the breakdown would match the totals.
+func newRundownBuckets(result *value.BenchmarkResult) []*rundownBucket {
+	var (
+		names   []string
+		buckets = make(map[string]*value.BucketDetails)
+	)
+
+	for _, backup := range result.Backups {
+		for _, bucket := range backup.Buckets {
+			total, ok := buckets[bucket.Name]
+			if !ok {
+				total = &value.BucketDetails{Name: bucket.Name}
+				buckets[bucket.Name] = total
+				names = append(names, bucket.Name)
+			}
+
+			total.Items += bucket.Items
+			total.Size += bucket.Size
+		}
+	}
+
+	if len(names) < 2 {
+		return nil
+	}
+
+	results := make([]*rundownBucket, 0, len(names))
+
+	for _, name := range names {
+		rate := buckets[name].Size
+		if result.Duration >= time.Second {
+			rate /= uint64(result.Duration.Seconds())
+		}
+
+		results = append(results, &rundownBucket{
+			Name:            name,
+			Items:           fmt.Sprint(buckets[name].Items),
+			Size:            format.Bytes(buckets[name].Size),
+			AvgTransferRate: format.Bytes(rate),
 		})
 	}
 
@@ -62,21 +142,79 @@ func (r Rundown) String() string {
 	)
 
 	fmt.Fprintln(buffer, "| Rundown\n| -------")
-	fmt.Fprintf(writer, "| Iteration\t Duration\t Items (AIN)\t Size (ADS)\t Size (GDS)\t Transfer Rate (ADS)\t "+
-		"Transfer Rate (GDS)\t\n")
+	fmt.Fprintf(writer, "| Iteration\t Start\t End\t Duration\t Items (AIN)\t Size (ADS)\t Size (GDS)\t "+
+		"Transfer Rate (ADS)\t Transfer Rate (GDS)\t Disk Queue (Delta)\t DCP Backlog (Delta)\t "+
+		"BG Fetched (Delta)\t Compaction\t\n")
 
 	for index, result := range r {
-		fmt.Fprintf(writer, "| %d\t %s\t %s\t %s\t %s\t %s/s\t %s/s\t\n",
-			index+1,
+		iteration := fmt.Sprint(index + 1)
+		if result.Variant != "" {
+			iteration += fmt.Sprintf(" [%s]", result.Variant)
+		}
+
+		if result.Range != "" {
+			iteration += fmt.Sprintf(" (%s)", result.Range)
+		}
+
+		fmt.Fprintf(writer, "| %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s/s\t %s/s\t %s\t %s\t %s\t %s\t\n",
+			iteration,
+			result.Start,
+			result.End,
 			result.Duration,
 			result.AIN,
 			result.ADS,
 			result.GDS,
 			result.AvgTransferRateADS,
-			result.AvgTransferRateGDS)
+			result.AvgTransferRateGDS,
+			result.DiskWriteQueue,
+			result.DCPBacklog,
+			result.BGFetched,
+			result.Compaction)
+
+		for _, bucket := range result.Buckets {
+			fmt.Fprintf(writer, "|   %s\t \t \t \t %s\t %s\t \t %s/s\t \t \t \t \t \t\n",
+				bucket.Name,
+				bucket.Items,
+				bucket.Size,
+				bucket.AvgTransferRate)
+		}
 	}
 
 	_ = writer.Flush()
 
 	return strings.TrimSpace(buffer.String())
 }
+
+// formatTime returns the given time in UTC using the RFC3339 format, zero times are formatted as 'N/A'.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "N/A"
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// kvStatDelta returns the formatted difference in the given stat from before/after the benchmark.
+func kvStatDelta(before, after *value.KVStats, stat func(stats *value.KVStats) uint64) string {
+	if before == nil || after == nil {
+		return "N/A"
+	}
+
+	return fmt.Sprintf("%+d", int64(stat(after))-int64(stat(before)))
+}
+
+// compactionState returns a description of whether a compaction was running before/after the benchmark.
+func compactionState(before, after *value.KVStats) string {
+	switch {
+	case before == nil || after == nil:
+		return "N/A"
+	case before.Compacting && after.Compacting:
+		return "running"
+	case before.Compacting:
+		return "finished"
+	case after.Compacting:
+		return "started"
+	default:
+		return "idle"
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/couchbase/tools-common/strings/format"
+	"github.com/jamesl33/cbtools-autobench/value"
+)
+
+// scalingResult encapsulates the throughput for a single thread count, along with how well it scales relative to the
+// smallest thread count.
+type scalingResult struct {
+	Variant            string `json:"variant"`
+	Threads            int    `json:"threads"`
+	AvgTransferRateADS string `json:"avg_transfer_rate_ads,omitempty"`
+	Speedup            string `json:"speedup,omitempty"`
+	Efficiency         string `json:"efficiency,omitempty"`
+
+	// largest indicates whether this is the largest configuration in its group, along with the unformatted speedup and
+	// efficiency which are used to summarize the scaling in the 'Overview' component.
+	largest    bool
+	speedup    float64
+	efficiency float64
+}
+
+// Scaling is a component which shows how the throughput of 'cbbackupmgr' scales with the number of threads, when
+// variants only differ by their thread count.
+type Scaling []*scalingResult
+
+// NewScaling creates a new 'Scaling' component with the provided options, returns nil if a thread count sweep wasn't
+// run.
+func NewScaling(options Options) Scaling {
+	type variant struct {
+		name    string
+		threads int
+		results value.BenchmarkResults
+	}
+
+	var (
+		groups = make([]string, 0)
+		byName = make(map[string][]*variant)
+	)
+
+	for _, name := range options.Results.Variants() {
+		results := options.Results.Variant(name)
+
+		// Automatically selected thread counts can't be compared
+		threads := results[0].Threads
+		if threads == 0 {
+			continue
+		}
+
+		group := scalingGroup(name, threads)
+		if _, ok := byName[group]; !ok {
+			groups = append(groups, group)
+		}
+
+		byName[group] = append(byName[group], &variant{name: name, threads: threads, results: results})
+	}
+
+	var scaling Scaling
+
+	for _, group := range groups {
+		variants := byName[group]
+		if len(variants) < 2 {
+			continue
+		}
+
+		baseline, largest := variants[0], variants[0]
+
+		for _, variant := range variants {
+			if variant.threads < baseline.threads {
+				baseline = variant
+			}
+
+			if variant.threads > largest.threads {
+				largest = variant
+			}
+		}
+
+		for _, variant := range variants {
+			var (
+				rate     = float64(variant.results.AvgTransferRateADS())
+				baseRate = float64(baseline.results.AvgTransferRateADS())
+				linear   = float64(variant.threads) / float64(baseline.threads)
+				result   = &scalingResult{
+					Variant:            variant.name,
+					Threads:            variant.threads,
+					AvgTransferRateADS: format.Bytes(variant.results.AvgTransferRateADS()),
+					Speedup:            "N/A",
+					Efficiency:         "N/A",
+					largest:            variant == largest,
+				}
+			)
+
+			if baseRate != 0 {
+				result.speedup = rate / baseRate
+				result.efficiency = result.speedup / linear * 100
+				result.Speedup = fmt.Sprintf("%.2fx", result.speedup)
+				result.Efficiency = fmt.Sprintf("%.2f%%", result.efficiency)
+			}
+
+			scaling = append(scaling, result)
+		}
+	}
+
+	return scaling
+}
+
+// String returns a string representation of the 'Scaling' component which will be output in the report.
+func (s Scaling) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Scaling\n| -------")
+	fmt.Fprintf(writer, "| Variant\t Threads\t Avg Transfer Rate (ADS)\t Speedup\t Efficiency\t\n")
+
+	for _, result := range s {
+		fmt.Fprintf(writer, "| %s\t %d\t %s/s\t %s\t %s\t\n",
+			result.Variant,
+			result.Threads,
+			result.AvgTransferRateADS,
+			result.Speedup,
+			result.Efficiency)
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
+
+// worst returns the least efficient of the largest configurations from each group, this is the result most likely to
+// highlight a scaling regression. Returns nil if the efficiency couldn't be calculated for any group.
+func (s Scaling) worst() *scalingResult {
+	var worst *scalingResult
+
+	for _, result := range s {
+		if !result.largest || result.speedup == 0 {
+			continue
+		}
+
+		if worst == nil || result.efficiency < worst.efficiency {
+			worst = result
+		}
+	}
+
+	return worst
+}
+
+// scalingGroup returns the name of the variant excluding its thread count, variants in the same group only differ by
+// the number of threads they were run with.
+func scalingGroup(variant string, threads int) string {
+	return strings.TrimSuffix(strings.TrimSuffix(variant, fmt.Sprintf("threads=%d", threads)), ", ")
+}
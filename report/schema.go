@@ -0,0 +1,146 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the JSON report, it must be incremented whenever a field is removed/renamed or its
+// type is changed. Adding new fields is backwards compatible and doesn't require the version to be incremented.
+const SchemaVersion = 1
+
+// shaper is implemented by types which have a custom JSON encoding, returning a value with the same shape as the
+// encoding so that it can be described by the schema.
+type shaper interface {
+	JSONShape() any
+}
+
+// Schema returns the JSON schema which describes the JSON report, generated from the report types so that it's always
+// in sync with the report.
+func Schema() ([]byte, error) {
+	defs := make(map[string]any)
+
+	root := schemaFor(reflect.TypeOf(Report{}), defs)
+
+	return json.MarshalIndent(map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   "cbtools-autobench report",
+		"version": SchemaVersion,
+		"$ref":    root["$ref"],
+		"$defs":   defs,
+	}, "", "  ")
+}
+
+// schemaFor returns the schema for the given type, named structs are added to the provided definitions and referenced
+// allowing recursive types to be described.
+func schemaFor(t reflect.Type, defs map[string]any) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return map[string]any{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(time.Duration(0)):
+		return map[string]any{"type": "integer", "description": "duration in nanoseconds"}
+	}
+
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*shaper)(nil)).Elem()) {
+		return schemaFor(reflect.TypeOf(reflect.New(t).Interface().(shaper).JSONShape()), defs)
+	}
+
+	// Types with a custom encoding which can't be described are permitted to be any value
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) {
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), defs)}
+	case reflect.Struct:
+		return structSchema(t, defs)
+	default:
+		return map[string]any{}
+	}
+}
+
+// structSchema returns the schema for the given struct type, fields without the 'omitempty' option are required. Named
+// structs are added to the provided definitions and a reference is returned instead.
+func structSchema(t reflect.Type, defs map[string]any) map[string]any {
+	name := strings.TrimPrefix(t.String(), "*")
+
+	if t.Name() != "" {
+		if _, ok := defs[name]; ok {
+			return map[string]any{"$ref": "#/$defs/" + name}
+		}
+
+		// Reserve the definition before describing the fields, in case the struct is recursive
+		defs[name] = nil
+	}
+
+	var (
+		properties = make(map[string]any)
+		required   = make([]string, 0)
+	)
+
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+
+		tag := field.Tag.Get("json")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+
+		key, options, _ := strings.Cut(tag, ",")
+		if key == "" {
+			key = field.Name
+		}
+
+		properties[key] = schemaFor(field.Type, defs)
+
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, key)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties, "required": required}
+
+	if t.Name() == "" {
+		return schema
+	}
+
+	defs[name] = schema
+
+	return map[string]any{"$ref": "#/$defs/" + name}
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/pkg/errors"
+)
+
+// Sink is a destination which a report may be written to, for example stdout or a remote endpoint.
+type Sink interface {
+	Write(report *Report) error
+}
+
+// NewSink creates a new sink using the provided config.
+func NewSink(config *value.SinkConfig) (Sink, error) {
+	switch config.Type {
+	case "", value.SinkStdout:
+		return &StdoutSink{JSON: config.JSON}, nil
+	case value.SinkJSON:
+		return newFileSink(config, &JSONSink{Path: config.Path})
+	case value.SinkHTML:
+		return newFileSink(config, &HTMLSink{Path: config.Path})
+	case value.SinkPrometheus:
+		return newFileSink(config, &PrometheusSink{Path: config.Path})
+	case value.SinkWebhook:
+		if config.URL == "" {
+			return nil, errors.New("webhook sink requires a url")
+		}
+
+		return &WebhookSink{URL: config.URL}, nil
+	}
+
+	return nil, errors.Errorf("unknown/unsupported sink type '%s'", config.Type)
+}
+
+// NewSinks creates a sink for each of the provided configs.
+func NewSinks(configs []*value.SinkConfig) ([]Sink, error) {
+	sinks := make([]Sink, 0, len(configs))
+
+	for _, config := range configs {
+		sink, err := NewSink(config)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create '%s' sink", config.Type)
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	return sinks, nil
+}
+
+// newFileSink validates that the given config contains a path before returning the provided file based sink.
+func newFileSink(config *value.SinkConfig, sink Sink) (Sink, error) {
+	if config.Path == "" {
+		return nil, errors.Errorf("%s sink requires a path", config.Type)
+	}
+
+	return sink, nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// StdoutSink displays the report on stdout, this is either a human readable form or standard JSON.
+type StdoutSink struct {
+	JSON bool
+}
+
+// Write implements the 'Sink' interface.
+func (s *StdoutSink) Write(report *Report) error {
+	if !s.JSON {
+		fmt.Printf("%s\n", report)
+		return nil
+	}
+
+	rJSON, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", rJSON)
+
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/couchbase/tools-common/strings/format"
+)
+
+// taskResult encapsulates the information for a single backup when running concurrent backups.
+type taskResult struct {
+	Iteration          int    `json:"iteration"`
+	Task               int    `json:"task"`
+	Archive            string `json:"archive,omitempty"`
+	Repository         string `json:"repository,omitempty"`
+	Start              string `json:"start,omitempty"`
+	End                string `json:"end,omitempty"`
+	Duration           string `json:"duration,omitempty"`
+	AIN                string `json:"ain,omitempty"`
+	ADS                string `json:"ads,omitempty"`
+	AvgTransferRateADS string `json:"avg_transfer_rate_ads,omitempty"`
+}
+
+// Tasks is a component which contains the per-task rundown when running concurrent backups; the aggregate for each
+// iteration is displayed in the 'Rundown' component.
+type Tasks []*taskResult
+
+// NewTasks creates a new 'Tasks' component with the provided options, returns nil if no concurrent backups were run.
+func NewTasks(options Options) Tasks {
+	var results []*taskResult
+
+	for iteration, result := range options.Results {
+		for idx, task := range result.Tasks {
+			results = append(results, &taskResult{
+				Iteration:          iteration + 1,
+				Task:               idx + 1,
+				Archive:            task.Archive,
+				Repository:         task.Repository,
+				Start:              formatTime(task.Start),
+				End:                formatTime(task.End),
+				Duration:           format.Duration(task.Duration),
+				AIN:                fmt.Sprint(task.AIN),
+				ADS:                format.Bytes(task.ADS),
+				AvgTransferRateADS: format.Bytes(task.AvgTransferRateADS()),
+			})
+		}
+	}
+
+	return results
+}
+
+// String returns a string representation of the 'Tasks' component which will be output in the report.
+func (t Tasks) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Tasks\n| -----")
+	fmt.Fprintf(writer, "| Iteration\t Task\t Archive\t Repository\t Start\t End\t Duration\t Items (AIN)\t "+
+		"Size (ADS)\t Transfer Rate (ADS)\t\n")
+
+	for _, result := range t {
+		fmt.Fprintf(writer, "| %d\t %d\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s/s\t\n",
+			result.Iteration,
+			result.Task,
+			result.Archive,
+			result.Repository,
+			result.Start,
+			result.End,
+			result.Duration,
+			result.AIN,
+			result.ADS,
+			result.AvgTransferRateADS)
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
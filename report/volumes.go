@@ -0,0 +1,53 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+)
+
+// Volumes is a component which displays the characteristics of the AWS EBS volumes backing the cluster/backup client.
+type Volumes []*value.Volume
+
+// String returns a string representation of the 'Volumes' component which will be output in the report.
+func (v Volumes) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Volumes\n| -------")
+	fmt.Fprintf(writer, "| Host\t Path\t ID\t Type\t Size\t IOPS\t Throughput\t\n")
+
+	for _, volume := range v {
+		fmt.Fprintf(writer, "| %s\t %s\t %s\t %s\t %dGiB\t %d\t %dMiB/s\t\n",
+			volume.Host,
+			volume.Path,
+			volume.ID,
+			volume.Type,
+			volume.Size,
+			volume.IOPS,
+			volume.Throughput)
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
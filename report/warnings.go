@@ -0,0 +1,65 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"github.com/jamesl33/cbtools-autobench/value"
+)
+
+// Warnings is a component which displays any caveats which should be considered when interpreting the results, for
+// example, the backup client being co-located with a cluster node.
+type Warnings []string
+
+// NewWarnings creates a new 'Warnings' component with the provided options.
+func NewWarnings(options Options) Warnings {
+	var warnings Warnings
+
+	if options.Blueprint != nil {
+		if node := options.Blueprint.Colocated(); node != nil {
+			warnings = append(warnings, fmt.Sprintf("Backup client is co-located with cluster node '%s', results "+
+				"include contention with Couchbase Server", node.Host))
+		}
+	}
+
+	// The cluster is only throttled whilst restoring
+	restored := slices.ContainsFunc(options.Modes, func(mode string) bool {
+		return mode == value.BenchmarkRestore || mode == value.BenchmarkRestoreRange
+	})
+
+	if config := options.Config; restored && config != nil && config.BenchmarkConfig != nil &&
+		config.BenchmarkConfig.Throttle != nil && config.BenchmarkConfig.Throttle.WriterThreads != 0 {
+		warnings = append(warnings, fmt.Sprintf("Cluster was throttled to %d KV writer thread(s) whilst restoring",
+			config.BenchmarkConfig.Throttle.WriterThreads))
+	}
+
+	return warnings
+}
+
+// String returns a string representation of the 'Warnings' component which will be output in the report.
+func (w Warnings) String() string {
+	buffer := &bytes.Buffer{}
+
+	fmt.Fprintln(buffer, "| Warnings\n| --------")
+
+	for _, warning := range w {
+		fmt.Fprintf(buffer, "| %s\n", warning)
+	}
+
+	return buffer.String()
+}
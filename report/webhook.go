@@ -0,0 +1,51 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WebhookSink sends the JSON report to a remote endpoint using a POST request.
+type WebhookSink struct {
+	URL string
+}
+
+// Write implements the 'Sink' interface.
+func (w *WebhookSink) Write(report *Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal report")
+	}
+
+	client := &http.Client{Timeout: time.Minute}
+
+	resp, err := client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to send request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// aliases maps the supported non-standard cron macros to their standard equivalent.
+var aliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+// bounds represents the inclusive range of values accepted by a cron field.
+type bounds struct {
+	min, max int
+}
+
+var (
+	minutes  = bounds{0, 59}
+	hours    = bounds{0, 23}
+	days     = bounds{1, 31}
+	months   = bounds{1, 12}
+	weekdays = bounds{0, 7}
+)
+
+// Cron is a parsed standard five field cron expression (minute, hour, day of month, month and day of week).
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+
+	// domStar/dowStar track whether the day fields were unrestricted; when both day fields are restricted a time only
+	// needs to match one of them (which is the standard cron behavior).
+	domStar, dowStar bool
+}
+
+// ParseCron parses the given cron expression, the macros '@hourly', '@daily', '@midnight', '@weekly', '@monthly' and
+// '@yearly' are also supported.
+func ParseCron(expr string) (*Cron, error) {
+	if alias, ok := aliases[strings.TrimSpace(expr)]; ok {
+		expr = alias
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("expected 5 fields in cron expression '%s', got %d", expr, len(fields))
+	}
+
+	var (
+		cron = &Cron{domStar: fields[2] == "*", dowStar: fields[4] == "*"}
+		err  error
+	)
+
+	for idx, field := range []struct {
+		dst *uint64
+		b   bounds
+	}{{&cron.minute, minutes}, {&cron.hour, hours}, {&cron.dom, days}, {&cron.month, months}, {&cron.dow, weekdays}} {
+		*field.dst, err = parseField(fields[idx], field.b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid field '%s'", fields[idx])
+		}
+	}
+
+	// Sunday may be represented as either zero or seven
+	if cron.dow&(1<<7) != 0 {
+		cron.dow |= 1
+	}
+
+	return cron, nil
+}
+
+// Next returns the next time after the provided time which matches the cron expression. A zero time is returned if no
+// matching time could be found within the next five years e.g. for the 30th of February.
+func (c *Cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches returns a boolean indicating whether the day of the given time matches the cron expression.
+func (c *Cron) dayMatches(t time.Time) bool {
+	var (
+		dom = c.dom&(1<<uint(t.Day())) != 0
+		dow = c.dow&(1<<uint(t.Weekday())) != 0
+	)
+
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// parseField parses a single cron field returning a bitset of the matching values. Supports wildcards, lists, ranges
+// and steps e.g. '*/15', '1-5' and '0,30'.
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		start, end, step := b.min, b.max, 1
+
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		if hasStep {
+			var err error
+
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step <= 0 {
+				return 0, errors.Errorf("invalid step '%s'", stepStr)
+			}
+		}
+
+		if rng != "*" {
+			lower, upper, isRange := strings.Cut(rng, "-")
+
+			var err error
+
+			start, err = strconv.Atoi(lower)
+			if err != nil {
+				return 0, errors.Errorf("invalid value '%s'", lower)
+			}
+
+			end = start
+
+			if isRange {
+				end, err = strconv.Atoi(upper)
+				if err != nil {
+					return 0, errors.Errorf("invalid value '%s'", upper)
+				}
+			} else if hasStep {
+				end = b.max
+			}
+		}
+
+		if start < b.min || end > b.max || start > end {
+			return 0, errors.Errorf("value out of range [%d, %d]", b.min, b.max)
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"time"
+
+	fsutil "github.com/couchbase/tools-common/fs/util"
+	"github.com/jamesl33/cbtools-autobench/report"
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+)
+
+// Options encapsulates the options which may be passed into the 'NewScheduler' function.
+type Options struct {
+	// Config is the schedule which will be followed.
+	Config *value.ScheduleConfig
+
+	// Run is the function used to run a benchmark suite.
+	Run func(ctx context.Context, suite *value.SuiteSchedule) (*report.Report, error)
+
+	// Sinks are any additional sinks which the report from each successful run will be written to.
+	Sinks []report.Sink
+}
+
+// entry is a suite which has been scheduled to run at a given time.
+type entry struct {
+	suite *value.SuiteSchedule
+	cron  *Cron
+	next  time.Time
+}
+
+// notification is the payload sent to the notification endpoint upon completion of a scheduled run.
+type notification struct {
+	Suite    *value.SuiteSchedule `json:"suite"`
+	Status   string               `json:"status"`
+	Error    string               `json:"error,omitempty"`
+	Started  time.Time            `json:"started"`
+	Finished time.Time            `json:"finished"`
+	Report   *report.Report       `json:"report,omitempty"`
+}
+
+// Scheduler runs benchmark suites whenever their cron expressions match, runs are performed sequentially since suites
+// are likely to be using the same cluster/backup client.
+type Scheduler struct {
+	options Options
+	entries []*entry
+}
+
+// NewScheduler creates a new scheduler, returning an error if any of the provided suites are invalid.
+func NewScheduler(options Options) (*Scheduler, error) {
+	if len(options.Config.Suites) == 0 {
+		return nil, errors.New("no suites have been scheduled")
+	}
+
+	entries := make([]*entry, 0, len(options.Config.Suites))
+
+	for _, suite := range options.Config.Suites {
+		cron, err := ParseCron(suite.Cron)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse cron expression for suite '%s'", suite.Name)
+		}
+
+		for idx, step := range suite.Entries() {
+			err = validateStep(step)
+			if err != nil {
+				return nil, errors.Wrapf(err, "suite '%s' has invalid step %d", suite.Name, idx+1)
+			}
+		}
+
+		entries = append(entries, &entry{suite: suite, cron: cron})
+	}
+
+	return &Scheduler{options: options, entries: entries}, nil
+}
+
+// validateStep returns an error if the given step doesn't either run a valid benchmark or change the topology.
+func validateStep(step *value.SuiteStep) error {
+	if step.Topology != nil {
+		if step.Benchmark != "" {
+			return errors.New("steps may either run a benchmark or change the topology, not both")
+		}
+
+		return nil
+	}
+
+	if !slices.Contains(value.BenchmarkTypes, step.Benchmark) {
+		return errors.Errorf("unknown/unsupported benchmark '%s'", step.Benchmark)
+	}
+
+	return nil
+}
+
+// Run the scheduler until the provided context is cancelled.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if s.options.Config.HistoryPath != "" {
+		err := fsutil.Mkdir(s.options.Config.HistoryPath, 0, true, true)
+		if err != nil {
+			return errors.Wrap(err, "failed to create history directory")
+		}
+	}
+
+	now := time.Now()
+	for _, e := range s.entries {
+		e.next = e.cron.Next(now)
+	}
+
+	for {
+		next := s.nextEntry()
+		if next == nil {
+			return errors.New("none of the scheduled suites will run again")
+		}
+
+		log.WithFields(log.Fields{"suite": next.suite.Name, "at": next.next}).Info("Waiting for next scheduled run")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Until(next.next)):
+		}
+
+		s.run(ctx, next)
+
+		next.next = next.cron.Next(time.Now())
+	}
+}
+
+// nextEntry returns the entry which is due to run soonest, returns nil if no entries will run again.
+func (s *Scheduler) nextEntry() *entry {
+	var next *entry
+
+	for _, e := range s.entries {
+		if e.next.IsZero() {
+			continue
+		}
+
+		if next == nil || e.next.Before(next.next) {
+			next = e
+		}
+	}
+
+	return next
+}
+
+// run the suite for the given entry, storing the results and sending a notification upon completion. Failures are
+// logged rather than returned so that a single failed run doesn't stop any future runs.
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	fields := log.Fields{"suite": e.suite.Name, "benchmark": e.suite.Benchmark}
+	log.WithFields(fields).Info("Running scheduled suite")
+
+	payload := &notification{Suite: e.suite, Status: "succeeded", Started: time.Now()}
+
+	result, err := s.options.Run(ctx, e.suite)
+	if err == nil {
+		err = s.store(e.suite, payload.Started, result)
+	}
+
+	payload.Finished = time.Now()
+	payload.Report = result
+
+	if err != nil {
+		log.WithFields(fields).Errorf("Scheduled suite failed: %s", err)
+
+		payload.Status = "failed"
+		payload.Error = err.Error()
+	}
+
+	err = s.notify(payload)
+	if err != nil {
+		log.WithFields(fields).Errorf("Failed to send notification: %s", err)
+	}
+}
+
+// store writes the given report to the history directory and any additional sinks.
+func (s *Scheduler) store(suite *value.SuiteSchedule, started time.Time, result *report.Report) error {
+	sinks := s.options.Sinks
+
+	if s.options.Config.HistoryPath != "" {
+		path := filepath.Join(s.options.Config.HistoryPath,
+			fmt.Sprintf("%s-%s.json", suite.Name, started.UTC().Format("20060102T150405Z")))
+
+		sinks = append([]report.Sink{&report.JSONSink{Path: path}}, sinks...)
+	}
+
+	for _, sink := range sinks {
+		err := sink.Write(result)
+		if err != nil {
+			return errors.Wrap(err, "failed to write report")
+		}
+	}
+
+	return nil
+}
+
+// notify sends the given payload to the notification endpoint, this is a no-op if no endpoint is configured.
+func (s *Scheduler) notify(payload *notification) error {
+	if s.options.Config.NotifyURL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal notification")
+	}
+
+	client := &http.Client{Timeout: time.Minute}
+
+	resp, err := client.Post(s.options.Config.NotifyURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to send request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/jamesl33/cbtools-autobench/report"
+	"github.com/jamesl33/cbtools-autobench/value"
+)
+
+// JobType represents the type of work which will be performed by a job.
+type JobType string
+
+const (
+	// JobProvision provisions the cluster/backup client and loads the test dataset.
+	JobProvision JobType = "provision"
+
+	// JobBenchmark runs one or more benchmarks against an already provisioned cluster.
+	JobBenchmark JobType = "benchmark"
+)
+
+// JobStatus represents the current state of a job.
+type JobStatus string
+
+const (
+	// JobQueued indicates that the job is waiting for previously submitted jobs to complete.
+	JobQueued JobStatus = "queued"
+
+	// JobRunning indicates that the job is currently running.
+	JobRunning JobStatus = "running"
+
+	// JobSucceeded indicates that the job completed successfully.
+	JobSucceeded JobStatus = "succeeded"
+
+	// JobFailed indicates that the job returned an error.
+	JobFailed JobStatus = "failed"
+)
+
+// Job is a unit of work submitted via the REST API, jobs are run sequentially since they're likely to be using the same
+// cluster/backup client.
+type Job struct {
+	ID       string    `json:"id"`
+	Type     JobType   `json:"type"`
+	Mode     string    `json:"mode,omitempty"`
+	LoadOnly bool      `json:"load_only,omitempty"`
+	ConfigID string    `json:"config"`
+	Status   JobStatus `json:"status"`
+	Error    string    `json:"error,omitempty"`
+	Created  time.Time `json:"created"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+
+	config   *value.AutobenchConfig
+	report   *report.Report
+	progress bytes.Buffer
+}
+
+// done returns a boolean indicating whether the job has finished running.
+func (j *Job) done() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
@@ -0,0 +1,407 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jamesl33/cbtools-autobench/report"
+	"github.com/jamesl33/cbtools-autobench/value"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Options encapsulates the options which may be passed into the 'NewServer' function.
+type Options struct {
+	// Address is the address which the REST API will listen on.
+	Address string
+
+	// Token, when provided, must be supplied as a bearer token in the 'Authorization' header of every request.
+	Token string
+
+	// Provision is the function used to run provisioning jobs.
+	Provision func(ctx context.Context, config *value.AutobenchConfig, loadOnly bool) error
+
+	// Benchmark is the function used to run benchmarking jobs.
+	Benchmark func(ctx context.Context, config *value.AutobenchConfig, mode string) (*report.Report, error)
+}
+
+// Server exposes a REST API which can be used to submit configs, start provision/benchmark jobs, stream their progress
+// and fetch the resulting reports.
+type Server struct {
+	options Options
+
+	mu      sync.Mutex
+	nextID  uint64
+	configs map[string]*value.AutobenchConfig
+	jobs    map[string]*Job
+	order   []*Job
+	current *Job
+	queue   chan *Job
+}
+
+// NewServer creates a new server using the provided options.
+func NewServer(options Options) *Server {
+	return &Server{
+		options: options,
+		configs: make(map[string]*value.AutobenchConfig),
+		jobs:    make(map[string]*Job),
+		order:   make([]*Job, 0),
+		queue:   make(chan *Job, 128),
+	}
+}
+
+// Write implements the 'io.Writer' interface, any data written will be appended to the progress of the running job;
+// this allows the server to be used as the output for the logging handler.
+func (s *Server) Write(data []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current == nil {
+		return len(data), nil
+	}
+
+	return s.current.progress.Write(data)
+}
+
+// Serve runs the REST API until the provided context is cancelled.
+func (s *Server) Serve(ctx context.Context) error {
+	go s.worker(ctx)
+
+	server := &http.Server{
+		Addr:              s.options.Address,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: time.Minute,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.WithField("address", s.options.Address).Info("Serving REST API")
+
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// handler returns the handler which routes requests to the REST API endpoints.
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /configs", s.handleSubmitConfig)
+	mux.HandleFunc("POST /jobs", s.handleSubmitJob)
+	mux.HandleFunc("GET /jobs", s.handleListJobs)
+	mux.HandleFunc("GET /jobs/{id}", s.handleGetJob)
+	mux.HandleFunc("GET /jobs/{id}/progress", s.handleJobProgress)
+	mux.HandleFunc("GET /jobs/{id}/report", s.handleJobReport)
+	mux.HandleFunc("GET /schema", s.handleSchema)
+
+	return s.authenticate(mux)
+}
+
+// authenticate wraps the given handler, rejecting any requests which don't provide the configured bearer token.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.options.Token == "" {
+		return next
+	}
+
+	expected := []byte("Bearer " + s.options.Token)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// worker runs the queued jobs sequentially until the provided context is cancelled.
+func (s *Server) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-s.queue:
+			s.run(ctx, job)
+		}
+	}
+}
+
+// run the given job, updating its status upon completion.
+func (s *Server) run(ctx context.Context, job *Job) {
+	s.mu.Lock()
+	s.current = job
+	job.Status = JobRunning
+	job.Started = time.Now()
+	s.mu.Unlock()
+
+	fields := log.Fields{"id": job.ID, "type": job.Type, "mode": job.Mode}
+	log.WithFields(fields).Info("Running job")
+
+	var (
+		result *report.Report
+		err    error
+	)
+
+	switch job.Type {
+	case JobProvision:
+		err = s.options.Provision(ctx, job.config, job.LoadOnly)
+	case JobBenchmark:
+		result, err = s.options.Benchmark(ctx, job.config, job.Mode)
+	}
+
+	if err != nil {
+		log.WithFields(fields).Errorf("Job failed: %s", err)
+	} else {
+		log.WithFields(fields).Info("Job completed successfully")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.current = nil
+	job.report = result
+	job.Finished = time.Now()
+	job.Status = JobSucceeded
+
+	if err != nil {
+		job.Status = JobFailed
+		job.Error = err.Error()
+	}
+}
+
+// handleSubmitConfig decodes and stores the YAML config in the request body, returning an identifier which may be used
+// when submitting jobs.
+func (s *Server) handleSubmitConfig(w http.ResponseWriter, r *http.Request) {
+	var config *value.AutobenchConfig
+
+	err := yaml.NewDecoder(r.Body).Decode(&config)
+	if err != nil || config == nil {
+		http.Error(w, "failed to decode config", http.StatusBadRequest)
+		return
+	}
+
+	if config.BenchmarkConfig != nil && config.BenchmarkConfig.CBMConfig != nil {
+		err = config.BenchmarkConfig.CBMConfig.ResolveCredentials()
+		if err != nil {
+			http.Error(w, "failed to resolve AWS credentials: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	id := s.newID()
+	s.configs[id] = config
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+// handleSubmitJob queues a new job using a previously submitted config.
+func (s *Server) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Type     JobType `json:"type"`
+		Mode     string  `json:"mode"`
+		LoadOnly bool    `json:"load_only"`
+		ConfigID string  `json:"config"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "failed to decode request", http.StatusBadRequest)
+		return
+	}
+
+	if request.Type != JobProvision && request.Type != JobBenchmark {
+		http.Error(w, "unknown/unsupported job type", http.StatusBadRequest)
+		return
+	}
+
+	if request.Type == JobBenchmark && !slices.Contains(value.BenchmarkTypes, request.Mode) {
+		http.Error(w, "unknown/unsupported benchmark mode", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, ok := s.configs[request.ConfigID]
+	if !ok {
+		http.Error(w, "unknown config", http.StatusNotFound)
+		return
+	}
+
+	job := &Job{
+		ID:       s.newID(),
+		Type:     request.Type,
+		Mode:     request.Mode,
+		LoadOnly: request.LoadOnly,
+		ConfigID: request.ConfigID,
+		Status:   JobQueued,
+		Created:  time.Now(),
+		config:   config,
+	}
+
+	select {
+	case s.queue <- job:
+	default:
+		http.Error(w, "job queue is full", http.StatusServiceUnavailable)
+		return
+	}
+
+	s.jobs[job.ID] = job
+	s.order = append(s.order, job)
+
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// handleListJobs returns all the submitted jobs in the order they were submitted.
+func (s *Server) handleListJobs(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, s.order)
+}
+
+// handleGetJob returns the job with the requested identifier.
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[r.PathValue("id")]
+	if !ok {
+		http.Error(w, "unknown job", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleJobProgress streams the log output of the requested job, the response will be completed once the job has
+// finished.
+func (s *Server) handleJobProgress(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	job, ok := s.jobs[r.PathValue("id")]
+	s.mu.Unlock()
+
+	if !ok {
+		http.Error(w, "unknown job", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	flusher, _ := w.(http.Flusher)
+
+	var offset int
+
+	for {
+		s.mu.Lock()
+		data := append([]byte(nil), job.progress.Bytes()[offset:]...)
+		done := job.done()
+		s.mu.Unlock()
+
+		offset += len(data)
+
+		_, err := w.Write(data)
+		if err != nil {
+			return
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if done {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// handleJobReport returns the JSON report generated by the requested benchmarking job.
+func (s *Server) handleJobReport(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[r.PathValue("id")]
+	if !ok {
+		http.Error(w, "unknown job", http.StatusNotFound)
+		return
+	}
+
+	if job.report == nil {
+		http.Error(w, "report not available", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, job.report)
+}
+
+// handleSchema returns the JSON schema which describes the reports returned by the API.
+func (s *Server) handleSchema(w http.ResponseWriter, _ *http.Request) {
+	schema, err := report.Schema()
+	if err != nil {
+		http.Error(w, "failed to generate schema", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, json.RawMessage(schema))
+}
+
+// newID returns a new unique identifier, note that the caller must be holding the lock.
+func (s *Server) newID() string {
+	s.nextID++
+	return strconv.FormatUint(s.nextID, 10)
+}
+
+// writeJSON is a utility function which writes the JSON representation of the given data as the response.
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		http.Error(w, "failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(encoded)
+}
@@ -34,11 +34,15 @@ import (
 // up/performing benchmarks.
 type Client struct {
 	client   *ssh.Client
-	Platform value.Platform
+	platform value.Platform
+
+	// binDirectory is the Couchbase Server bin directory, which is added to the 'PATH' when executing commands.
+	binDirectory string
 }
 
-// NewClient creates a new client which is connected to the provided host.
-func NewClient(host string, config *value.SSHConfig) (*Client, error) {
+// NewClient creates a new client which is connected to the provided host, commands are executed with the given
+// Couchbase Server bin directory in their 'PATH'.
+func NewClient(host string, config *value.SSHConfig, binDirectory string) (*Client, error) {
 	log.WithField("host", host).Info("Establishing ssh connection")
 
 	signer, err := parsePrivateKey(config.PrivateKey, config.PrivateKeyPassphrase)
@@ -46,7 +50,7 @@ func NewClient(host string, config *value.SSHConfig) (*Client, error) {
 		return nil, errors.Wrap(err, "failed to parse private key")
 	}
 
-	client, err := ssh.Dial("tcp", fmt.Sprintf("%s:%d", host, 22), &ssh.ClientConfig{
+	client, err := ssh.Dial("tcp", fmt.Sprintf("%s:%d", host, config.GetPort()), &ssh.ClientConfig{
 		User:            config.Username,
 		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
 		HostKeyCallback: func(_ string, _ net.Addr, _ ssh.PublicKey) error { return nil },
@@ -64,8 +68,9 @@ func NewClient(host string, config *value.SSHConfig) (*Client, error) {
 	log.WithFields(fields).Info("Successfully established ssh connection")
 
 	return &Client{
-		Platform: platform,
-		client:   client,
+		platform:     platform,
+		client:       client,
+		binDirectory: binDirectory,
 	}, nil
 }
 
@@ -144,61 +149,23 @@ func (c *Client) SecureDownload(source, sink string) error {
 	return session.Wait()
 }
 
-// InstallPackageAt installs the package at the provided path on the remote machine.
-func (c *Client) InstallPackageAt(path string) error {
-	_, err := c.ExecuteCommand(c.Platform.CommandInstallPackageAt(path))
-	return err
-}
-
-// InstallPackages uses the platform specific package manager to install the given package.
-func (c *Client) InstallPackages(packages ...string) error {
-	_, err := c.ExecuteCommand(c.Platform.CommandInstallPackages(packages...))
-	return err
-}
-
-// UninstallPackages uses the platform specific package manager to uninstall the given package.
-func (c *Client) UninstallPackages(packages ...string) error {
-	_, err := c.ExecuteCommand(c.Platform.CommandUninstallPackages(packages...))
-	return err
-}
-
-// FileExists returns a boolean indicating whether a file with the given path exists on the remote machine.
-func (c *Client) FileExists(path string) bool {
-	_, err := c.ExecuteCommand(value.NewCommand("test -e %s", path))
-	return err == nil
-}
-
-// RemoveFile removes the file at the given path on the remote machine.
-func (c *Client) RemoveFile(path string) error {
-	_, err := c.ExecuteCommand(value.NewCommand("rm %s", path))
-	return err
-}
-
-// RemoveDirectory removes the directory at the given path on the remote machine.
-func (c *Client) RemoveDirectory(path string) error {
-	_, err := c.ExecuteCommand(value.NewCommand("rm -rf %s", path))
-	return err
-}
-
-// Sync runs 'sync' on the remote machine ensuring all dirty package are written to disk.
-func (c *Client) Sync() error {
-	_, err := c.ExecuteCommand(value.NewCommand("sync"))
-	return err
-}
-
-// FlushCaches sync then flushes the caches on the remote machine; this allows for more consistent benchmark results.
-func (c *Client) FlushCaches() error {
-	_, err := c.ExecuteCommand(value.NewCommand("sync; echo 3 > /proc/sys/vm/drop_caches"))
-	return err
+// Platform returns the platform of the remote machine.
+func (c *Client) Platform() value.Platform {
+	return c.platform
 }
 
 // ExecuteCommand is a wrapper with executes the given command on the remote machine.
 func (c *Client) ExecuteCommand(command value.Command) ([]byte, error) {
 	return executeCommand(c.client, command.ToString(map[string]string{
-		"PATH": fmt.Sprintf("%s:$PATH", value.CBBinDirectory),
+		"PATH": fmt.Sprintf("%s:$PATH", c.binDirectory),
 	}))
 }
 
+// Dial connects to the given address from the remote server, the connection is tunnelled over ssh.
+func (c *Client) Dial(network, address string) (net.Conn, error) {
+	return c.client.Dial(network, address)
+}
+
 // Close releases an resources in use by this client.
 func (c *Client) Close() error {
 	return c.client.Close()
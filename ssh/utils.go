@@ -15,7 +15,6 @@
 package ssh
 
 import (
-	"bytes"
 	"os"
 	"strings"
 
@@ -76,51 +75,20 @@ func executeCommand(client *ssh.Client, command string) ([]byte, error) {
 
 // determinePlatform uses the provided ssh client to determine which platform it's connected too.
 func determinePlatform(client *ssh.Client) (value.Platform, error) {
-	command := value.NewCommand("cat /etc/os-release | grep '^ID=' | cut -c4-")
-
-	distro, err := executeCommand(client, command.ToString(nil))
+	distro, err := executeCommand(client, value.CommandDistro.ToString(nil))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to determine distribution")
 	}
 
-	command = value.NewCommand("cat /etc/os-release | grep '^VERSION_ID=' | cut -c13- | rev | cut -c2- | rev")
-
-	release, err := executeCommand(client, command.ToString(nil))
+	like, err := executeCommand(client, value.CommandDistroLike.ToString(nil))
 	if err != nil {
-		return "", errors.Wrap(err, "failed to determine version")
-	}
-
-	// Do some cleanup since we don't always get uniform output
-	distro = bytes.TrimSpace(distro)
-	distro = bytes.TrimPrefix(distro, []byte{'"'})
-	distro = bytes.TrimSuffix(distro, []byte{'"'})
-
-	switch string(distro) {
-	case "ubuntu":
-		return determineUbuntuPlatform(strings.TrimSpace(string(release)))
-	case "amzn":
-		return determineAmazonLinuxPlatform(strings.TrimSpace(string(release)))
-	}
-
-	return "", errors.Errorf("unsupported distro '%s'", strings.TrimSpace(string(distro)))
-}
-
-// determineUbuntuPlatform returns the specific platform for the given Ubuntu release.
-func determineUbuntuPlatform(release string) (value.Platform, error) {
-	switch release {
-	case "20.04":
-		return value.PlatformUbuntu20_04, nil
+		return "", errors.Wrap(err, "failed to determine parent distributions")
 	}
 
-	return "", errors.Errorf("unsupported ubuntu release '%s'", release)
-}
-
-// determineAmazonLinuxPlatform returns the specific platform for the given Amazon Linux release.
-func determineAmazonLinuxPlatform(release string) (value.Platform, error) {
-	switch release {
-	case "2", "2023":
-		return value.PlatformAmazonLinux2, nil
+	release, err := executeCommand(client, value.CommandRelease.ToString(nil))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to determine version")
 	}
 
-	return "", errors.Errorf("unsupported amazon linux release '%s'", release)
+	return value.NewPlatform(string(distro), string(like), string(release))
 }
@@ -43,8 +43,13 @@ type LoggingHandler struct {
 
 // NewLoggingHandler creates a new LoggingHandler which will log to stdout.
 func NewLoggingHandler() *LoggingHandler {
+	return NewWriterLoggingHandler(os.Stdout)
+}
+
+// NewWriterLoggingHandler creates a new LoggingHandler which will log to the provided writer.
+func NewWriterLoggingHandler(writer io.Writer) *LoggingHandler {
 	return &LoggingHandler{
-		writer: os.Stdout,
+		writer: writer,
 	}
 }
 
@@ -0,0 +1,68 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utilities
+
+import (
+	"runtime/debug"
+)
+
+// These are populated at build time using '-ldflags', see the 'build' target in the Makefile.
+var (
+	// Version is the version of cbtools-autobench e.g. the tag which was built.
+	Version string
+
+	// Commit is the hash of the commit which was built.
+	Commit string
+)
+
+// BuildVersion returns the version/commit of cbtools-autobench. When they weren't populated at build time, the commit
+// is taken from the VCS information embedded by the Go toolchain (suffixed with '-dirty' for modified trees).
+func BuildVersion() (string, string) {
+	version, commit := Version, Commit
+
+	if version == "" {
+		version = "unknown"
+	}
+
+	if commit != "" {
+		return version, commit
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return version, "unknown"
+	}
+
+	var modified bool
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			commit = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+
+	if commit == "" {
+		return version, "unknown"
+	}
+
+	if modified {
+		commit += "-dirty"
+	}
+
+	return version, commit
+}
@@ -0,0 +1,73 @@
+// Copyright 2022 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/couchbase/tools-common/strings/format"
+)
+
+// ArchiveSummary is a summary of the contents of an archive, used to sanity check the state of the archive between
+// benchmark runs.
+type ArchiveSummary struct {
+	Archive      string               `json:"archive"`
+	Repositories []*RepositorySummary `json:"repositories"`
+}
+
+// RepositorySummary is a summary of a single repository in an archive.
+type RepositorySummary struct {
+	Name    string           `json:"name"`
+	Size    uint64           `json:"size"`
+	Backups []*BackupDetails `json:"backups"`
+}
+
+// String returns a human readable representation of the archive summary.
+func (a *ArchiveSummary) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintf(buffer, "| Archive (%s)\n| -------\n", a.Archive)
+
+	if len(a.Repositories) == 0 {
+		fmt.Fprintln(buffer, "| The archive does not contain any repositories")
+		return strings.TrimSpace(buffer.String())
+	}
+
+	fmt.Fprintf(writer, "| Repository\t Backup\t Type\t Size\t Items\t\n")
+
+	for _, repository := range a.Repositories {
+		fmt.Fprintf(writer, "| %s\t %d backup(s)\t \t %s\t \t\n", repository.Name, len(repository.Backups),
+			format.Bytes(repository.Size))
+
+		for _, backup := range repository.Backups {
+			var items uint64
+			for _, bucket := range backup.Buckets {
+				items += bucket.Items
+			}
+
+			fmt.Fprintf(writer, "| \t %s\t %s\t %s\t %d\t\n", backup.Date, backup.Type, format.Bytes(backup.Size), items)
+		}
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ResolveCredentials populates the object store credentials using the configured AWS profile, then assumes the
+// configured role (if any) replacing them with temporary credentials.
+func (c *CBMConfig) ResolveCredentials() error {
+	err := c.ResolveProfile()
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve profile")
+	}
+
+	err = c.AssumeRole()
+	if err != nil {
+		return errors.Wrap(err, "failed to assume role")
+	}
+
+	return nil
+}
+
+// AssumeRole uses the 'aws' cli on the machine running autobench to assume the configured role, replacing the object
+// store credentials with the returned temporary credentials. Any existing credentials are used to assume the role,
+// otherwise the default credentials chain is used.
+func (c *CBMConfig) AssumeRole() error {
+	if c.ObjRoleARN == "" {
+		return nil
+	}
+
+	args := []string{
+		"sts", "assume-role", "--output", "json", "--role-arn", c.ObjRoleARN, "--role-session-name", "cbtools-autobench",
+	}
+
+	if c.ObjRoleDurationSeconds != 0 {
+		args = append(args, "--duration-seconds", strconv.Itoa(c.ObjRoleDurationSeconds))
+	}
+
+	if c.ObjRegion != "" {
+		args = append(args, "--region", c.ObjRegion)
+	}
+
+	command := exec.Command("aws", args...)
+	command.Env = os.Environ()
+
+	if c.ObjAccessKeyID != "" {
+		command.Env = append(command.Env,
+			"AWS_ACCESS_KEY_ID="+c.ObjAccessKeyID,
+			"AWS_SECRET_ACCESS_KEY="+c.ObjSecretAccessKey,
+			"AWS_SESSION_TOKEN="+c.ObjSessionToken,
+		)
+	}
+
+	output, err := command.Output()
+	if err != nil {
+		return errors.Wrapf(err, "failed to run 'aws sts assume-role'")
+	}
+
+	var decoded struct {
+		Credentials struct {
+			AccessKeyID     string `json:"AccessKeyId"`
+			SecretAccessKey string `json:"SecretAccessKey"`
+			SessionToken    string `json:"SessionToken"`
+		} `json:"Credentials"`
+	}
+
+	err = json.Unmarshal(output, &decoded)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode temporary credentials")
+	}
+
+	c.ObjAccessKeyID = decoded.Credentials.AccessKeyID
+	c.ObjSecretAccessKey = decoded.Credentials.SecretAccessKey
+	c.ObjSessionToken = decoded.Credentials.SessionToken
+
+	return nil
+}
+
+// ResolveProfile populates the object store credentials/region using the configured AWS profile, which is read from the
+// shared credentials/config files on the local machine. Any values explicitly set in the config take precedence.
+func (c *CBMConfig) ResolveProfile() error {
+	if c.ObjProfile == "" {
+		return nil
+	}
+
+	path, err := awsFilePath(c.ObjCredentialsFile, "AWS_SHARED_CREDENTIALS_FILE", "credentials")
+	if err != nil {
+		return err
+	}
+
+	credentials, err := readAWSProfile(path, c.ObjProfile)
+	if err != nil {
+		return errors.Wrap(err, "failed to read shared credentials file")
+	}
+
+	if credentials == nil || credentials["aws_access_key_id"] == "" || credentials["aws_secret_access_key"] == "" {
+		return errors.Errorf("profile '%s' does not contain credentials in '%s'", c.ObjProfile, path)
+	}
+
+	c.ObjAccessKeyID = cmp.Or(c.ObjAccessKeyID, credentials["aws_access_key_id"])
+	c.ObjSecretAccessKey = cmp.Or(c.ObjSecretAccessKey, credentials["aws_secret_access_key"])
+	c.ObjSessionToken = cmp.Or(c.ObjSessionToken, credentials["aws_session_token"])
+	c.ObjRegion = cmp.Or(c.ObjRegion, credentials["region"])
+
+	if c.ObjRegion != "" {
+		return nil
+	}
+
+	// The region is generally stored in the config file, where profiles other than the default are prefixed
+	path, err = awsFilePath("", "AWS_CONFIG_FILE", "config")
+	if err != nil {
+		return err
+	}
+
+	section := "profile " + c.ObjProfile
+	if c.ObjProfile == "default" {
+		section = "default"
+	}
+
+	config, err := readAWSProfile(path, section)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to read config file")
+	}
+
+	c.ObjRegion = config["region"]
+
+	return nil
+}
+
+// awsFilePath returns the path to the given shared AWS file, which may be overridden by the config or environment.
+func awsFilePath(path, env, name string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+
+	if path = os.Getenv(env); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to determine home directory")
+	}
+
+	return filepath.Join(home, ".aws", name), nil
+}
+
+// readAWSProfile returns the key/value pairs in the given section of the INI formatted AWS file at the given path,
+// returns nil if the section doesn't exist.
+func readAWSProfile(path, section string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		scanner = bufio.NewScanner(file)
+		values  map[string]string
+		current string
+	)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, val, ok := strings.Cut(line, "=")
+		if !ok || current != section {
+			continue
+		}
+
+		if values == nil {
+			values = make(map[string]string)
+		}
+
+		values[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+
+	return values, scanner.Err()
+}
@@ -28,21 +28,80 @@ type BackupClientBlueprint struct {
 	// Host is the hostname/address of the node
 	Host string `yaml:"host,omitempty"`
 
-	// PackagePath is the path to a local package. This package will be secure copied to the backup client and installed
-	// instead of downloading the build from latest builds.
+	// Local indicates that the backup client is the machine running 'cbtools-autobench', commands will be executed
+	// directly rather than via ssh.
+	Local bool `yaml:"local,omitempty"`
+
+	// SSH overrides the global ssh config when connecting to the backup client.
+	SSH *SSHConfig `yaml:"ssh,omitempty"`
+
+	// PackagePath is the path to a local package. This package will be secure copied to the backup client and installed;
+	// builds are never downloaded, so packages from a mirror/latest builds must be fetched beforehand.
 	//
 	// NOTE: No validation takes place to ensure the package is valid for the current distribution; that's on you...
 	PackagePath string `yaml:"package_path,omitempty"`
+
+	// DependenciesPath is the path to a local directory containing the dependency packages (e.g. awscli), these will be
+	// secure copied to the backup client and installed offline rather than using the package manager's repositories.
+	DependenciesPath string `yaml:"dependencies_path,omitempty"`
+
+	// CBPaths describes where Couchbase Server is installed on the backup client.
+	CBPaths `yaml:",inline"`
+
+	// Mount is the configuration for an NFS/EFS export which will be mounted on the backup client during provisioning,
+	// allowing benchmarking backups to a NAS.
+	Mount *MountBlueprint `yaml:"mount,omitempty"`
+
+	// Volumes describes the AWS EBS volumes which are expected to back paths on the backup client (e.g. the archive),
+	// their characteristics are validated during provisioning and recorded in the report.
+	Volumes []*VolumeBlueprint `yaml:"volumes,omitempty"`
+}
+
+// MountBlueprint encapsulates the configuration for a network filesystem which will be mounted on the backup client.
+//
+// NOTE: The archive should be a sub-directory of the mount path, since the archive directory is removed when purging.
+type MountBlueprint struct {
+	// Source is the export which will be mounted e.g. 'fs-12345678.efs.us-east-1.amazonaws.com:/'.
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+
+	// Path is the directory on the backup client where the export will be mounted.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Type is the filesystem type passed to 'mount', defaults to 'nfs4'.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Options is the comma separated list of mount options e.g. 'nfsvers=4.1,rsize=1048576,wsize=1048576'.
+	Options string `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// FSType returns the filesystem type which should be passed to 'mount'.
+func (m *MountBlueprint) FSType() string {
+	if m.Type != "" {
+		return m.Type
+	}
+
+	return "nfs4"
+}
+
+// backupClientBlueprintJSON is the JSON representation of the backup client blueprint which is included in the report.
+type backupClientBlueprintJSON struct {
+	Host    string          `json:"host,omitempty"`
+	Version string          `json:"version,omitempty"`
+	Mount   *MountBlueprint `json:"mount,omitempty"`
+}
+
+// JSONShape returns a value with the same shape as the JSON representation of the backup blueprint, used to generate
+// the schema of the report.
+func (b *BackupClientBlueprint) JSONShape() any {
+	return backupClientBlueprintJSON{}
 }
 
 // MarshalJSON returns a JSON representation of the backup blueprint which will be displayed in the report.
 func (b *BackupClientBlueprint) MarshalJSON() ([]byte, error) {
-	return json.Marshal(struct {
-		Host    string `json:"host,omitempty"`
-		Version string `json:"version,omitempty"`
-	}{
+	return json.Marshal(backupClientBlueprintJSON{
 		Host:    b.Host,
 		Version: extractBuild(b.PackagePath),
+		Mount:   b.Mount,
 	})
 }
 
@@ -59,5 +118,30 @@ func (b *BackupClientBlueprint) String() string {
 
 	_ = writer.Flush()
 
+	if b.Mount != nil {
+		fmt.Fprintf(buffer, "\n%s", b.Mount)
+	}
+
 	return strings.TrimSpace(buffer.String())
 }
+
+// String returns a human readable string representation of the mount which will be displayed in the report.
+func (m *MountBlueprint) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	options := "default"
+	if m.Options != "" {
+		options = m.Options
+	}
+
+	fmt.Fprintln(buffer, "| Mount\n| -----")
+	fmt.Fprintf(writer, "| Source\t Path\t Type\t Options\t\n")
+	fmt.Fprintf(writer, "| %s\t %s\t %s\t %s\t\n", m.Source, m.Path, m.FSType(), options)
+
+	_ = writer.Flush()
+
+	return buffer.String()
+}
@@ -15,9 +15,57 @@
 package value
 
 import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
 	"time"
 )
 
+const (
+	// BenchmarkBackup benchmarks creating a backup of the cluster.
+	BenchmarkBackup = "backup"
+
+	// BenchmarkRestore benchmarks restoring a backup to the cluster.
+	BenchmarkRestore = "restore"
+
+	// BenchmarkRestoreRange benchmarks restoring slices of a seeded chain of incremental backups to the cluster.
+	BenchmarkRestoreRange = "restore-range"
+
+	// BenchmarkInfo benchmarks scanning the metadata of the backups in the repository using the info sub-command.
+	BenchmarkInfo = "info"
+
+	// BenchmarkRemove benchmarks removing a backup (or a seeded chain of backups) using the remove sub-command.
+	BenchmarkRemove = "remove"
+)
+
+const (
+	// ArchiveDeviceModeRepeat repeats each benchmark once per archive device, allowing the devices to be compared.
+	ArchiveDeviceModeRepeat = "repeat"
+
+	// ArchiveDeviceModeStripe runs a backup to each archive device simultaneously, loading all the devices at once.
+	ArchiveDeviceModeStripe = "stripe"
+)
+
+const (
+	// ClusterLogsAll collects the cluster logs from every node in the cluster.
+	ClusterLogsAll = "all"
+
+	// ClusterLogsConnected only collects the cluster logs from the node which 'cbbackupmgr' connects to.
+	ClusterLogsConnected = "connected"
+
+	// ClusterLogsNone skips collecting the cluster logs, the 'cbbackupmgr' logs are still collected.
+	ClusterLogsNone = "none"
+)
+
+// EncryptionNone may be used when comparing encryption algorithms to benchmark an unencrypted repository.
+const EncryptionNone = "none"
+
+// BenchmarkTypes is the list of supported benchmarks.
+var BenchmarkTypes = []string{
+	BenchmarkBackup, BenchmarkRestore, BenchmarkRestoreRange, BenchmarkInfo, BenchmarkRemove,
+}
+
 // BenchmarkConfig encapsulates the configuration available for running benchmarks.
 type BenchmarkConfig struct {
 	// Iterations is the number of times a benchmark will be run, more iterations will result in more accurate data.
@@ -25,16 +73,480 @@ type BenchmarkConfig struct {
 
 	// CBMConfig is the configuration which will be passed to 'cbbackupmgr' when run on the remote machine.
 	CBMConfig *CBMConfig `json:"cbbackupmgr_config,omitempty" yaml:"cbbackupmgr_config,omitempty"`
+
+	// ConcurrentBackups is the number of backups which will be run simultaneously by the backup benchmark, each backup
+	// will be to a different repository. A zero value indicates that a single backup will be run.
+	ConcurrentBackups int `json:"concurrent_backups,omitempty" yaml:"concurrent_backups,omitempty"`
+
+	// Seed is the configuration used to pre-seed the archive with a chain of incremental backups prior to running
+	// benchmarks which operate on existing backups.
+	Seed *SeedConfig `json:"seed,omitempty" yaml:"seed,omitempty"`
+
+	// Deletions is the configuration used to delete a fraction of the dataset prior to running backup benchmarks, so
+	// that the backups are dominated by tombstones.
+	Deletions *DeletionConfig `json:"deletions,omitempty" yaml:"deletions,omitempty"`
+
+	// KeepArchive indicates that an existing archive/repository should be reused rather than purged at the start of the
+	// benchmark. Any backups already in the repository will be kept, with new backups being created on top of them;
+	// restore benchmarks only restore the backups they created (starting with a full backup).
+	KeepArchive bool `json:"keep_archive,omitempty" yaml:"keep_archive,omitempty"`
+
+	// DCPSampleInterval is the interval in seconds at which the DCP stats for the backup connection(s) are sampled during
+	// backup benchmarks, a zero value disables sampling.
+	DCPSampleInterval int `json:"dcp_sample_interval,omitempty" yaml:"dcp_sample_interval,omitempty"`
+
+	// RestoreIntoExisting indicates that restore benchmarks should restore into a bucket which already contains the data
+	// (rather than flushing it beforehand), which exercises the slower conflict resolution path in the cluster.
+	RestoreIntoExisting bool `json:"restore_into_existing,omitempty" yaml:"restore_into_existing,omitempty"`
+
+	// StagingTmpfsSize is the size of a tmpfs (e.g. '16G') which will be mounted at the obj staging directory for the
+	// duration of cloud benchmarks, isolating the object store throughput from the speed of the local staging disk.
+	StagingTmpfsSize string `json:"staging_tmpfs_size,omitempty" yaml:"staging_tmpfs_size,omitempty"`
+
+	// MinFreeSpacePercentage is the minimum percentage of free space on the archive/staging filesystems, benchmarks will
+	// be aborted early when it's crossed. A zero value disables the check.
+	MinFreeSpacePercentage int `json:"min_free_space_percentage,omitempty" yaml:"min_free_space_percentage,omitempty"`
+
+	// StallTimeout is the number of seconds after which a benchmark will be aborted if no progress has been observed
+	// i.e. the archive/staging directory and the 'cbbackupmgr' logs haven't changed. A zero value disables the check.
+	StallTimeout int `json:"stall_timeout,omitempty" yaml:"stall_timeout,omitempty"`
+
+	// NetworkPreflight indicates that the raw network throughput between the backup client and each cluster node should
+	// be measured (using 'iperf3') prior to benchmarking, so that transfer rates can be judged against the link speed.
+	NetworkPreflight bool `json:"network_preflight,omitempty" yaml:"network_preflight,omitempty"`
+
+	// LatencyPreflight indicates that the round trip time from the backup client to each cluster node (and between the
+	// cluster nodes) should be measured prior to benchmarking.
+	LatencyPreflight bool `json:"latency_preflight,omitempty" yaml:"latency_preflight,omitempty"`
+
+	// RestoreRange is the configuration used by the range restore benchmark.
+	RestoreRange *RestoreRangeConfig `json:"restore_range,omitempty" yaml:"restore_range,omitempty"`
+
+	// ArchiveDevices is a list of archive paths, one per physical device, which will be benchmarked according to the
+	// archive device mode; this takes precedence over the archive in the 'cbbackupmgr' config.
+	ArchiveDevices []string `json:"archive_devices,omitempty" yaml:"archive_devices,omitempty"`
+
+	// ArchiveDeviceMode determines how the archive devices are benchmarked i.e. repeat/stripe, defaults to repeat.
+	//
+	// NOTE: 'cbbackupmgr' is unable to split a single backup across multiple archives, therefore, striping is achieved
+	// by running a backup to each device simultaneously; it's only supported by backup benchmarks.
+	ArchiveDeviceMode string `json:"archive_device_mode,omitempty" yaml:"archive_device_mode,omitempty"`
+
+	// CompareStorage is a list of storage types which will be compared by running the same benchmark using each type,
+	// the first type is used as the baseline for the comparison.
+	CompareStorage []string `json:"compare_storage,omitempty" yaml:"compare_storage,omitempty"`
+
+	// CompareBlackhole indicates that each benchmark should be run both with and without the blackhole sink, allowing
+	// the cost of reading data from the cluster to be separated from the cost of writing it to the archive.
+	CompareBlackhole bool `json:"compare_blackhole,omitempty" yaml:"compare_blackhole,omitempty"`
+
+	// CompareDisable is a list of sets of data types which will be compared by running the same benchmark with each set
+	// excluded using the '--disable-*' flags, an empty set may be used to benchmark every data type.
+	CompareDisable [][]string `json:"compare_disable,omitempty" yaml:"compare_disable,omitempty"`
+
+	// CompareCompression is a list of bucket compression modes (i.e. off/passive/active) which will be compared by
+	// running the same benchmark after changing the compression mode of the benchmarking bucket.
+	CompareCompression []string `json:"compare_compression,omitempty" yaml:"compare_compression,omitempty"`
+
+	// CompareEncryption is a list of encryption algorithms (i.e. AES256GCM) which will be compared by running the same
+	// benchmark using an encrypted repository for each algorithm; 'none' may be used to include an unencrypted
+	// baseline, allowing the overhead of encryption to be measured.
+	CompareEncryption []string `json:"compare_encryption,omitempty" yaml:"compare_encryption,omitempty"`
+
+	// CompareReplaceTTL is a list of values passed to '--replace-ttl' (i.e. none/all/expired) which will be compared by
+	// running the same restore benchmark with each value, allowing the cost of rewriting expiries to be measured.
+	CompareReplaceTTL []string `json:"compare_replace_ttl,omitempty" yaml:"compare_replace_ttl,omitempty"`
+
+	// CompareThreads is a list of thread counts which will be swept by running the same benchmark with each value passed
+	// to '--threads', allowing the scaling of 'cbbackupmgr' to be measured.
+	CompareThreads []int `json:"compare_threads,omitempty" yaml:"compare_threads,omitempty"`
+
+	// CloudPricing is the pricing used to estimate the cost of each iteration when benchmarking using an object store,
+	// no estimate is included in the report when omitted.
+	CloudPricing *CloudPricing `json:"cloud_pricing,omitempty" yaml:"cloud_pricing,omitempty"`
+
+	// IterationLogs indicates whether the 'cbbackupmgr' logs directory should be downloaded after every iteration, so
+	// that the logs for each iteration survive the archive being purged. Requires a logs directory to be provided.
+	IterationLogs bool `json:"iteration_logs,omitempty" yaml:"iteration_logs,omitempty"`
+
+	// ExistingArchive indicates whether restore benchmarks should restore the backups in an existing (possibly
+	// externally created) archive/repository, skipping purging the archive and creating the repository/backups.
+	ExistingArchive bool `json:"existing_archive,omitempty" yaml:"existing_archive,omitempty"`
+
+	// VerifyIntegrity indicates whether restore benchmarks should verify that the restored documents match those in the
+	// benchmarking bucket once the last backup has been created, allowing performance runs to double as correctness
+	// runs.
+	VerifyIntegrity bool `json:"verify_integrity,omitempty" yaml:"verify_integrity,omitempty"`
+
+	// Throttle is the configuration used to throttle the cluster prior to running restore benchmarks, so that the
+	// cluster (rather than the backup client) is the bottleneck.
+	Throttle *ThrottleConfig `json:"throttle,omitempty" yaml:"throttle,omitempty"`
+
+	// ClusterLogs is the configuration used to limit which nodes the cluster logs are collected from, when unset the
+	// logs are collected from every node.
+	ClusterLogs *ClusterLogsConfig `json:"cluster_logs,omitempty" yaml:"cluster_logs,omitempty"`
+}
+
+// BenchmarkVariant is a variation of the benchmark config, multiple variants may be benchmarked in a single run so that
+// they can be compared against each other.
+type BenchmarkVariant struct {
+	// Name is used to identify the results for the variant in the report, it will be empty when not comparing variants.
+	Name string
+
+	// Config is the benchmark config which should be used for the variant.
+	Config *BenchmarkConfig
+
+	// CompressionMode is the compression mode which the benchmarking bucket should be using for the variant, empty when
+	// the compression mode isn't being compared.
+	CompressionMode string
+}
+
+// RestoreRangeConfig encapsulates the configuration for the range restore benchmark, which restores slices of a seeded
+// chain of backups using the '--start' and '--end' flags.
+type RestoreRangeConfig struct {
+	// Start is the position (starting from one) in the seeded chain of the first backup to restore.
+	Start int `json:"start,omitempty" yaml:"start,omitempty"`
+
+	// Lengths is the list of chain lengths which will be benchmarked, each length will be run for the configured number
+	// of iterations.
+	Lengths []int `json:"lengths,omitempty" yaml:"lengths,omitempty"`
+}
+
+// SeedConfig encapsulates the configuration used to pre-seed an archive with a chain of incremental backups.
+type SeedConfig struct {
+	// Backups is the number of backups which will be created, the first backup will be a full backup with the remaining
+	// backups being incremental.
+	Backups int `json:"backups,omitempty" yaml:"backups,omitempty"`
+
+	// Mutations is the number of items which will be mutated between each backup.
+	Mutations int `json:"mutations,omitempty" yaml:"mutations,omitempty"`
+
+	// GrowthPercentage is the percentage by which the size of the mutated items grows between each backup, modelling a
+	// dataset which grows over time. The growth compounds, so the items mutated prior to the nth incremental backup are
+	// '(1 + growth/100)^n' times the configured item size.
+	GrowthPercentage float64 `json:"growth_percentage,omitempty" yaml:"growth_percentage,omitempty"`
+}
+
+// DeletionConfig encapsulates the configuration used to delete a fraction of the documents in the benchmarking bucket.
+//
+// NOTE: The documents are not recreated after benchmarking, the dataset should be reloaded before running other
+// benchmarks against the cluster.
+type DeletionConfig struct {
+	// Percentage is the percentage of the documents in the benchmarking bucket which will be deleted.
+	Percentage float64 `json:"percentage,omitempty" yaml:"percentage,omitempty"`
+
+	// PurgeInterval is the metadata purge interval (in days) set on the benchmarking bucket prior to deleting the
+	// documents, controlling how long the tombstones are retained. A zero value leaves the interval unchanged.
+	PurgeInterval float64 `json:"purge_interval,omitempty" yaml:"purge_interval,omitempty"`
+}
+
+// ThrottleConfig encapsulates the configuration used to limit the throughput of the data service whilst restoring.
+type ThrottleConfig struct {
+	// WriterThreads is the number of KV writer threads used by each node, reducing the rate at which restored items are
+	// persisted. A zero value leaves the number of writer threads unchanged.
+	WriterThreads int `json:"writer_threads,omitempty" yaml:"writer_threads,omitempty"`
+}
+
+// ClusterLogsConfig encapsulates the configuration used to limit the scope of cluster log collection, which may take a
+// long time on large clusters.
+type ClusterLogsConfig struct {
+	// Scope determines which nodes the logs are collected from i.e. all/connected/none, defaults to all.
+	Scope string `json:"scope,omitempty" yaml:"scope,omitempty"`
+
+	// Nodes is an explicit list of hosts to collect the logs from, this takes precedence over the scope.
+	Nodes []string `json:"nodes,omitempty" yaml:"nodes,omitempty"`
+}
+
+// Validate returns an error if the cluster logs config is invalid.
+func (c *ClusterLogsConfig) Validate() error {
+	if !slices.Contains([]string{"", ClusterLogsAll, ClusterLogsConnected, ClusterLogsNone}, c.Scope) {
+		return fmt.Errorf("invalid cluster logs scope '%s', expected all/connected/none", c.Scope)
+	}
+
+	return nil
+}
+
+// ItemSize returns the size of the items which should be mutated prior to the given (zero indexed) backup in the chain,
+// when the items were initially loaded with the given size.
+func (s *SeedConfig) ItemSize(backup, size int) int {
+	return int(math.Round(float64(size) * math.Pow(1+s.GrowthPercentage/100, float64(backup))))
+}
+
+// Tasks returns a benchmark config for each of the backups which should be run simultaneously, when running concurrent
+// backups each config will use a different repository. When striping across archive devices, each of the backups will
+// be run once per device.
+func (b *BenchmarkConfig) Tasks() []*BenchmarkConfig {
+	tasks := []*BenchmarkConfig{b}
+
+	if b.ConcurrentBackups > 1 {
+		tasks = make([]*BenchmarkConfig, 0, b.ConcurrentBackups)
+
+		for idx := 0; idx < b.ConcurrentBackups; idx++ {
+			task := *b
+			task.CBMConfig = b.CBMConfig.WithRepository(fmt.Sprintf("%s-%d", b.CBMConfig.Repository, idx+1))
+
+			tasks = append(tasks, &task)
+		}
+	}
+
+	if b.ArchiveDeviceMode != ArchiveDeviceModeStripe || len(b.ArchiveDevices) == 0 {
+		return tasks
+	}
+
+	striped := make([]*BenchmarkConfig, 0, len(tasks)*len(b.ArchiveDevices))
+
+	for _, device := range b.ArchiveDevices {
+		for _, task := range tasks {
+			striped = append(striped, task.withArchive(device))
+		}
+	}
+
+	return striped
+}
+
+// withArchive returns a copy of the config which will use the given archive.
+func (b *BenchmarkConfig) withArchive(archive string) *BenchmarkConfig {
+	var (
+		config    = *b
+		cbmConfig = *b.CBMConfig
+	)
+
+	cbmConfig.Archive = archive
+	config.CBMConfig = &cbmConfig
+
+	return &config
+}
+
+// Variants returns the variations of the config which should be benchmarked, a single unnamed variant is returned when
+// no comparison has been configured. When multiple comparisons are configured, every combination will be benchmarked.
+func (b *BenchmarkConfig) Variants() []*BenchmarkVariant {
+	base := *b
+	base.CompareStorage = nil
+	base.CompareBlackhole = false
+	base.CompareDisable = nil
+	base.CompareCompression = nil
+	base.CompareEncryption = nil
+	base.CompareReplaceTTL = nil
+	base.CompareThreads = nil
+
+	variants := []*BenchmarkVariant{{Config: &base}}
+
+	// Each device will be benchmarked as a separate variant, allowing the throughput of each device to be compared
+	if b.ArchiveDeviceMode != ArchiveDeviceModeStripe && len(b.ArchiveDevices) != 0 {
+		variants = expandVariants(variants, b.ArchiveDevices, func(variant *BenchmarkVariant, device string) string {
+			variant.Config.CBMConfig.Archive = device
+
+			return "device=" + device
+		})
+	}
+
+	if len(b.CompareStorage) != 0 {
+		variants = expandVariants(variants, b.CompareStorage, func(variant *BenchmarkVariant, storage string) string {
+			// Each storage type uses a different repository so that backups of different types are never mixed
+			variant.Config.CBMConfig.Repository = fmt.Sprintf("%s-%s", variant.Config.CBMConfig.Repository, storage)
+			variant.Config.CBMConfig.Storage = storage
+
+			return "storage=" + storage
+		})
+	}
+
+	if b.CompareBlackhole {
+		variants = expandVariants(variants, []bool{false, true}, func(variant *BenchmarkVariant, blackhole bool) string {
+			variant.Config.CBMConfig.Blackhole = blackhole
+
+			if blackhole {
+				return "sink=blackhole"
+			}
+
+			return "sink=archive"
+		})
+	}
+
+	if len(b.CompareDisable) != 0 {
+		variants = expandVariants(variants, b.CompareDisable, func(variant *BenchmarkVariant, disable []string) string {
+			variant.Config.CBMConfig.Disable = disable
+
+			if len(disable) == 0 {
+				return "disable=none"
+			}
+
+			return "disable=" + strings.Join(disable, "+")
+		})
+	}
+
+	if len(b.CompareCompression) != 0 {
+		variants = expandVariants(variants, b.CompareCompression, func(variant *BenchmarkVariant, mode string) string {
+			variant.CompressionMode = mode
+
+			return "compression=" + mode
+		})
+	}
+
+	if len(b.CompareEncryption) != 0 {
+		variants = expandVariants(variants, b.CompareEncryption, func(variant *BenchmarkVariant, algo string) string {
+			// Encryption is configured per repository, so each algorithm must use a different repository
+			variant.Config.CBMConfig.Repository = fmt.Sprintf("%s-%s", variant.Config.CBMConfig.Repository, algo)
+			variant.Config.CBMConfig.Encrypted = algo != EncryptionNone
+			variant.Config.CBMConfig.EncryptionAlgo = ""
+
+			if variant.Config.CBMConfig.Encrypted {
+				variant.Config.CBMConfig.EncryptionAlgo = algo
+			}
+
+			return "encryption=" + algo
+		})
+	}
+
+	if len(b.CompareReplaceTTL) != 0 {
+		variants = expandVariants(variants, b.CompareReplaceTTL, func(variant *BenchmarkVariant, mode string) string {
+			variant.Config.CBMConfig.ReplaceTTL = mode
+
+			return "replace_ttl=" + mode
+		})
+	}
+
+	// The thread count is always the last part of the name, which allows the report to group variants which only differ
+	// by their thread count
+	if len(b.CompareThreads) != 0 {
+		variants = expandVariants(variants, b.CompareThreads, func(variant *BenchmarkVariant, threads int) string {
+			variant.Config.CBMConfig.Threads = threads
+
+			return fmt.Sprintf("threads=%d", threads)
+		})
+	}
+
+	return variants
+}
+
+// expandVariants returns a variant for every combination of the given variants and options, the provided function
+// should apply the option to a copy of the variant (including its 'cbbackupmgr' config) and return the name of the
+// option.
+func expandVariants[T any](variants []*BenchmarkVariant, options []T,
+	apply func(variant *BenchmarkVariant, option T) string,
+) []*BenchmarkVariant {
+	expanded := make([]*BenchmarkVariant, 0, len(variants)*len(options))
+
+	for _, variant := range variants {
+		for _, option := range options {
+			var (
+				config    = *variant.Config
+				cbmConfig = *variant.Config.CBMConfig
+				copied    = *variant
+			)
+
+			config.CBMConfig = &cbmConfig
+			copied.Config = &config
+
+			name := apply(&copied, option)
+
+			if variant.Name != "" {
+				name = variant.Name + ", " + name
+			}
+
+			copied.Name = name
+
+			expanded = append(expanded, &copied)
+		}
+	}
+
+	return expanded
 }
 
 // BenchmarkResults is a wrapper around a slice of benchmark results which provides some utility functions.
 type BenchmarkResults []*BenchmarkResult
 
+// Variants returns the names of the variants which produced the results, in the order they were first benchmarked.
+func (b BenchmarkResults) Variants() []string {
+	variants := make([]string, 0)
+
+	for _, result := range b {
+		if !slices.Contains(variants, result.Variant) {
+			variants = append(variants, result.Variant)
+		}
+	}
+
+	return variants
+}
+
+// Variant returns the results which were produced by the variant with the given name.
+func (b BenchmarkResults) Variant(name string) BenchmarkResults {
+	results := make(BenchmarkResults, 0, len(b))
+
+	for _, result := range b {
+		if result.Variant == name {
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// AvgDuration returns the average duration of the benchmark results.
+func (b BenchmarkResults) AvgDuration() time.Duration {
+	if len(b) == 0 {
+		return 0
+	}
+
+	var duration time.Duration
+	for _, result := range b {
+		duration += result.Duration
+	}
+
+	return time.Duration(int64(duration) / int64(len(b)))
+}
+
+// AvgCPUTime returns the average CPU time consumed on the backup client across all the benchmarks.
+func (b BenchmarkResults) AvgCPUTime() time.Duration {
+	if len(b) == 0 {
+		return 0
+	}
+
+	var total time.Duration
+	for _, result := range b {
+		total += result.CPUTime
+	}
+
+	return total / time.Duration(len(b))
+}
+
+// AvgADS returns the average actual data size of the benchmark results.
+func (b BenchmarkResults) AvgADS() uint64 {
+	if len(b) == 0 {
+		return 0
+	}
+
+	var ads uint64
+	for _, result := range b {
+		ads += result.ADS
+	}
+
+	return ads / uint64(len(b))
+}
+
+// AvgTransferRateADS returns the average transfer rate of the benchmark results calculated using the actual data size.
+func (b BenchmarkResults) AvgTransferRateADS() uint64 {
+	if len(b) == 0 {
+		return 0
+	}
+
+	var transferRate uint64
+	for _, result := range b {
+		transferRate += result.AvgTransferRateADS()
+	}
+
+	return transferRate / uint64(len(b))
+}
+
 // BenchmarkResult encapsulates a single benchmark results.
 type BenchmarkResult struct {
 	// Duration is the how long the benchmark took to complete (this does not include setup/cleanup).
 	Duration time.Duration
 
+	// Start/End are the wall-clock times at which the benchmark started/completed, allowing the results to be correlated
+	// with logs and external monitoring.
+	Start time.Time
+	End   time.Time
+
 	// AIN is the actual number of data items that was backed up. This will be used to determine if a workload
 	// generation tool (e.g. cbc-pillowfight) has managed to generate enough mutations during each granularity period
 	// (relevant to Point-In-Time backup testing).
@@ -43,6 +555,65 @@ type BenchmarkResult struct {
 	// ADS is the actual size of the data that was backed up. This will be used to calculate how much data is
 	// transferred for backup/restore benchmarks.
 	ADS uint64
+
+	// DiskSize is the actual size of the backup on disk, this will be compared with the ADS/GDS to determine how
+	// effective compression/deduplication was. A zero value indicates that it couldn't be determined.
+	DiskSize uint64
+
+	// CPUTime is the CPU time consumed on the backup client whilst the benchmark was running, summed across all the
+	// CPUs. A zero value indicates that it couldn't be determined.
+	CPUTime time.Duration
+
+	// Backups contains the structured details of the backup(s) created by the benchmark, one per repository when running
+	// concurrent backups.
+	Backups []*BackupDetails
+
+	// Variant is the name of the variant of the benchmark config which produced this result, empty when not comparing
+	// variants.
+	Variant string
+
+	// Threads is the number of threads passed to 'cbbackupmgr' using '--threads', zero indicates that the number of
+	// threads was automatically selected.
+	Threads int
+
+	// KVStatsBefore/KVStatsAfter are snapshots of some key KV stats taken immediately before/after the benchmark.
+	KVStatsBefore *KVStats
+	KVStatsAfter  *KVStats
+
+	// DCP is a summary of the DCP stats sampled for the backup connection(s) during backup benchmarks.
+	DCP *DCPSummary
+
+	// Archive/Repository are the archive/repository used by the backup, only populated for the individual tasks when
+	// running concurrent backups.
+	Archive    string
+	Repository string
+
+	// Range is the range of backups which were restored by the range restore benchmark e.g. '1-10'.
+	Range string
+
+	// PutRequests/GetRequests/ListRequests are the number of requests made to the object store, estimated based on the
+	// number of objects written by backups or read by restores unless counted. Zero for local archives.
+	PutRequests  uint64
+	GetRequests  uint64
+	ListRequests uint64
+
+	// Requests are the requests made to the object store for each S3 operation, counted using the S3 SDK debug logs.
+	// Only populated when counting requests, in which case the values above are the actual counts, not estimates.
+	Requests S3Requests
+
+	// Integrity is the outcome of verifying the restored documents, only populated for restore benchmarks when
+	// verifying integrity.
+	Integrity *Integrity
+
+	// Tasks contains the results for each of the individual backups when running concurrent backups, in which case the
+	// values above are the aggregate of all the tasks.
+	Tasks []*BenchmarkResult
+}
+
+// Complete records the end time of the benchmark along with its duration, the start time must already be populated.
+func (b *BenchmarkResult) Complete() {
+	b.End = time.Now()
+	b.Duration = b.End.Sub(b.Start)
 }
 
 // AvgTransferRateGDS returns the average transfer rate of all the benchmarks calculated using the generated data size.
@@ -20,3 +20,48 @@ type Blueprint struct {
 	Cluster      *ClusterBlueprint      `yaml:"cluster,omitempty"`
 	BackupClient *BackupClientBlueprint `yaml:"backup_client,omitempty"`
 }
+
+// Colocated returns the cluster node which is also being used as the backup client, nil if the backup client is a
+// dedicated machine. Small labs may share a node, however, benchmarks will be competing with Couchbase Server for
+// resources.
+func (b *Blueprint) Colocated() *NodeBlueprint {
+	if b.Cluster == nil || b.BackupClient == nil {
+		return nil
+	}
+
+	for _, node := range b.Cluster.Nodes {
+		if (node.Local && b.BackupClient.Local) || (node.Host != "" && node.Host == b.BackupClient.Host) {
+			return node
+		}
+	}
+
+	return nil
+}
+
+// redacted returns a copy of the blueprint where any node specific ssh config/cluster credentials have been redacted.
+func (b *Blueprint) redacted() *Blueprint {
+	blueprint := *b
+
+	if b.Cluster != nil {
+		cluster := *b.Cluster
+		cluster.Nodes = make([]*NodeBlueprint, 0, len(b.Cluster.Nodes))
+
+		for _, node := range b.Cluster.Nodes {
+			copied := *node
+			copied.SSH = copied.SSH.Redacted()
+			cluster.Nodes = append(cluster.Nodes, &copied)
+		}
+
+		cluster.Credentials = cluster.Credentials.Redacted()
+
+		blueprint.Cluster = &cluster
+	}
+
+	if b.BackupClient != nil {
+		client := *b.BackupClient
+		client.SSH = client.SSH.Redacted()
+		blueprint.BackupClient = &client
+	}
+
+	return &blueprint
+}
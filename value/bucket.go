@@ -31,6 +31,27 @@ type BucketBlueprint struct {
 	PiTRGranularity   uint64         `json:"pitr_granularity,omitempty" yaml:"pitr_granularity,omitempty"`
 	PiTRMaxHistoryAge uint64         `json:"pitr_max_history_age,omitempty" yaml:"pitr_max_history_age,omitempty"`
 	Data              *DataBlueprint `json:"data,omitempty" yaml:"data,omitempty"`
+
+	// RAMQuotaMB/RAMQuotaPercentage may be used to explicitly set the bucket quota, either in megabytes or as a
+	// percentage of the data service quota (allowing the quota to be split between multiple buckets). When neither are
+	// set, the whole data service quota will be used (or half of it, when a target bucket is configured).
+	RAMQuotaMB         uint64 `json:"ram_quota_mb,omitempty" yaml:"ram_quota_mb,omitempty"`
+	RAMQuotaPercentage uint64 `json:"ram_quota_percentage,omitempty" yaml:"ram_quota_percentage,omitempty"`
+
+	// CompressionMode is the compression mode of the bucket i.e. off/passive/active, an empty value uses the default.
+	CompressionMode string `json:"compression_mode,omitempty" yaml:"compression_mode,omitempty"`
+
+	// StorageBackend is the storage backend of the bucket i.e. couchstore/magma, an empty value uses the default. Backup
+	// performance differs significantly between the backends, so it's displayed in the report.
+	StorageBackend string `json:"storage_backend,omitempty" yaml:"storage_backend,omitempty"`
+
+	// TargetBucket is the name of a secondary bucket which will be created alongside the benchmarking bucket, when set,
+	// restore benchmarks will restore into this bucket using '--map-data' (leaving the benchmarking bucket untouched).
+	TargetBucket string `json:"target_bucket,omitempty" yaml:"target_bucket,omitempty"`
+
+	// AutoCompaction is the auto-compaction configuration applied whilst benchmarking, when unset the cluster defaults
+	// are left untouched.
+	AutoCompaction *AutoCompactionBlueprint `json:"auto_compaction,omitempty" yaml:"auto_compaction,omitempty"`
 }
 
 // String returns a string representation of the blueprint which will be output in the report.
@@ -55,12 +76,28 @@ func (b *BucketBlueprint) String() string {
 		evictionPolicy = b.EvictionPolicy
 	}
 
+	compressionMode := "default"
+	if b.CompressionMode != "" {
+		compressionMode = b.CompressionMode
+	}
+
+	storageBackend := "default"
+	if b.StorageBackend != "" {
+		storageBackend = b.StorageBackend
+	}
+
+	targetBucket := "N/A"
+	if b.TargetBucket != "" {
+		targetBucket = b.TargetBucket
+	}
+
 	pitrGranularity, pitrMaxHistoryAge := b.stringifyPiTRSettings()
 
 	fmt.Fprintln(buffer, "| Bucket\n| ------")
-	fmt.Fprintf(writer, "| vBuckets\t Type\t Eviction Policy\t PiTR Enabled\t PiTR Granularity\t PiTR Max History "+
-		"Age\t Compact\t\n")
-	fmt.Fprintf(writer, "| %s\t %s\t %s\t %t\t %s\t %s\t %t\t\n", vbuckets, bucketType, evictionPolicy, b.PiTREnabled,
+	fmt.Fprintf(writer, "| vBuckets\t Type\t Storage Backend\t Eviction Policy\t Compression Mode\t RAM Quota\t "+
+		"Target Bucket\t PiTR Enabled\t PiTR Granularity\t PiTR Max History Age\t Compact\t\n")
+	fmt.Fprintf(writer, "| %s\t %s\t %s\t %s\t %s\t %s\t %s\t %t\t %s\t %s\t %t\t\n", vbuckets, bucketType,
+		storageBackend, evictionPolicy, compressionMode, b.stringifyRAMQuota(), targetBucket, b.PiTREnabled,
 		pitrGranularity, pitrMaxHistoryAge, b.Compact)
 
 	_ = writer.Flush()
@@ -70,6 +107,30 @@ func (b *BucketBlueprint) String() string {
 	return buffer.String()
 }
 
+// stringifyRAMQuota returns the configured bucket quota as a string to display in the report.
+func (b *BucketBlueprint) stringifyRAMQuota() string {
+	if b.RAMQuotaMB != 0 {
+		return fmt.Sprintf("%dMB", b.RAMQuotaMB)
+	}
+
+	return fmt.Sprintf("%d%% of cluster", b.QuotaPercentage())
+}
+
+// QuotaPercentage returns the percentage of the data service quota which should be used for the bucket quota, this is
+// only used when an explicit quota in megabytes hasn't been provided.
+func (b *BucketBlueprint) QuotaPercentage() uint64 {
+	if b.RAMQuotaPercentage != 0 {
+		return b.RAMQuotaPercentage
+	}
+
+	// Both buckets will be created using the same quota, so split the data service quota between them
+	if b.TargetBucket != "" {
+		return 50
+	}
+
+	return 100
+}
+
 // stringifyPiTRSettings returns the pitr granularity/max age as strings to display in the report.
 func (b *BucketBlueprint) stringifyPiTRSettings() (string, string) {
 	if !b.PiTREnabled {
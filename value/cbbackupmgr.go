@@ -20,6 +20,8 @@ import (
 	"strconv"
 	"strings"
 	"text/tabwriter"
+
+	"github.com/pkg/errors"
 )
 
 // CBMEnvironment is the environment that will be passed to 'cbbackupmgr' when it's run on the remote machine.
@@ -59,6 +61,21 @@ type CBMConfig struct {
 	// TLS indicates whether to use the 'couchbases://' schema.
 	TLS bool `json:"tls,omitempty" yaml:"tls,omitempty"`
 
+	// CACert is the path to the CA certificate on the backup client used to verify the cluster certificates, passed to
+	// 'cbbackupmgr' using '--cacert'. When using TLS without a CA certificate (unless verification is disabled), the
+	// trusted CAs are fetched from the cluster and uploaded to the backup client.
+	CACert string `json:"cacert,omitempty" yaml:"cacert,omitempty"`
+
+	// NoSSLVerify disables verification of the cluster certificates when using TLS.
+	NoSSLVerify bool `json:"no_ssl_verify,omitempty" yaml:"no_ssl_verify,omitempty"`
+
+	// ClientCert/ClientKey are the paths to the client certificate/private key on the backup client, when provided
+	// 'cbbackupmgr' authenticates using them rather than the cluster credentials. This requires TLS, and for client
+	// certificate authentication to be enabled on the cluster (see 'TLSConfig.ClientAuth').
+	ClientCert        string `json:"client_cert,omitempty" yaml:"client_cert,omitempty"`
+	ClientKey         string `json:"client_key,omitempty" yaml:"client_key,omitempty"`
+	ClientKeyPassword string `json:"-" yaml:"client_key_password,omitempty"`
+
 	// Cloud related arguments.
 	ObjStagingDirectory       string `json:"obj_staging_directory,omitempty" yaml:"obj_staging_directory,omitempty"`
 	ObjAccessKeyID            string `json:"-" yaml:"obj_access_key_id,omitempty"`
@@ -70,21 +87,72 @@ type CBMConfig struct {
 	S3LogLevel                string `json:"s3_log_level,omitempty" yaml:"s3_log_level,omitempty"`
 	S3ForcePathStyle          bool   `json:"s3_force_path_style,omitempty" yaml:"s3_force_path_style,omitempty"`
 
+	// ObjProfile is the name of an AWS profile, read from the shared credentials file on the machine running autobench
+	// (defaulting to '~/.aws/credentials'), which will be used to populate the credentials/region above.
+	ObjProfile         string `json:"obj_profile,omitempty" yaml:"obj_profile,omitempty"`
+	ObjCredentialsFile string `json:"-" yaml:"obj_credentials_file,omitempty"`
+
+	// ObjSessionToken is the session token for temporary credentials, passed to 'cbbackupmgr' via 'AWS_SESSION_TOKEN'.
+	ObjSessionToken string `json:"-" yaml:"obj_session_token,omitempty"`
+
+	// ObjRoleARN is an IAM role which will be assumed using STS on the machine running autobench, the resulting
+	// temporary credentials will be used by 'cbbackupmgr'. The duration should cover the entire run.
+	ObjRoleARN             string `json:"obj_role_arn,omitempty" yaml:"obj_role_arn,omitempty"`
+	ObjRoleDurationSeconds int    `json:"obj_role_duration_seconds,omitempty" yaml:"obj_role_duration_seconds,omitempty"`
+
+	// ObjCountRequests enables debug logging for the S3 SDK (unless a log level has already been configured), allowing
+	// the requests made to the object store during each iteration to be counted by parsing the logs.
+	ObjCountRequests bool `json:"obj_count_requests,omitempty" yaml:"obj_count_requests,omitempty"`
+
 	// Encrypted related arguments
 	Encrypted      bool   `json:"encrypted,omitempty" yaml:"encrypted,omitempty"`
 	Passphrase     string `json:"passphrase,omitempty" yaml:"passphrase,omitempty"`
 	EncryptionAlgo string `json:"encryption_algo,omitempty" yaml:"encryption_algo,omitempty"`
 
+	// Key management related arguments, when a key URL is provided the repository key will be protected using the
+	// key management service (e.g. 'awskms://alias/autobench') rather than the passphrase.
+	KMKeyURL          string `json:"km_key_url,omitempty" yaml:"km_key_url,omitempty"`
+	KMRegion          string `json:"km_region,omitempty" yaml:"km_region,omitempty"`
+	KMEndpoint        string `json:"km_endpoint,omitempty" yaml:"km_endpoint,omitempty"`
+	KMAccessKeyID     string `json:"-" yaml:"km_access_key_id,omitempty"`
+	KMSecretAccessKey string `json:"-" yaml:"km_secret_access_key,omitempty"`
+
 	// NumThreads is the default of threads which will be used by 'cbbackupmgr'. A zero value will allow 'cbbackupmgr'
 	// to automatically determine the number of threads.
 	Threads int `json:"threads,omitempty" yaml:"threads,omitempty"`
 
+	// LogLevel is the value passed to '--log-level' for backups/restores, empty uses the 'cbbackupmgr' default.
+	LogLevel string `json:"log_level,omitempty" yaml:"log_level,omitempty"`
+
 	// PiTR indicates whether the backup repository should be configured for Point-In-Time backups.
 	PiTR bool `json:"pitr,omitempty" yaml:"pitr,omitempty"`
 
 	// Blackhole indicates whether the benchmarks should actually backup any data or just pull it from the cluster and
 	// then discard it immediately.
 	Blackhole bool `json:"blackhole,omitempty" yaml:"blackhole,omitempty"`
+
+	// ExtraBackupFlags/ExtraRestoreFlags/ExtraConfigFlags are appended verbatim to the generated 'backup', 'restore' and
+	// 'config' commands, allowing new/hidden options to be benchmarked without explicit support.
+	ExtraBackupFlags  []string `json:"extra_backup_flags,omitempty" yaml:"extra_backup_flags,omitempty"`
+	ExtraRestoreFlags []string `json:"extra_restore_flags,omitempty" yaml:"extra_restore_flags,omitempty"`
+	ExtraConfigFlags  []string `json:"extra_config_flags,omitempty" yaml:"extra_config_flags,omitempty"`
+
+	// ForceUpdates indicates whether restores should bypass conflict resolution, forcing the restored values to overwrite
+	// any existing values in the cluster.
+	ForceUpdates bool `json:"force_updates,omitempty" yaml:"force_updates,omitempty"`
+
+	// Disable is a list of data types which will be excluded from backups/restores by passing the matching '--disable-*'
+	// flags e.g. 'views' will pass '--disable-views'.
+	Disable []string `json:"disable,omitempty" yaml:"disable,omitempty"`
+
+	// AutoCreateBuckets indicates whether restore benchmarks should delete the target bucket prior to each restore and
+	// have 'cbbackupmgr' recreate it, measuring the full disaster recovery path including bucket creation.
+	AutoCreateBuckets bool `json:"auto_create_buckets,omitempty" yaml:"auto_create_buckets,omitempty"`
+
+	// ReplaceTTL/ReplaceTTLWith are passed to '--replace-ttl' (i.e. none/all/expired) and '--replace-ttl-with' when
+	// restoring, rewriting the expiry of the restored documents.
+	ReplaceTTL     string `json:"replace_ttl,omitempty" yaml:"replace_ttl,omitempty"`
+	ReplaceTTLWith string `json:"replace_ttl_with,omitempty" yaml:"replace_ttl_with,omitempty"`
 }
 
 // String returns a human readable string representation of the config which will be displayed in the report.
@@ -109,17 +177,42 @@ func (c *CBMConfig) String() string {
 		threads = strconv.Itoa(c.Threads)
 	}
 
+	disabled := "N/A"
+	if len(c.Disable) != 0 {
+		disabled = strings.Join(c.Disable, ", ")
+	}
+
+	encryption := "N/A"
+	if c.Encrypted {
+		encryption = c.encryption()
+	}
+
+	replaceTTL := "N/A"
+	if c.ReplaceTTL != "" {
+		replaceTTL = c.ReplaceTTL
+	}
+
+	if c.ReplaceTTL != "" && c.ReplaceTTLWith != "" {
+		replaceTTL += " (" + c.ReplaceTTLWith + ")"
+	}
+
 	fmt.Fprintln(buffer, "| CBM\n| ----")
-	fmt.Fprintf(writer, "| Archive\t Repository\t Staging Directory\t Storage\t Threads\t PiTR\t "+
-		"Blackhole\t\n")
-	fmt.Fprintf(writer, "| %s\t %s\t %s\t %s\t %s\t %t\t %t\t\n",
+	fmt.Fprintf(writer, "| Archive\t Repository\t Staging Directory\t Storage\t Transport\t Threads\t PiTR\t "+
+		"Blackhole\t Auto Create Buckets\t Force Updates\t Disabled\t Encryption\t Replace TTL\t\n")
+	fmt.Fprintf(writer, "| %s\t %s\t %s\t %s\t %s\t %s\t %t\t %t\t %t\t %t\t %s\t %s\t %s\t\n",
 		c.Archive,
 		c.Repository,
 		staging,
 		storage,
+		c.transport(),
 		threads,
 		c.PiTR,
-		c.Blackhole)
+		c.Blackhole,
+		c.AutoCreateBuckets,
+		c.ForceUpdates,
+		disabled,
+		encryption,
+		replaceTTL)
 
 	_ = writer.Flush()
 
@@ -130,6 +223,51 @@ func (c *CBMConfig) String() string {
 	return strings.TrimSpace(buffer.String())
 }
 
+// Validate returns an error if the client certificate config is incomplete, or can't be used.
+func (c *CBMConfig) Validate() error {
+	if c.ClientCert == "" && c.ClientKey == "" {
+		return nil
+	}
+
+	if c.ClientCert == "" || c.ClientKey == "" {
+		return errors.New("both 'client_cert' and 'client_key' must be provided")
+	}
+
+	if !c.TLS {
+		return errors.New("authenticating using a client certificate requires 'tls'")
+	}
+
+	return nil
+}
+
+// transport returns how 'cbbackupmgr' connects to the cluster i.e. plain/tls/mtls, to display in the report.
+func (c *CBMConfig) transport() string {
+	switch {
+	case !c.TLS:
+		return "plain"
+	case c.ClientCert != "":
+		return "mtls"
+	default:
+		return "tls"
+	}
+}
+
+// WithCACert returns a copy of the config which will use the given CA certificate to verify the cluster certificates.
+func (c *CBMConfig) WithCACert(path string) *CBMConfig {
+	config := *c
+	config.CACert = path
+
+	return &config
+}
+
+// WithRepository returns a copy of the config which will use the given repository.
+func (c *CBMConfig) WithRepository(repository string) *CBMConfig {
+	config := *c
+	config.Repository = repository
+
+	return &config
+}
+
 // CommandConfig returns a command which may be run on the remote backup client to configure the benchmark
 // archive/repository.
 func (c *CBMConfig) CommandConfig() Command {
@@ -139,50 +277,74 @@ func (c *CBMConfig) CommandConfig() Command {
 	command = c.addCloudArgs(command)
 	command = c.addEncryptionArgs(command, true)
 	command = c.addPointInTimeFlag(command)
+	command = addExtraFlags(command, c.ExtraConfigFlags)
 
-	return NewCommand(command)
+	return NewCommand("%s", command)
 }
 
-// CommandBackup returns a command which may be run on the remote backup client to perform a backup.
-func (c *CBMConfig) CommandBackup(host string, ignoreBlackhole bool) Command {
+// CommandBackup returns a command which may be run on the remote backup client to perform a backup, authenticating
+// using the given cluster credentials. The backup is forced to be a full backup when 'full' is set.
+func (c *CBMConfig) CommandBackup(host string, credentials *Credentials, ignoreBlackhole, full bool) Command {
 	command := fmt.Sprintf(
-		`cbbackupmgr backup -a %s -r %s -c %s -u Administrator -p asdasd --no-progress-bar`,
+		`cbbackupmgr backup -a %s -r %s -c %s %s --no-progress-bar`,
 		c.Archive,
 		c.Repository,
 		host,
+		c.authFlags(credentials),
 	)
 
 	command = c.prefixEnvironment(command)
+	command = c.addTLSArgs(command)
 	command = c.addCloudArgs(command)
 	command = c.addEncryptionArgs(command, false)
 	command = c.addStorage(command)
 	command = c.addThreads(command)
+	command = c.addLogLevel(command)
+
+	if full {
+		command += " --full-backup"
+	}
 
 	// When we're performing restore benchmarks we actually need to create a backup so we should ignore the blackhole
-	// configuration.
+	// configuration; the backup must also contain every data type, since it's the restore which excludes them.
 	if !ignoreBlackhole {
 		command = c.addBlackhole(command)
+		command = c.addDisable(command)
 	}
 
-	return NewCommand(command)
+	command = addExtraFlags(command, c.ExtraBackupFlags)
+
+	return NewCommand("%s", command)
 }
 
-// CommandRestore returns a command which can be run on the remote backup client to perform a restore.
-func (c *CBMConfig) CommandRestore(host string) Command {
+// CommandRestore returns a command which can be run on the remote backup client to perform a restore. The start/end
+// arguments may be used to restore a range of backups, empty values will restore all the backups in the repository.
+// When a target bucket is provided, the data will be restored into it rather than the bucket it was backed up from.
+func (c *CBMConfig) CommandRestore(host string, credentials *Credentials, start, end, target string) Command {
 	command := fmt.Sprintf(
-		`cbbackupmgr restore -a %s -r %s -c %s -u Administrator -p asdasd --no-progress-bar`,
+		`cbbackupmgr restore -a %s -r %s -c %s %s --no-progress-bar`,
 		c.Archive,
 		c.Repository,
 		host,
+		c.authFlags(credentials),
 	)
 
 	command = c.prefixEnvironment(command)
+	command = c.addTLSArgs(command)
 	command = c.addCloudArgs(command)
 	command = c.addEncryptionArgs(command, false)
 	command = c.addThreads(command)
+	command = c.addLogLevel(command)
 	command = c.addBlackhole(command)
-
-	return NewCommand(command)
+	command = c.addRange(command, start, end)
+	command = c.addMapData(command, target)
+	command = c.addAutoCreateBuckets(command)
+	command = c.addForceUpdates(command)
+	command = c.addReplaceTTL(command)
+	command = c.addDisable(command)
+	command = addExtraFlags(command, c.ExtraRestoreFlags)
+
+	return NewCommand("%s", command)
 }
 
 // CommandCollectLogs returns a command which can be run on the remote backup client to collect the 'cbbackupmgr' logs.
@@ -192,7 +354,7 @@ func (c *CBMConfig) CommandCollectLogs() Command {
 	command = c.addCloudArgs(command)
 	command = c.prefixEnvironment(command)
 
-	return NewCommand(command)
+	return NewCommand("%s", command)
 }
 
 // CommandRemove returns a command which can be run on the remote backup client to remove all the backups from start to
@@ -209,34 +371,76 @@ func (c *CBMConfig) CommandRemove(start, end string) Command {
 	command = c.prefixEnvironment(command)
 	command = c.addCloudArgs(command)
 
-	return NewCommand(command)
+	return NewCommand("%s", command)
 }
 
 // CommandInfo returns a command which can be run on the remote backup client which will return information about the
-// given backup repository in JSON format.
+// given backup repository in JSON format, the passphrase/key is provided so that it fails for a mismatched repository.
 func (c *CBMConfig) CommandInfo() Command {
 	command := fmt.Sprintf("cbbackupmgr info -a %s -r %s -j", c.Archive, c.Repository)
 
+	command = c.prefixEnvironment(command)
+	command = c.addCloudArgs(command)
+	command = c.addEncryptionArgs(command, false)
+
+	return NewCommand("%s", command)
+}
+
+// CommandArchiveInfo returns a command which can be run on the remote backup client which will return information about
+// the archive, including the repositories it contains, in JSON format.
+func (c *CBMConfig) CommandArchiveInfo() Command {
+	command := fmt.Sprintf("cbbackupmgr info -a %s -j", c.Archive)
+
 	command = c.prefixEnvironment(command)
 	command = c.addCloudArgs(command)
 
-	return NewCommand(command)
+	return NewCommand("%s", command)
 }
 
 // prefixEnvironment with prefix the given command with the current 'cbbackupmgr' environment variables.
 func (c *CBMConfig) prefixEnvironment(command string) string {
-	if len(c.EnvVars) == 0 {
-		return command
-	}
-
 	var env string
 	for key, value := range c.EnvVars {
 		env += fmt.Sprintf("export %s=%s; ", key, value)
 	}
 
+	// There's no flag for the session token, it must be provided using the environment
+	if c.ObjSessionToken != "" {
+		env += fmt.Sprintf("export AWS_SESSION_TOKEN=%s; ", c.ObjSessionToken)
+	}
+
 	return env + command
 }
 
+// authFlags returns the flags used to authenticate against the cluster, the client certificate/private key when they've
+// been provided, otherwise the given credentials.
+func (c *CBMConfig) authFlags(credentials *Credentials) string {
+	if c.ClientCert == "" {
+		return credentials.Flags()
+	}
+
+	flags := fmt.Sprintf("--client-cert %s --client-key %s", c.ClientCert, c.ClientKey)
+	if c.ClientKeyPassword != "" {
+		flags += fmt.Sprintf(" --client-key-password %s", shellQuote(c.ClientKeyPassword))
+	}
+
+	return flags
+}
+
+// addTLSArgs will conditionally add the --cacert/--no-ssl-verify flags to the given command.
+func (c *CBMConfig) addTLSArgs(command string) string {
+	switch {
+	case !c.TLS:
+		return command
+	case c.NoSSLVerify:
+		return command + " --no-ssl-verify"
+	case c.CACert != "":
+		return command + fmt.Sprintf(" --cacert %s", c.CACert)
+	}
+
+	return command
+}
+
 // addStorage will add the storage flag to the given command if required.
 func (c *CBMConfig) addStorage(command string) string {
 	if c.Storage == "" {
@@ -255,6 +459,89 @@ func (c *CBMConfig) addThreads(command string) string {
 	return command + " --auto-select-threads"
 }
 
+// addLogLevel will conditionally add the --log-level flag to the given command.
+func (c *CBMConfig) addLogLevel(command string) string {
+	if c.LogLevel == "" {
+		return command
+	}
+
+	return command + fmt.Sprintf(" --log-level %s", c.LogLevel)
+}
+
+// addRange will conditionally add the --start/--end flags to the given command.
+func (c *CBMConfig) addRange(command, start, end string) string {
+	if start != "" {
+		command += fmt.Sprintf(" --start %s", start)
+	}
+
+	if end != "" {
+		command += fmt.Sprintf(" --end %s", end)
+	}
+
+	return command
+}
+
+// addMapData will conditionally add the --map-data flag to the given command, mapping the benchmarking bucket to the
+// given target bucket.
+func (c *CBMConfig) addMapData(command, target string) string {
+	if target == "" {
+		return command
+	}
+
+	return command + fmt.Sprintf(" --map-data default=%s", target)
+}
+
+// addAutoCreateBuckets will conditionally add the --auto-create-buckets flag to the given command.
+func (c *CBMConfig) addAutoCreateBuckets(command string) string {
+	if !c.AutoCreateBuckets {
+		return command
+	}
+
+	return command + " --auto-create-buckets"
+}
+
+// addForceUpdates will conditionally add the --force-updates flag to the given command.
+func (c *CBMConfig) addForceUpdates(command string) string {
+	if !c.ForceUpdates {
+		return command
+	}
+
+	return command + " --force-updates"
+}
+
+// addReplaceTTL will add the '--replace-ttl' and '--replace-ttl-with' flags to the given command if configured.
+func (c *CBMConfig) addReplaceTTL(command string) string {
+	if c.ReplaceTTL == "" {
+		return command
+	}
+
+	command += " --replace-ttl " + c.ReplaceTTL
+
+	if c.ReplaceTTLWith != "" {
+		command += " --replace-ttl-with " + c.ReplaceTTLWith
+	}
+
+	return command
+}
+
+// addDisable will add a '--disable-*' flag to the given command for each of the disabled data types.
+func (c *CBMConfig) addDisable(command string) string {
+	for _, disable := range c.Disable {
+		command += fmt.Sprintf(" --disable-%s", disable)
+	}
+
+	return command
+}
+
+// addExtraFlags appends the given flags verbatim to the given command.
+func addExtraFlags(command string, flags []string) string {
+	for _, flag := range flags {
+		command += " " + flag
+	}
+
+	return command
+}
+
 // addBlackhole will conditionally add the --blackhole flag to the given command.
 func (c *CBMConfig) addBlackhole(command string) string {
 	if !c.Blackhole {
@@ -303,8 +590,13 @@ func (c *CBMConfig) addCloudArgs(command string) string {
 		command += " --obj-no-ssl-verify"
 	}
 
-	if c.S3LogLevel != "" {
-		command += fmt.Sprintf(" --s3-log-level %s", c.S3LogLevel)
+	level := c.S3LogLevel
+	if level == "" && c.ObjCountRequests {
+		level = S3LogLevelDebug
+	}
+
+	if level != "" {
+		command += fmt.Sprintf(" --s3-log-level %s", level)
 	}
 
 	if c.S3ForcePathStyle {
@@ -320,7 +612,11 @@ func (c *CBMConfig) addEncryptionArgs(command string, config bool) string {
 		return command
 	}
 
-	command += fmt.Sprintf(" --passphrase %s", c.Passphrase)
+	if c.KMKeyURL != "" {
+		command = c.addKMArgs(command)
+	} else {
+		command += fmt.Sprintf(" --passphrase %s", c.Passphrase)
+	}
 
 	if !config {
 		return command
@@ -334,3 +630,40 @@ func (c *CBMConfig) addEncryptionArgs(command string, config bool) string {
 
 	return command
 }
+
+// addKMArgs will add the key management flags to the given command, used instead of the passphrase.
+func (c *CBMConfig) addKMArgs(command string) string {
+	command += fmt.Sprintf(" --km-key-url %s", c.KMKeyURL)
+
+	if c.KMRegion != "" {
+		command += fmt.Sprintf(" --km-region %s", c.KMRegion)
+	}
+
+	if c.KMEndpoint != "" {
+		command += fmt.Sprintf(" --km-endpoint %s", c.KMEndpoint)
+	}
+
+	if c.KMAccessKeyID != "" {
+		command += fmt.Sprintf(" --km-access-key-id %s", c.KMAccessKeyID)
+	}
+
+	if c.KMSecretAccessKey != "" {
+		command += fmt.Sprintf(" --km-secret-access-key %s", c.KMSecretAccessKey)
+	}
+
+	return command
+}
+
+// encryption returns a description of how the repository is encrypted, which will be displayed in the report.
+func (c *CBMConfig) encryption() string {
+	algo := "default"
+	if c.EncryptionAlgo != "" {
+		algo = c.EncryptionAlgo
+	}
+
+	if c.KMKeyURL != "" {
+		return algo + " (kms)"
+	}
+
+	return algo + " (passphrase)"
+}
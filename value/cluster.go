@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 )
@@ -26,12 +27,20 @@ import (
 // ClusterBlueprint encapsulates the configuration for the Couchbase Cluster which will be provisioned by the
 // 'provision' sub-command.
 type ClusterBlueprint struct {
-	// PackagePath is the path to a local package. This package will be secure copied to each cluster node and installed
-	// instead of downloading the build from latest builds.
+	// PackagePath is the path to a local package. This package will be secure copied to each cluster node and installed;
+	// builds are never downloaded, so packages from a mirror/latest builds must be fetched beforehand. Uploaded packages
+	// are cached on each node, keyed by their checksum.
 	//
 	// NOTE: No validation takes place to ensure the package is valid for the current distribution; that's on you...
 	PackagePath string `yaml:"package_path,omitempty"`
 
+	// DependenciesPath is the path to a local directory containing the dependency packages (e.g. awscli), these will be
+	// secure copied to each cluster node and installed offline rather than using the package manager's repositories.
+	DependenciesPath string `yaml:"dependencies_path,omitempty"`
+
+	// CBPaths describes where Couchbase Server is installed on the cluster nodes.
+	CBPaths `yaml:",inline"`
+
 	// Nodes is the list of node blueprints which will be used to create the cluster.
 	Nodes []*NodeBlueprint `yaml:"nodes,omitempty"`
 
@@ -41,20 +50,100 @@ type ClusterBlueprint struct {
 	// DeveloperPreview is a boolean which indicates whether or not developer preview should be enabled on the
 	// cluster.
 	DeveloperPreview bool `yaml:"developer_preview,omitempty"`
+
+	// RAMQuotaMB/RAMQuotaPercentage may be used to explicitly set the data service quota, either in megabytes or as a
+	// percentage of the total memory on each node. When neither are set, 80% of the total memory will be used.
+	RAMQuotaMB         uint64 `yaml:"ram_quota_mb,omitempty"`
+	RAMQuotaPercentage uint64 `yaml:"ram_quota_percentage,omitempty"`
+
+	// ServiceQuotas sets the memory quotas for the non-data services (see 'NodeBlueprint.Services'), when unset the
+	// Couchbase Server defaults are used.
+	ServiceQuotas *ServiceQuotas `yaml:"service_quotas,omitempty"`
+
+	// Volumes describes the AWS EBS volumes which are expected to back paths on every cluster node (e.g. the data path),
+	// their characteristics are validated during provisioning and recorded in the report.
+	Volumes []*VolumeBlueprint `yaml:"volumes,omitempty"`
+
+	// Concurrency is the maximum number of nodes which will be operated on concurrently (e.g. when provisioning), when
+	// unset this defaults to the number of CPUs on the machine running autobench.
+	Concurrency int `yaml:"concurrency,omitempty"`
+
+	// ProvisionBatchSize enables rolling provisioning, where nodes are provisioned in batches of the given size with
+	// each batch completing before the next begins; this avoids overwhelming the package mirror on large clusters.
+	ProvisionBatchSize int `yaml:"provision_batch_size,omitempty"`
+
+	// RebalanceMovesPerNode is the number of concurrent vBucket moves per node during a rebalance, when unset the
+	// cluster default is used.
+	RebalanceMovesPerNode int `yaml:"rebalance_moves_per_node,omitempty"`
+
+	// CompactionConcurrentRatio is the maximum ratio of the writer threads which may be used to compact the benchmarking
+	// buckets concurrently (i.e. 'compaction_max_concurrent_ratio'), when unset the cluster default is used.
+	CompactionConcurrentRatio float64 `yaml:"compaction_concurrent_ratio,omitempty"`
+
+	// Credentials are the cluster administrator credentials, when unset the default credentials are used.
+	Credentials *Credentials `yaml:"credentials,omitempty"`
+
+	// REST configures how requests are made to the cluster manager REST API, by default plain HTTP is used.
+	REST *RESTConfig `yaml:"rest,omitempty"`
+
+	// TLS enables TLS for the cluster, node certificates are configured during provisioning and interactions with the
+	// cluster manager (e.g. 'couchbase-cli') use HTTPS.
+	TLS *TLSConfig `yaml:"tls,omitempty"`
+
+	// Managed indicates whether the cluster is provisioned/managed by autobench (the default). An unmanaged cluster is
+	// an existing cluster which is attached to, only the node hosts/ports and credentials are required; no ssh access
+	// to the nodes is required, the cluster is instead driven from the backup client.
+	Managed *bool `yaml:"managed,omitempty"`
+}
+
+// IsManaged returns a boolean indicating whether the cluster is provisioned/managed by autobench.
+func (c *ClusterBlueprint) IsManaged() bool {
+	return c.Managed == nil || *c.Managed
+}
+
+// QuotaPercentage returns the percentage of the total memory on each node which should be used for the data service
+// quota, this is only used when an explicit quota in megabytes hasn't been provided.
+func (c *ClusterBlueprint) QuotaPercentage() uint64 {
+	if c.RAMQuotaPercentage != 0 {
+		return c.RAMQuotaPercentage
+	}
+
+	return 80
+}
+
+// clusterBlueprintJSON is the JSON representation of the cluster blueprint which is included in the report.
+type clusterBlueprintJSON struct {
+	Version          string           `json:"version,omitempty"`
+	Nodes            []*NodeBlueprint `json:"nodes,omitempty"`
+	Bucket           *BucketBlueprint `json:"bucket,omitempty"`
+	DeveloperPreview bool             `json:"developer_preview,omitempty"`
+	RAMQuota         string           `json:"ram_quota,omitempty"`
+	ServiceQuotas    *ServiceQuotas   `json:"service_quotas,omitempty"`
+	TLS              bool             `json:"tls,omitempty"`
+
+	RebalanceMovesPerNode     int     `json:"rebalance_moves_per_node,omitempty"`
+	CompactionConcurrentRatio float64 `json:"compaction_concurrent_ratio,omitempty"`
+}
+
+// JSONShape returns a value with the same shape as the JSON representation of the cluster blueprint, used to generate
+// the schema of the report.
+func (c *ClusterBlueprint) JSONShape() any {
+	return clusterBlueprintJSON{}
 }
 
 // MarshalJSON returns a JSON representation of the cluster blueprint which will be displayed in the report.
 func (c *ClusterBlueprint) MarshalJSON() ([]byte, error) {
-	return json.Marshal(struct {
-		Version          string           `json:"version,omitempty"`
-		Nodes            []*NodeBlueprint `json:"nodes,omitempty"`
-		Bucket           *BucketBlueprint `json:"bucket,omitempty"`
-		DeveloperPreview bool             `json:"developer_preview,omitempty"`
-	}{
+	return json.Marshal(clusterBlueprintJSON{
 		Version:          extractBuild(c.PackagePath),
 		Nodes:            c.Nodes,
 		Bucket:           c.Bucket,
 		DeveloperPreview: c.DeveloperPreview,
+		RAMQuota:         c.stringifyRAMQuota(),
+		ServiceQuotas:    c.ServiceQuotas,
+		TLS:              c.TLSEnabled(),
+
+		RebalanceMovesPerNode:     c.RebalanceMovesPerNode,
+		CompactionConcurrentRatio: c.CompactionConcurrentRatio,
 	})
 }
 
@@ -66,11 +155,13 @@ func (c *ClusterBlueprint) String() string {
 	)
 
 	fmt.Fprintln(buffer, "| Cluster\n| -------")
-	fmt.Fprintf(writer, "| Node\t Version\t Host\t Developer Preview\t\n")
+	fmt.Fprintf(writer, "| Node\t Version\t Host\t Services\t Developer Preview\t RAM Quota\t Rebalance Moves\t "+
+		"Compaction Ratio\t\n")
 
 	for index, node := range c.Nodes {
-		fmt.Fprintf(writer, "| %d\t %s\t %s\t %t\t\n", index+1, extractBuild(c.PackagePath), node.Host,
-			c.DeveloperPreview)
+		fmt.Fprintf(writer, "| %d\t %s\t %s\t %s\t %t\t %s\t %s\t %s\t\n", index+1, extractBuild(c.PackagePath),
+			node.Host, node.ServicesFlag(), c.DeveloperPreview, c.stringifyRAMQuota(), c.stringifyRebalanceMoves(),
+			c.stringifyCompactionRatio())
 	}
 
 	_ = writer.Flush()
@@ -80,6 +171,34 @@ func (c *ClusterBlueprint) String() string {
 	return strings.TrimSpace(buffer.String())
 }
 
+// stringifyRAMQuota returns the configured data service quota as a string to display in the report.
+func (c *ClusterBlueprint) stringifyRAMQuota() string {
+	if c.RAMQuotaMB != 0 {
+		return fmt.Sprintf("%dMB", c.RAMQuotaMB)
+	}
+
+	return fmt.Sprintf("%d%%", c.QuotaPercentage())
+}
+
+// stringifyRebalanceMoves returns the configured number of rebalance moves per node as a string to display in the
+// report.
+func (c *ClusterBlueprint) stringifyRebalanceMoves() string {
+	if c.RebalanceMovesPerNode == 0 {
+		return "default"
+	}
+
+	return strconv.Itoa(c.RebalanceMovesPerNode)
+}
+
+// stringifyCompactionRatio returns the configured compaction concurrency ratio as a string to display in the report.
+func (c *ClusterBlueprint) stringifyCompactionRatio() string {
+	if c.CompactionConcurrentRatio == 0 {
+		return "default"
+	}
+
+	return strconv.FormatFloat(c.CompactionConcurrentRatio, 'g', -1, 64)
+}
+
 // extractBuild will extract the build number from the provided string. Returns 'unknown' in the event that we're unable
 // to determine the version.
 func extractBuild(s string) string {
@@ -0,0 +1,162 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"text/tabwriter"
+)
+
+// MaxCollections is the maximum number of collections which may be created in the benchmarking bucket, this is the
+// default per-cluster limit imposed by Couchbase Server.
+const MaxCollections = 1000
+
+// CollectionDistribution describes how the items in the dataset are distributed between the collections.
+type CollectionDistribution string
+
+const (
+	// CollectionDistributionUniform distributes the items evenly between the collections.
+	CollectionDistributionUniform CollectionDistribution = "uniform"
+
+	// CollectionDistributionZipf distributes the items following Zipf's law, where the n-th collection contains 1/n of
+	// the items in the first collection; this reflects datasets where a few collections contain most of the data.
+	CollectionDistributionZipf CollectionDistribution = "zipf"
+)
+
+// CollectionsBlueprint describes the scopes/collections which are created in the benchmarking bucket when provisioning,
+// the dataset is distributed between these collections rather than being loaded into the default collection.
+//
+// NOTE: Collections are only supported by Couchbase Server 7.0.0 and above.
+type CollectionsBlueprint struct {
+	// Scopes is the number of scopes which will be created, in addition to the default scope.
+	Scopes int `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+
+	// Collections is the number of collections which will be created in each scope.
+	Collections int `json:"collections,omitempty" yaml:"collections,omitempty"`
+
+	// Distribution controls how the items are distributed between the collections i.e. uniform/zipf, defaults to
+	// uniform.
+	Distribution CollectionDistribution `json:"distribution,omitempty" yaml:"distribution,omitempty"`
+}
+
+// Collection is a collection in the benchmarking bucket, the UID is only populated once it's been resolved using the
+// collection manifest.
+type Collection struct {
+	Scope string
+	Name  string
+	UID   string
+}
+
+// String returns the 'scope.collection' expression for the collection.
+func (c *Collection) String() string {
+	return c.Scope + "." + c.Name
+}
+
+// Validate returns an error if the blueprint is invalid.
+func (c *CollectionsBlueprint) Validate() error {
+	if c.Scopes <= 0 || c.Collections <= 0 {
+		return fmt.Errorf("at least one scope/collection is required, got %d scope(s) with %d collection(s)", c.Scopes,
+			c.Collections)
+	}
+
+	if total := c.Scopes * c.Collections; total > MaxCollections {
+		return fmt.Errorf("%d collections exceeds the maximum of %d", total, MaxCollections)
+	}
+
+	switch c.Distribution {
+	case "", CollectionDistributionUniform, CollectionDistributionZipf:
+		return nil
+	}
+
+	return fmt.Errorf("unknown collection distribution '%s'", c.Distribution)
+}
+
+// List returns the collections described by the blueprint, scopes/collections are named 'scope_<n>'/'collection_<n>'.
+func (c *CollectionsBlueprint) List() []*Collection {
+	collections := make([]*Collection, 0, c.Scopes*c.Collections)
+
+	for scope := 1; scope <= c.Scopes; scope++ {
+		for collection := 1; collection <= c.Collections; collection++ {
+			collections = append(collections, &Collection{
+				Scope: fmt.Sprintf("scope_%d", scope),
+				Name:  fmt.Sprintf("collection_%d", collection),
+			})
+		}
+	}
+
+	return collections
+}
+
+// Distribute splits the given number of items between the collections (in the order returned by 'List') using the
+// configured distribution, any remainder is assigned to the first collections.
+func (c *CollectionsBlueprint) Distribute(items int) []int {
+	var (
+		total   = c.Scopes * c.Collections
+		weights = make([]float64, total)
+		sum     float64
+	)
+
+	for idx := range weights {
+		weights[idx] = 1
+		if c.Distribution == CollectionDistributionZipf {
+			weights[idx] = 1 / float64(idx+1)
+		}
+
+		sum += weights[idx]
+	}
+
+	var (
+		distribution = make([]int, total)
+		remaining    = items
+	)
+
+	for idx, weight := range weights {
+		distribution[idx] = int(float64(items) * weight / sum)
+		remaining -= distribution[idx]
+	}
+
+	// Each collection loses less than an item to rounding, so the remainder is less than the number of collections
+	for idx := 0; idx < remaining; idx++ {
+		distribution[idx]++
+	}
+
+	return distribution
+}
+
+// String returns a string representation of the blueprint which will be output in the report.
+func (c *CollectionsBlueprint) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	distribution := CollectionDistributionUniform
+	if c.Distribution != "" {
+		distribution = c.Distribution
+	}
+
+	// The share of the dataset in the largest collection, which highlights how skewed the distribution is
+	largest := 100 * float64(slices.Max(c.Distribute(1_000_000))) / 1_000_000
+
+	fmt.Fprintln(buffer, "| Collections\n| -----------")
+	fmt.Fprintf(writer, "| Scopes\t Collections Per Scope\t Distribution\t Largest Collection\t\n")
+	fmt.Fprintf(writer, "| %d\t %d\t %s\t %.2f%%\t\n", c.Scopes, c.Collections, distribution, largest)
+
+	_ = writer.Flush()
+
+	return buffer.String()
+}
@@ -0,0 +1,236 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	// DefaultFragmentationPercentage is the fragmentation percentage at which Couchbase Server triggers auto-compaction
+	// by default, for both the database and view files.
+	DefaultFragmentationPercentage = 30
+)
+
+// AutoCompactionBlueprint describes the auto-compaction settings which will be applied to the cluster whilst
+// benchmarking, so that background compaction doesn't fire mid-iteration. The original settings are restored once
+// benchmarking has completed.
+type AutoCompactionBlueprint struct {
+	// Disabled indicates that auto-compaction should be disabled entirely whilst benchmarking.
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+
+	// Database/View are the fragmentation thresholds at which auto-compaction of the database/view files will be
+	// triggered, when unset the Couchbase Server default percentage is used.
+	Database *FragmentationThreshold `json:"database,omitempty" yaml:"database,omitempty"`
+	View     *FragmentationThreshold `json:"view,omitempty" yaml:"view,omitempty"`
+
+	// TimeWindow limits auto-compaction to the given time period, when unset compaction may run at any time.
+	TimeWindow *CompactionTimeWindow `json:"time_window,omitempty" yaml:"time_window,omitempty"`
+
+	// ParallelCompaction indicates whether the database and view/index files may be compacted in parallel.
+	ParallelCompaction bool `json:"parallel_compaction,omitempty" yaml:"parallel_compaction,omitempty"`
+}
+
+// DatabaseThreshold returns the effective database fragmentation threshold.
+func (a *AutoCompactionBlueprint) DatabaseThreshold() *FragmentationThreshold {
+	return a.Database.effective()
+}
+
+// ViewThreshold returns the effective view fragmentation threshold.
+func (a *AutoCompactionBlueprint) ViewThreshold() *FragmentationThreshold {
+	return a.View.effective()
+}
+
+// Validate returns an error if the auto-compaction settings are invalid.
+func (a *AutoCompactionBlueprint) Validate() error {
+	if a.Disabled && (a.Database != nil || a.View != nil) {
+		return fmt.Errorf("fragmentation thresholds can't be provided when auto-compaction is disabled")
+	}
+
+	err := a.Database.validate()
+	if err != nil {
+		return fmt.Errorf("invalid database threshold: %w", err)
+	}
+
+	err = a.View.validate()
+	if err != nil {
+		return fmt.Errorf("invalid view threshold: %w", err)
+	}
+
+	if a.TimeWindow != nil {
+		return a.TimeWindow.Validate()
+	}
+
+	return nil
+}
+
+// AutoCompactionSettings are the effective cluster-wide auto-compaction settings, read back from the cluster once the
+// auto-compaction blueprint has been applied.
+type AutoCompactionSettings struct {
+	// Database/View are the fragmentation thresholds, nil when auto-compaction is disabled for the files.
+	Database *FragmentationThreshold `json:"database,omitempty"`
+	View     *FragmentationThreshold `json:"view,omitempty"`
+
+	// TimeWindow is the period in which auto-compaction may run, nil when it may run at any time.
+	TimeWindow *CompactionTimeWindow `json:"time_window,omitempty"`
+
+	Parallel bool `json:"parallel_compaction"`
+
+	// PurgeInterval is the number of days after which tombstones are purged.
+	PurgeInterval float64 `json:"purge_interval_days,omitempty"`
+
+	// IndexMode/IndexPercentage are the index service compaction mode and fragmentation threshold, empty for clusters
+	// which don't report them.
+	IndexMode       string `json:"index_compaction_mode,omitempty"`
+	IndexPercentage int    `json:"index_percentage,omitempty"`
+}
+
+// String returns a string representation of the effective auto-compaction settings which will be output in the
+// report.
+func (a *AutoCompactionSettings) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	threshold := func(threshold *FragmentationThreshold) string {
+		if threshold == nil {
+			return "disabled"
+		}
+
+		return threshold.String()
+	}
+
+	window := "any time"
+	if a.TimeWindow != nil {
+		window = a.TimeWindow.String()
+	}
+
+	index := a.IndexMode
+	if a.IndexPercentage != 0 {
+		index += fmt.Sprintf(" (%d%%)", a.IndexPercentage)
+	}
+
+	fmt.Fprintln(buffer, "| Auto Compaction\n| ---------------")
+	fmt.Fprintf(writer, "| Database Threshold\t View Threshold\t Time Window\t Parallel\t Purge Interval\t Index\t\n")
+	fmt.Fprintf(writer, "| %s\t %s\t %s\t %t\t %s days\t %s\t\n", threshold(a.Database), threshold(a.View), window,
+		a.Parallel, strconv.FormatFloat(a.PurgeInterval, 'f', -1, 64), index)
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
+
+// FragmentationThreshold is the point at which auto-compaction will be triggered, either as a percentage of the file
+// which is fragmented or as an absolute size (whichever is reached first).
+type FragmentationThreshold struct {
+	Percentage int    `json:"percentage,omitempty" yaml:"percentage,omitempty"`
+	SizeMB     uint64 `json:"size_mb,omitempty" yaml:"size_mb,omitempty"`
+}
+
+// effective returns the threshold with any defaults applied.
+func (f *FragmentationThreshold) effective() *FragmentationThreshold {
+	if f == nil {
+		return &FragmentationThreshold{Percentage: DefaultFragmentationPercentage}
+	}
+
+	if f.Percentage == 0 && f.SizeMB == 0 {
+		return &FragmentationThreshold{Percentage: DefaultFragmentationPercentage}
+	}
+
+	return f
+}
+
+// validate returns an error if the threshold is invalid.
+func (f *FragmentationThreshold) validate() error {
+	if f == nil || f.Percentage == 0 {
+		return nil
+	}
+
+	if f.Percentage < 2 || f.Percentage > 100 {
+		return fmt.Errorf("percentage must be between 2 and 100, got %d", f.Percentage)
+	}
+
+	return nil
+}
+
+// String returns a string representation of the threshold which will be output in the report.
+func (f *FragmentationThreshold) String() string {
+	var thresholds []string
+
+	if f.Percentage != 0 {
+		thresholds = append(thresholds, strconv.Itoa(f.Percentage)+"%")
+	}
+
+	if f.SizeMB != 0 {
+		thresholds = append(thresholds, fmt.Sprintf("%dMB", f.SizeMB))
+	}
+
+	return strings.Join(thresholds, " or ")
+}
+
+// CompactionTimeWindow is the period of the day (in the cluster's local time) in which auto-compaction is allowed to
+// run.
+type CompactionTimeWindow struct {
+	// From/To are the start/end of the time window in the format 'HH:MM'.
+	From string `json:"from,omitempty" yaml:"from,omitempty"`
+	To   string `json:"to,omitempty" yaml:"to,omitempty"`
+
+	// AbortOutside indicates whether compactions which are still running at the end of the window should be aborted.
+	AbortOutside bool `json:"abort_outside,omitempty" yaml:"abort_outside,omitempty"`
+}
+
+// Validate returns an error if the time window is invalid.
+func (c *CompactionTimeWindow) Validate() error {
+	for _, clock := range []string{c.From, c.To} {
+		_, err := time.Parse("15:04", clock)
+		if err != nil {
+			return fmt.Errorf("invalid time window '%s', expected the format 'HH:MM'", clock)
+		}
+	}
+
+	return nil
+}
+
+// FromTime returns the hour/minute at which the time window starts.
+func (c *CompactionTimeWindow) FromTime() (int, int) {
+	return parseClock(c.From)
+}
+
+// ToTime returns the hour/minute at which the time window ends.
+func (c *CompactionTimeWindow) ToTime() (int, int) {
+	return parseClock(c.To)
+}
+
+// String returns a string representation of the time window which will be output in the report.
+func (c *CompactionTimeWindow) String() string {
+	window := c.From + "-" + c.To
+	if c.AbortOutside {
+		window += " (abort outside)"
+	}
+
+	return window
+}
+
+// parseClock returns the hour/minute from the given 'HH:MM' clock, the clock should have already been validated.
+func parseClock(clock string) (int, int) {
+	parsed, _ := time.Parse("15:04", clock)
+	return parsed.Hour(), parsed.Minute()
+}
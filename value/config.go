@@ -14,10 +14,150 @@
 
 package value
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// redacted is the value which replaces any secrets when the config is included in the report.
+const redacted = "<redacted>"
+
 // AutobenchConfig encapsulates the options which can be used to configure 'cbtools-authbench' and the benchmarks that
 // is performs. By default the config file is read from disk in the YAML format.
 type AutobenchConfig struct {
 	SSHConfig       *SSHConfig       `yaml:"ssh,omitempty"`
 	Blueprint       *Blueprint       `yaml:"blueprint,omitempty"`
 	BenchmarkConfig *BenchmarkConfig `yaml:"benchmark,omitempty"`
+
+	// Sinks is the list of destinations which the benchmarking report will be written to, when omitted the report is
+	// written to stdout.
+	Sinks []*SinkConfig `yaml:"sinks,omitempty"`
+
+	// Schedule describes the recurring benchmarks which will be run by the 'schedule' sub-command.
+	Schedule *ScheduleConfig `yaml:"schedule,omitempty"`
+
+	// Matrix describes the cluster/backup client versions which will be benchmarked by the 'matrix' sub-command.
+	Matrix *MatrixConfig `yaml:"matrix,omitempty"`
+
+	// NodeScaling describes the cluster sizes which will be benchmarked by the 'scale' sub-command.
+	NodeScaling *NodeScalingConfig `yaml:"node_scaling,omitempty"`
+}
+
+// Validate returns an error if the benchmark config uses features which aren't supported by the data blueprint, the
+// integrity export and data deletions only operate on the default collection.
+func (a *AutobenchConfig) Validate() error {
+	if a.BenchmarkConfig == nil || a.Blueprint == nil || a.Blueprint.Cluster == nil ||
+		a.Blueprint.Cluster.Bucket == nil || a.Blueprint.Cluster.Bucket.Data == nil ||
+		a.Blueprint.Cluster.Bucket.Data.Collections == nil {
+		return nil
+	}
+
+	if a.BenchmarkConfig.Deletions != nil {
+		return errors.New("deleting data is not supported when the dataset is loaded into collections")
+	}
+
+	if a.BenchmarkConfig.VerifyIntegrity {
+		return errors.New("verifying integrity is not supported when the dataset is loaded into collections")
+	}
+
+	return nil
+}
+
+// Redacted returns a copy of the config where any secrets (passphrases, credentials, URLs which may contain tokens etc)
+// have been redacted, so that it may be safely included in the report.
+func (a *AutobenchConfig) Redacted() *AutobenchConfig {
+	config := *a
+
+	config.SSHConfig = a.SSHConfig.Redacted()
+
+	if a.Blueprint != nil {
+		config.Blueprint = a.Blueprint.redacted()
+	}
+
+	if a.BenchmarkConfig != nil && a.BenchmarkConfig.CBMConfig != nil {
+		var (
+			benchmark = *a.BenchmarkConfig
+			cbm       = *a.BenchmarkConfig.CBMConfig
+		)
+
+		cbm.ObjAccessKeyID = redact(cbm.ObjAccessKeyID)
+		cbm.ObjSecretAccessKey = redact(cbm.ObjSecretAccessKey)
+		cbm.ObjSessionToken = redact(cbm.ObjSessionToken)
+		cbm.Passphrase = redact(cbm.Passphrase)
+		cbm.KMAccessKeyID = redact(cbm.KMAccessKeyID)
+		cbm.KMSecretAccessKey = redact(cbm.KMSecretAccessKey)
+		cbm.ClientKeyPassword = redact(cbm.ClientKeyPassword)
+
+		if cbm.EnvVars != nil {
+			cbm.EnvVars = make(CBMEnvironment, len(a.BenchmarkConfig.CBMConfig.EnvVars))
+
+			for key, value := range a.BenchmarkConfig.CBMConfig.EnvVars {
+				if isSecret(key) {
+					value = redact(value)
+				}
+
+				cbm.EnvVars[key] = value
+			}
+		}
+
+		benchmark.CBMConfig = &cbm
+		config.BenchmarkConfig = &benchmark
+	}
+
+	if a.Sinks != nil {
+		config.Sinks = make([]*SinkConfig, 0, len(a.Sinks))
+
+		for _, sink := range a.Sinks {
+			copied := *sink
+			copied.URL = redact(copied.URL)
+			config.Sinks = append(config.Sinks, &copied)
+		}
+	}
+
+	if a.Schedule != nil {
+		schedule := *a.Schedule
+		schedule.NotifyURL = redact(schedule.NotifyURL)
+		config.Schedule = &schedule
+	}
+
+	return &config
+}
+
+// Canonical returns the redacted config in YAML format, along with its SHA-256 hash. Configs which only differ by their
+// formatting/ordering in the original config file will result in the same output.
+func (a *AutobenchConfig) Canonical() (string, string, error) {
+	encoded, err := yaml.Marshal(a.Redacted())
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to marshal config")
+	}
+
+	hash := sha256.Sum256(encoded)
+
+	return string(encoded), hex.EncodeToString(hash[:]), nil
+}
+
+// redact returns the redacted placeholder for non-empty values.
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+// isSecret returns a boolean indicating whether the given environment variable is likely to contain a secret.
+func isSecret(key string) bool {
+	key = strings.ToUpper(key)
+
+	for _, keyword := range []string{"SECRET", "PASSWORD", "PASSPHRASE", "TOKEN", "KEY"} {
+		if strings.Contains(key, keyword) {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,85 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultUsername is the username of the cluster administrator when no credentials are configured.
+	DefaultUsername = "Administrator"
+
+	// DefaultPassword is the password of the cluster administrator when no credentials are configured.
+	DefaultPassword = "asdasd"
+)
+
+// Credentials are the cluster administrator credentials used by the CLI tools, REST API requests and 'cbbackupmgr';
+// when provisioning, the cluster will be initialized using these credentials.
+type Credentials struct {
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+// GetUsername returns the username of the cluster administrator.
+func (c *Credentials) GetUsername() string {
+	if c == nil || c.Username == "" {
+		return DefaultUsername
+	}
+
+	return c.Username
+}
+
+// GetPassword returns the password of the cluster administrator.
+func (c *Credentials) GetPassword() string {
+	if c == nil || c.Password == "" {
+		return DefaultPassword
+	}
+
+	return c.Password
+}
+
+// QuotedUsername returns the username quoted so that it may be safely used in a shell command.
+func (c *Credentials) QuotedUsername() string {
+	return shellQuote(c.GetUsername())
+}
+
+// QuotedPassword returns the password quoted so that it may be safely used in a shell command.
+func (c *Credentials) QuotedPassword() string {
+	return shellQuote(c.GetPassword())
+}
+
+// Flags returns the '-u'/'-p' flags accepted by most of the Couchbase Server tools (e.g. 'couchbase-cli').
+func (c *Credentials) Flags() string {
+	return fmt.Sprintf("-u %s -p %s", c.QuotedUsername(), c.QuotedPassword())
+}
+
+// Redacted returns a copy of the credentials where the password has been redacted.
+func (c *Credentials) Redacted() *Credentials {
+	if c == nil {
+		return nil
+	}
+
+	credentials := *c
+	credentials.Password = redact(credentials.Password)
+
+	return &credentials
+}
+
+// shellQuote returns the given value wrapped in single quotes, escaping any single quotes it contains.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
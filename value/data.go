@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"strconv"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/couchbase/tools-common/strings/format"
@@ -33,6 +34,31 @@ const (
 	Pillowfight DataLoaderType = "pillowfight"
 )
 
+// KeyDistribution describes the keys generated when loading the benchmarking data.
+type KeyDistribution string
+
+const (
+	// KeyDistributionSequential generates keys using a prefix followed by a monotonically increasing number.
+	KeyDistributionSequential KeyDistribution = "sequential"
+
+	// KeyDistributionUUID generates keys using a prefix followed by a random UUID, loaded using 'cbimport'.
+	KeyDistributionUUID KeyDistribution = "uuid"
+)
+
+// MaxKeySize is the maximum size of a document key supported by Couchbase Server.
+const MaxKeySize = 250
+
+// DurabilityLevel is the durability level used when writing the benchmarking data, see the 'cbc-pillowfight'
+// '--durability' flag for more information.
+type DurabilityLevel string
+
+const (
+	DurabilityNone                     DurabilityLevel = "none"
+	DurabilityMajority                 DurabilityLevel = "majority"
+	DurabilityMajorityAndPersistActive DurabilityLevel = "majority_and_persist_to_active"
+	DurabilityPersistMajority          DurabilityLevel = "persist_to_majority"
+)
+
 // DataBlueprint encapsulates all the options available when populating a bucket with benchmarking data.
 type DataBlueprint struct {
 	DataLoader   DataLoaderType `json:"data_loader,omitempty" yaml:"data_loader,omitempty"`
@@ -41,6 +67,154 @@ type DataBlueprint struct {
 	Size         int            `json:"size,omitempty" yaml:"size,omitempty"`
 	Compressible bool           `json:"compressible,omitempty" yaml:"compressible,omitempty"`
 	LoadThreads  int            `json:"load_threads,omitempty" yaml:"load_threads,omitempty"`
+
+	// Durability is the durability level used when loading/mutating the dataset. Note that 'cbbackupmgr' doesn't
+	// support durable writes, so 'cbc-pillowfight' will be used to populate the bucket when a level is set.
+	Durability DurabilityLevel `json:"durability,omitempty" yaml:"durability,omitempty"`
+
+	// KeyPrefix is prepended to every generated key; note that a unique component is still appended to the prefix to
+	// avoid keys colliding when loading from multiple nodes.
+	KeyPrefix string `json:"key_prefix,omitempty" yaml:"key_prefix,omitempty"`
+
+	// KeySize is the target length of each key, the prefix is padded so that keys reach (approximately, since the
+	// loaders append a variable length number) this length. Keys are never truncated.
+	KeySize int `json:"key_size,omitempty" yaml:"key_size,omitempty"`
+
+	// KeyDistribution controls how keys are generated i.e. sequential/uuid, defaults to sequential.
+	KeyDistribution KeyDistribution `json:"key_distribution,omitempty" yaml:"key_distribution,omitempty"`
+
+	// AllowShortLoad logs a warning, rather than failing, when the bucket contains fewer items than expected once the
+	// data has been loaded.
+	AllowShortLoad bool `json:"-" yaml:"allow_short_load,omitempty"`
+
+	// Workload shapes the operations performed when using the 'pillowfight' data loader.
+	Workload PillowfightWorkload `json:"workload,omitempty" yaml:"workload,omitempty"`
+
+	// Collections describes the scopes/collections the dataset is distributed between, when unset the dataset is
+	// loaded into the default collection.
+	Collections *CollectionsBlueprint `json:"collections,omitempty" yaml:"collections,omitempty"`
+}
+
+// PillowfightWorkload encapsulates the options used to shape the operations performed by 'cbc-pillowfight' when it's
+// used to load/mutate the dataset (e.g. for PiTR/history benchmarks).
+type PillowfightWorkload struct {
+	// SetPercentage is the percentage of operations which are writes, the remainder are reads; defaults to 100.
+	SetPercentage int `json:"set_percentage,omitempty" yaml:"set_percentage,omitempty"`
+
+	// RandomAccess accesses the active items in a random order, rather than sequentially.
+	RandomAccess bool `json:"random_access,omitempty" yaml:"random_access,omitempty"`
+
+	// ExpiryPercentage is the percentage of the active items which are written with the expiry below.
+	ExpiryPercentage int `json:"expiry_percentage,omitempty" yaml:"expiry_percentage,omitempty"`
+
+	// Expiry is the expiry (in seconds) applied to the expiring items.
+	Expiry int `json:"expiry,omitempty" yaml:"expiry,omitempty"`
+}
+
+// Sets returns the percentage of operations which are writes.
+func (p PillowfightWorkload) Sets() int {
+	if p.SetPercentage == 0 {
+		return 100
+	}
+
+	return p.SetPercentage
+}
+
+// Expiring returns the number of the given active items which should be written with an expiry.
+func (p PillowfightWorkload) Expiring(items int) int {
+	if p.Expiry == 0 {
+		return 0
+	}
+
+	return items * p.ExpiryPercentage / 100
+}
+
+// Validate returns an error if the workload is invalid.
+func (p PillowfightWorkload) Validate() error {
+	if p.SetPercentage < 0 || p.SetPercentage > 100 {
+		return fmt.Errorf("set percentage must be between 0 and 100, got %d", p.SetPercentage)
+	}
+
+	if p.ExpiryPercentage < 0 || p.ExpiryPercentage > 100 {
+		return fmt.Errorf("expiry percentage must be between 0 and 100, got %d", p.ExpiryPercentage)
+	}
+
+	if p.ExpiryPercentage != 0 && p.Expiry <= 0 {
+		return fmt.Errorf("an expiry must be provided when the expiry percentage is non-zero")
+	}
+
+	return nil
+}
+
+// String returns a string representation of the workload which will be output in the report.
+func (p PillowfightWorkload) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	access := "sequential"
+	if p.RandomAccess {
+		access = "random"
+	}
+
+	expiry := "N/A"
+	if p.Expiry != 0 && p.ExpiryPercentage != 0 {
+		expiry = fmt.Sprintf("%d%% (%ds)", p.ExpiryPercentage, p.Expiry)
+	}
+
+	fmt.Fprintln(buffer, "| Workload\n| --------")
+	fmt.Fprintf(writer, "| Sets\t Gets\t Access\t Expiry\t\n")
+	fmt.Fprintf(writer, "| %d%%\t %d%%\t %s\t %s\t\n", p.Sets(), 100-p.Sets(), access, expiry)
+
+	_ = writer.Flush()
+
+	return buffer.String()
+}
+
+// ExpectedItems returns the number of items the bucket should contain once the data has been loaded. The items are
+// split between the nodes, which load unique keys, except when using 'cbc-pillowfight' where every node mutates the
+// same active items.
+//
+// NOTE: Expiring items aren't expected, since they may have been expired by the time the item count is checked.
+func (d *DataBlueprint) ExpectedItems() uint64 {
+	if d.DataLoader == Pillowfight {
+		return uint64(d.ActiveItems - d.Workload.Expiring(d.ActiveItems))
+	}
+
+	return uint64(d.Items)
+}
+
+// Prefix returns the key prefix which should be passed to the data loader. The given unique component (whose length
+// once expanded by the shell is 'length') is appended to the configured prefix, which is padded so that keys reach the
+// configured size once the loader has appended a suffix of the given length.
+func (d *DataBlueprint) Prefix(unique string, length, suffix int) string {
+	padding := d.KeySize - len(d.KeyPrefix) - length - suffix
+
+	return d.KeyPrefix + strings.Repeat("x", max(0, padding)) + unique
+}
+
+// UUIDKeys returns a boolean indicating whether keys should be generated using random UUIDs.
+func (d *DataBlueprint) UUIDKeys() bool {
+	return d.KeyDistribution == KeyDistributionUUID
+}
+
+// Durable returns a boolean indicating whether the dataset should be written using durable writes.
+func (d *DataBlueprint) Durable() bool {
+	return d.Durability != "" && d.Durability != DurabilityNone
+}
+
+// Loader returns the name of the tool which will be used to load the dataset, accounting for the cases where the
+// 'cbbackupmgr' data loader falls back to another tool.
+func (d *DataBlueprint) Loader() string {
+	switch {
+	case d.DataLoader == Pillowfight, d.Durable():
+		return "cbc-pillowfight"
+	case d.UUIDKeys():
+		return "cbimport"
+	}
+
+	return "cbbackupmgr"
 }
 
 // String returns a string representation of the blueprint which will be output in the report.
@@ -60,17 +234,44 @@ func (d *DataBlueprint) String() string {
 		activeItems = message.NewPrinter(language.English).Sprintf("%d", d.ActiveItems)
 	}
 
+	keySize := "auto"
+	if d.KeySize != 0 {
+		keySize = strconv.Itoa(d.KeySize)
+	}
+
+	keys := KeyDistributionSequential
+	if d.KeyDistribution != "" {
+		keys = d.KeyDistribution
+	}
+
+	durability := DurabilityNone
+	if d.Durable() {
+		durability = d.Durability
+	}
+
 	fmt.Fprintln(buffer, "| Data\n| ----")
-	fmt.Fprintf(writer, "| Data Loader\t Items\t Active Items\t Size\t Compressible\t Load Threads\t\n")
-	fmt.Fprintf(writer, "| %s\t %s\t %s\t %s\t %t\t %s\t\n",
+	fmt.Fprintf(writer, "| Data Loader\t Items\t Active Items\t Size\t Compressible\t Load Threads\t Durability\t "+
+		"Key Size\t Keys\t\n")
+	fmt.Fprintf(writer, "| %s\t %s\t %s\t %s\t %t\t %s\t %s\t %s\t %s\t\n",
 		d.DataLoader,
 		message.NewPrinter(language.English).Sprintf("%d", d.Items),
 		activeItems,
 		format.Bytes(uint64(d.Size)),
 		d.Compressible,
-		threads)
+		threads,
+		durability,
+		keySize,
+		keys)
 
 	_ = writer.Flush()
 
+	if d.DataLoader == Pillowfight {
+		fmt.Fprintf(buffer, "\n%s", d.Workload)
+	}
+
+	if d.Collections != nil {
+		fmt.Fprintf(buffer, "\n%s", d.Collections)
+	}
+
 	return buffer.String()
 }
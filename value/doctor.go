@@ -0,0 +1,78 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// CheckPassed indicates that a preflight check passed.
+	CheckPassed = "PASS"
+
+	// CheckFailed indicates that a preflight check failed, provisioning/benchmarking is likely to fail.
+	CheckFailed = "FAIL"
+
+	// CheckSkipped indicates that a preflight check wasn't run, for example because the host was unreachable.
+	CheckSkipped = "SKIP"
+)
+
+// Check is the result of a single preflight check run against a host by the 'doctor' sub-command.
+type Check struct {
+	Host   string `json:"host"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Checks is the list of preflight checks run by the 'doctor' sub-command.
+type Checks []*Check
+
+// Failed returns the number of checks which failed.
+func (c Checks) Failed() int {
+	var failed int
+
+	for _, check := range c {
+		if check.Status == CheckFailed {
+			failed++
+		}
+	}
+
+	return failed
+}
+
+// String returns a human readable pass/fail table of the checks.
+func (c Checks) String() string {
+	var (
+		buffer = &bytes.Buffer{}
+		writer = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+	)
+
+	fmt.Fprintln(buffer, "| Doctor\n| ------")
+	fmt.Fprintf(writer, "| Host\t Check\t Status\t Detail\t\n")
+
+	for _, check := range c {
+		fmt.Fprintf(writer, "| %s\t %s\t %s\t %s\t\n", check.Host, check.Name, check.Status, check.Detail)
+	}
+
+	_ = writer.Flush()
+
+	fmt.Fprintf(buffer, "| %d/%d check(s) failed\n", c.Failed(), len(c))
+
+	return strings.TrimSpace(buffer.String())
+}
@@ -0,0 +1,30 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+// Hardware describes the machine a cluster node/backup client is running on, it's included in the report so that the
+// environments of reports may be compared.
+type Hardware struct {
+	Host string `json:"host"`
+
+	// CPUs is the number of processing units available, as reported by 'nproc'.
+	CPUs uint64 `json:"cpus"`
+
+	// Memory is the total memory of the machine in KiB, as reported by '/proc/meminfo'.
+	Memory uint64 `json:"memory_kib"`
+
+	// OS is the pretty name of the operating system, as reported by '/etc/os-release'.
+	OS string `json:"os"`
+}
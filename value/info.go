@@ -18,4 +18,52 @@ package value
 type BackupInfo struct {
 	BackupSize uint64
 	ItemsNum   uint64
+
+	// DiskSize is the size of the backup on disk (or in the object store for cloud archives), zero when it couldn't be
+	// determined.
+	DiskSize uint64
+
+	// Objects is the number of objects in the object store which make up the backup, zero for local archives.
+	Objects uint64
+
+	// Details is the structured information about the backup which will be included in the report.
+	Details *BackupDetails
+}
+
+// BackupDetails is the structured information about a single backup, exposed in the JSON report for downstream
+// analysis.
+type BackupDetails struct {
+	Date    string           `json:"date"`
+	Type    string           `json:"type,omitempty"`
+	Size    uint64           `json:"size"`
+	Files   int              `json:"files,omitempty"`
+	Shards  int              `json:"shards,omitempty"`
+	Buckets []*BucketDetails `json:"buckets,omitempty"`
+
+	// Metadata is what 'cbbackupmgr' recorded about the backup in the archive, nil when it couldn't be read.
+	Metadata *BackupMetadata `json:"metadata,omitempty"`
+}
+
+// BackupMetadata is parsed from the repository/backup metadata written by 'cbbackupmgr', reflecting what was actually
+// done rather than what was requested. Values which aren't present in the metadata (e.g. for older versions) are
+// omitted.
+type BackupMetadata struct {
+	StorageVersion int    `json:"storage_version,omitempty"`
+	Storage        string `json:"storage,omitempty"`
+	Shards         int    `json:"shards,omitempty"`
+	Encrypted      *bool  `json:"encrypted,omitempty"`
+	Compressed     *bool  `json:"compressed,omitempty"`
+	PointInTime    *bool  `json:"point_in_time,omitempty"`
+}
+
+// BucketDetails is the per-bucket breakdown of a single backup.
+type BucketDetails struct {
+	Name       string `json:"name"`
+	Size       uint64 `json:"size"`
+	Items      uint64 `json:"items"`
+	Tombstones uint64 `json:"tombstones"`
+	Views      uint64 `json:"views"`
+	FTS        uint64 `json:"fts_indexes"`
+	Indexes    uint64 `json:"gsi_indexes"`
+	Analytics  uint64 `json:"analytics"`
 }
@@ -0,0 +1,57 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import "path/filepath"
+
+// CBPaths describes where Couchbase Server is installed, allowing non-standard installs (e.g. tarball deployments).
+type CBPaths struct {
+	// InstallDirectory is the directory Couchbase Server is installed into, defaults to 'CBInstallDirectory'.
+	InstallDirectory string `json:"install_directory,omitempty" yaml:"install_directory,omitempty"`
+
+	// BinDirectory is the directory containing the Couchbase Server binaries, defaults to the 'bin' directory in the
+	// install directory.
+	BinDirectory string `json:"bin_directory,omitempty" yaml:"bin_directory,omitempty"`
+}
+
+// Install returns the install directory.
+func (c CBPaths) Install() string {
+	if c.InstallDirectory == "" {
+		return CBInstallDirectory
+	}
+
+	return c.InstallDirectory
+}
+
+// Bin returns the bin directory.
+func (c CBPaths) Bin() string {
+	switch {
+	case c.BinDirectory != "":
+		return c.BinDirectory
+	case c.InstallDirectory != "":
+		return filepath.Join(c.InstallDirectory, "bin")
+	}
+
+	return CBBinDirectory
+}
+
+// Resolve returns the given path, relative paths are resolved against the install directory.
+func (c CBPaths) Resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(c.Install(), path)
+}
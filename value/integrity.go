@@ -0,0 +1,36 @@
+// Copyright 2022 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+// Integrity is the outcome of verifying the documents in the bucket after a restore against those which were in the
+// benchmarking bucket prior to it being backed up.
+type Integrity struct {
+	// Documents is the number of documents in the bucket after the restore.
+	Documents uint64
+
+	// Expected is the number of documents which were recorded prior to the backup.
+	Expected uint64
+
+	// Mismatched is the number of documents which are missing, unexpected or whose contents differ.
+	Mismatched uint64
+
+	// Examples are the keys of (up to ten of) the mismatched documents.
+	Examples []string
+}
+
+// Verified returns a boolean indicating whether the restored documents exactly match those which were recorded.
+func (i *Integrity) Verified() bool {
+	return i.Mismatched == 0 && i.Documents == i.Expected
+}
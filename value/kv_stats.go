@@ -0,0 +1,103 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+// KVStats is a snapshot of some key KV stats aggregated across all the nodes in the cluster, these are captured
+// before/after each benchmark iteration to help explain any anomalies in the results.
+type KVStats struct {
+	// DiskWriteQueue is the number of items waiting to be persisted to disk.
+	DiskWriteQueue uint64
+
+	// DCPBacklog is the number of items remaining to be sent across all DCP connections.
+	DCPBacklog uint64
+
+	// BGFetched is the number of items which have been fetched from disk, this is a counter.
+	BGFetched uint64
+
+	// Compacting indicates whether a compaction was running on the cluster.
+	Compacting bool
+}
+
+// Add the stats from a single node to the aggregate stats.
+func (k *KVStats) Add(other *KVStats) {
+	k.DiskWriteQueue += other.DiskWriteQueue
+	k.DCPBacklog += other.DCPBacklog
+	k.BGFetched += other.BGFetched
+	k.Compacting = k.Compacting || other.Compacting
+}
+
+// DCPSample is a single sample of the DCP stats for the 'cbbackupmgr' connection(s) aggregated across the cluster.
+type DCPSample struct {
+	// ItemsRemaining is the number of items remaining to be sent to 'cbbackupmgr'.
+	ItemsRemaining uint64
+
+	// BackfillBytes is the number of bytes which have been backfilled from disk but not yet sent to 'cbbackupmgr'.
+	BackfillBytes uint64
+
+	// Connections/Streams are the number of DCP connections/streams opened by 'cbbackupmgr'.
+	Connections uint64
+	Streams     uint64
+
+	// MemcachedConnections is the total number of connections to memcached, from all clients.
+	MemcachedConnections uint64
+}
+
+// DCPSummary summarizes the DCP samples taken during a single benchmark iteration.
+type DCPSummary struct {
+	Samples           int
+	MaxItemsRemaining uint64
+	MaxBackfillBytes  uint64
+
+	// MaxConnections/MaxStreams/MaxMemcachedConnections are the peak connection/stream counts, which help to identify
+	// changes in stream fan-out between builds.
+	MaxConnections          uint64
+	MaxStreams              uint64
+	MaxMemcachedConnections uint64
+
+	// TotalItemsRemaining/TotalBackfillBytes are used to calculate the averages, they're exported so that the summary
+	// survives being persisted to disk.
+	TotalItemsRemaining uint64
+	TotalBackfillBytes  uint64
+}
+
+// Add the given sample to the summary.
+func (d *DCPSummary) Add(sample *DCPSample) {
+	d.Samples++
+	d.MaxItemsRemaining = max(d.MaxItemsRemaining, sample.ItemsRemaining)
+	d.MaxBackfillBytes = max(d.MaxBackfillBytes, sample.BackfillBytes)
+	d.MaxConnections = max(d.MaxConnections, sample.Connections)
+	d.MaxStreams = max(d.MaxStreams, sample.Streams)
+	d.MaxMemcachedConnections = max(d.MaxMemcachedConnections, sample.MemcachedConnections)
+	d.TotalItemsRemaining += sample.ItemsRemaining
+	d.TotalBackfillBytes += sample.BackfillBytes
+}
+
+// AvgItemsRemaining returns the average number of items remaining across all the samples.
+func (d *DCPSummary) AvgItemsRemaining() uint64 {
+	if d.Samples == 0 {
+		return 0
+	}
+
+	return d.TotalItemsRemaining / uint64(d.Samples)
+}
+
+// AvgBackfillBytes returns the average number of backfilled bytes across all the samples.
+func (d *DCPSummary) AvgBackfillBytes() uint64 {
+	if d.Samples == 0 {
+		return 0
+	}
+
+	return d.TotalBackfillBytes / uint64(d.Samples)
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/couchbase/tools-common/strings/format"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// LoadSummary describes the loading of the benchmarking dataset, the load performance is itself a useful signal (for
+// example, it may catch regressions in 'cbbackupmgr generate').
+type LoadSummary struct {
+	DataLoader string
+	Items      uint64
+	Bytes      uint64
+	Duration   time.Duration
+}
+
+// ItemsPerSecond returns the average number of items loaded per second.
+func (l *LoadSummary) ItemsPerSecond() uint64 {
+	if l.Duration < time.Second {
+		return l.Items
+	}
+
+	return uint64(float64(l.Items) / l.Duration.Seconds())
+}
+
+// BytesPerSecond returns the average number of bytes loaded per second.
+func (l *LoadSummary) BytesPerSecond() uint64 {
+	if l.Duration < time.Second {
+		return l.Bytes
+	}
+
+	return uint64(float64(l.Bytes) / l.Duration.Seconds())
+}
+
+// loadSummaryJSON is the JSON representation of the load summary which is included in the report.
+type loadSummaryJSON struct {
+	DataLoader     string `json:"data_loader,omitempty"`
+	Items          uint64 `json:"items"`
+	Size           string `json:"size"`
+	Duration       string `json:"duration"`
+	ItemsPerSecond uint64 `json:"items_per_second"`
+	TransferRate   string `json:"transfer_rate"`
+}
+
+// JSONShape returns a value with the same shape as the JSON representation of the load summary, used to generate the
+// schema of the report.
+func (l *LoadSummary) JSONShape() any {
+	return loadSummaryJSON{}
+}
+
+// MarshalJSON returns a JSON representation of the load summary with raw values converted into human readable strings.
+func (l *LoadSummary) MarshalJSON() ([]byte, error) {
+	return json.Marshal(loadSummaryJSON{
+		DataLoader:     l.DataLoader,
+		Items:          l.Items,
+		Size:           format.Bytes(l.Bytes),
+		Duration:       format.Duration(l.Duration),
+		ItemsPerSecond: l.ItemsPerSecond(),
+		TransferRate:   format.Bytes(l.BytesPerSecond()) + "/s",
+	})
+}
+
+// String returns a string representation of the load summary which will be output in the report.
+func (l *LoadSummary) String() string {
+	var (
+		buffer  = &bytes.Buffer{}
+		writer  = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+		printer = message.NewPrinter(language.English)
+	)
+
+	fmt.Fprintln(buffer, "| Data Load\n| ---------")
+	fmt.Fprintf(writer, "| Data Loader\t Items\t Size\t Duration\t Items/s\t Transfer Rate\t\n")
+	fmt.Fprintf(writer, "| %s\t %s\t %s\t %s\t %s\t %s/s\t\n",
+		l.DataLoader,
+		printer.Sprintf("%d", l.Items),
+		format.Bytes(l.Bytes),
+		format.Duration(l.Duration),
+		printer.Sprintf("%d", l.ItemsPerSecond()),
+		format.Bytes(l.BytesPerSecond()))
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import "fmt"
+
+// MatrixConfig describes a cross-version compatibility matrix, used by the 'matrix' sub-command to provision/benchmark
+// every combination of the given cluster/backup client packages.
+type MatrixConfig struct {
+	// ClusterPackages is the list of packages which will be installed on the cluster nodes, overriding the package in
+	// the cluster blueprint.
+	ClusterPackages []string `yaml:"cluster_packages,omitempty"`
+
+	// BackupClientPackages is the list of packages which will be installed on the backup client, overriding the package
+	// in the backup client blueprint.
+	BackupClientPackages []string `yaml:"backup_client_packages,omitempty"`
+}
+
+// Pairs returns every combination of cluster/backup client package, grouped by the cluster package so that the cluster
+// only needs to be provisioned once per version.
+func (m *MatrixConfig) Pairs() []*MatrixPair {
+	pairs := make([]*MatrixPair, 0, len(m.ClusterPackages)*len(m.BackupClientPackages))
+
+	for _, cluster := range m.ClusterPackages {
+		for _, backupClient := range m.BackupClientPackages {
+			pairs = append(pairs, &MatrixPair{ClusterPackage: cluster, BackupClientPackage: backupClient})
+		}
+	}
+
+	return pairs
+}
+
+// MatrixPair is a single cluster/backup client package combination from the compatibility matrix.
+type MatrixPair struct {
+	ClusterPackage      string
+	BackupClientPackage string
+}
+
+// ClusterVersion returns the build of Couchbase Server installed on the cluster nodes.
+func (m *MatrixPair) ClusterVersion() string {
+	return extractBuild(m.ClusterPackage)
+}
+
+// BackupVersion returns the build of 'cbbackupmgr' installed on the backup client.
+func (m *MatrixPair) BackupVersion() string {
+	return extractBuild(m.BackupClientPackage)
+}
+
+// Name returns the name used to label the benchmark results for this pair.
+func (m *MatrixPair) Name() string {
+	return fmt.Sprintf("cluster=%s, cbbackupmgr=%s", m.ClusterVersion(), m.BackupVersion())
+}
+
+// MatrixResult is the outcome of benchmarking a single cluster/backup client pair.
+type MatrixResult struct {
+	Pair *MatrixPair
+
+	// Err is the error which caused provisioning/benchmarking the pair to fail, nil if it was successful.
+	Err error
+
+	// Results are the benchmark results for the pair, labelled using the name of the pair.
+	Results BenchmarkResults
+}
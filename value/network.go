@@ -0,0 +1,43 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import "time"
+
+// Bandwidth is the raw network throughput measured between the backup client and a single cluster node.
+type Bandwidth struct {
+	// Host is the hostname of the cluster node.
+	Host string
+
+	// BitsPerSecond is the throughput received by the backup client from the cluster node.
+	BitsPerSecond uint64
+}
+
+// BytesPerSecond returns the measured throughput in bytes per second, allowing it to be compared with transfer rates.
+func (b *Bandwidth) BytesPerSecond() uint64 {
+	return b.BitsPerSecond / 8
+}
+
+// Latency is the average round trip time measured from one host to another.
+type Latency struct {
+	// Source is the hostname of the machine which sent the pings.
+	Source string
+
+	// Target is the hostname of the machine which was pinged.
+	Target string
+
+	// RTT is the average round trip time.
+	RTT time.Duration
+}
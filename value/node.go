@@ -14,8 +14,51 @@
 
 package value
 
+import (
+	"net"
+	"strconv"
+)
+
+// DefaultManagementPort is the port used by the cluster manager (ns_server) REST API when no port is configured.
+const DefaultManagementPort = 8091
+
 // NodeBlueprint represents the configration for a Couchbase Cluster node.
 type NodeBlueprint struct {
 	Host     string `json:"host,omitempty" yaml:"host,omitempty"`
 	DataPath string `json:"-" yaml:"data_path,omitempty"`
+
+	// Port is the management port of the node, only required when attaching to an unmanaged cluster which isn't using
+	// the default port.
+	Port int `json:"-" yaml:"port,omitempty"`
+
+	// Services is the list of services which will be run on the node (see 'Services'), defaults to only the data
+	// service.
+	Services []string `json:"services,omitempty" yaml:"services,omitempty"`
+
+	// Local indicates that the node is the machine running 'cbtools-autobench', commands will be executed directly
+	// rather than via ssh.
+	Local bool `json:"local,omitempty" yaml:"local,omitempty"`
+
+	// SSH overrides the global ssh config when connecting to this node.
+	SSH *SSHConfig `json:"-" yaml:"ssh,omitempty"`
+
+	// Paths describes where Couchbase Server is installed on the node, populated from the cluster/backup client
+	// blueprint when connecting to the node.
+	Paths CBPaths `json:"-" yaml:"-"`
+}
+
+// DataDirectory returns the data path for the node, relative paths are resolved against the install directory. Returns
+// an empty string when no data path is configured, in which case the Couchbase Server default is used.
+func (n *NodeBlueprint) DataDirectory() string {
+	return n.Paths.Resolve(n.DataPath)
+}
+
+// ManagementAddress returns the 'host:port' address of the cluster manager REST API for the node.
+func (n *NodeBlueprint) ManagementAddress() string {
+	port := n.Port
+	if port == 0 {
+		port = DefaultManagementPort
+	}
+
+	return net.JoinHostPort(n.Host, strconv.Itoa(port))
 }
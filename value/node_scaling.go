@@ -0,0 +1,70 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import (
+	"fmt"
+	"slices"
+)
+
+// NodeScalingConfig describes a node count sweep, used by the 'scale' sub-command to benchmark the same dataset against
+// clusters of different sizes.
+type NodeScalingConfig struct {
+	// NodeCounts is the list of cluster sizes which will be benchmarked, defaults to every size from one node up to the
+	// number of nodes in the cluster blueprint.
+	NodeCounts []int `yaml:"node_counts,omitempty"`
+}
+
+// Counts returns the cluster sizes which should be benchmarked for a cluster with the given number of nodes, in the
+// order they should be run. The largest size is run first, since nodes are rebalanced out between each run.
+func (n *NodeScalingConfig) Counts(nodes int) ([]int, error) {
+	var counts []int
+
+	if n == nil || len(n.NodeCounts) == 0 {
+		for count := 1; count <= nodes; count++ {
+			counts = append(counts, count)
+		}
+	} else {
+		counts = slices.Clone(n.NodeCounts)
+	}
+
+	for _, count := range counts {
+		if count < 1 || count > nodes {
+			return nil, fmt.Errorf("node count %d is outside the range of 1-%d nodes in the cluster blueprint", count,
+				nodes)
+		}
+	}
+
+	slices.Sort(counts)
+	slices.Reverse(counts)
+
+	return slices.Compact(counts), nil
+}
+
+// NodeScalingResult is the outcome of benchmarking a single cluster size.
+type NodeScalingResult struct {
+	Nodes int
+
+	// Err is the error which caused scaling/benchmarking the cluster to fail, nil if it was successful.
+	Err error
+
+	// Results are the benchmark results for the cluster size, labelled using the number of nodes.
+	Results BenchmarkResults
+}
+
+// Name returns the name used to label the benchmark results for this cluster size.
+func (n *NodeScalingResult) Name() string {
+	return fmt.Sprintf("nodes=%d", n.Nodes)
+}
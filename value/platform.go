@@ -17,6 +17,8 @@ package value
 import (
 	"fmt"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
 // Platform represents the platform that 'cbtools-autobench' is currently being run against (note this is referring to
@@ -30,17 +32,114 @@ const (
 	// PlatformUbuntu20_04 represents the 20.04 release of Ubuntu.
 	PlatformUbuntu20_04 Platform = "ubuntu20.04"
 
+	// PlatformDebian11 represents the 11 (bullseye) release of Debian.
+	PlatformDebian11 Platform = "debian11"
+
+	// PlatformDebian12 represents the 12 (bookworm) release of Debian.
+	PlatformDebian12 Platform = "debian12"
+
 	// PlatformAmazonLinux2 represents the second version of Amazon Linux, note that the first version is now hidden
 	// from users and in theory should no longer be used.
 	PlatformAmazonLinux2 Platform = "amzn2"
+
+	// PlatformEnterpriseLinux8 represents the 8 release of Red Hat Enterprise Linux and its rebuilds (e.g. Rocky Linux
+	// and AlmaLinux).
+	PlatformEnterpriseLinux8 Platform = "el8"
+
+	// PlatformEnterpriseLinux9 represents the 9 release of Red Hat Enterprise Linux and its rebuilds (e.g. Rocky Linux
+	// and AlmaLinux).
+	PlatformEnterpriseLinux9 Platform = "el9"
+)
+
+var (
+	// CommandDistro is a command which outputs the distribution of the machine e.g. 'ubuntu'.
+	CommandDistro = NewCommand("cat /etc/os-release | grep '^ID=' | cut -c4-")
+
+	// CommandDistroLike is a command which outputs the distributions that the machines distribution is derived from
+	// e.g. 'rhel centos fedora', the output will be empty if there are none.
+	CommandDistroLike = NewCommand("cat /etc/os-release | grep '^ID_LIKE=' | cut -c9-")
+
+	// CommandRelease is a command which outputs the release of the distribution e.g. '20.04'.
+	CommandRelease = NewCommand("cat /etc/os-release | grep '^VERSION_ID=' | cut -c13- | rev | cut -c2- | rev")
 )
 
+// NewPlatform returns the platform for the given distribution/release, as output by '/etc/os-release'. When the
+// distribution isn't supported, the distributions it's derived from (the 'ID_LIKE' field) are tried in order.
+func NewPlatform(distro, like, release string) (Platform, error) {
+	// Do some cleanup since we don't always get uniform output
+	distro = strings.Trim(strings.TrimSpace(distro), `"`)
+	like = strings.Trim(strings.TrimSpace(like), `"`)
+	release = strings.TrimSpace(release)
+
+	for _, candidate := range append([]string{distro}, strings.Fields(like)...) {
+		switch candidate {
+		case "ubuntu":
+			return newUbuntuPlatform(release)
+		case "debian":
+			return newDebianPlatform(release)
+		case "amzn":
+			return newAmazonLinuxPlatform(release)
+		case "rhel", "rocky", "almalinux":
+			return newEnterpriseLinuxPlatform(release)
+		}
+	}
+
+	return "", errors.Errorf("unsupported distro '%s'", distro)
+}
+
+// newUbuntuPlatform returns the specific platform for the given Ubuntu release.
+func newUbuntuPlatform(release string) (Platform, error) {
+	switch release {
+	case "20.04":
+		return PlatformUbuntu20_04, nil
+	}
+
+	return "", errors.Errorf("unsupported ubuntu release '%s'", release)
+}
+
+// newDebianPlatform returns the specific platform for the given Debian release.
+func newDebianPlatform(release string) (Platform, error) {
+	switch release {
+	case "11":
+		return PlatformDebian11, nil
+	case "12":
+		return PlatformDebian12, nil
+	}
+
+	return "", errors.Errorf("unsupported debian release '%s'", release)
+}
+
+// newAmazonLinuxPlatform returns the specific platform for the given Amazon Linux release.
+func newAmazonLinuxPlatform(release string) (Platform, error) {
+	switch release {
+	case "2", "2023":
+		return PlatformAmazonLinux2, nil
+	}
+
+	return "", errors.Errorf("unsupported amazon linux release '%s'", release)
+}
+
+// newEnterpriseLinuxPlatform returns the specific platform for the given RHEL (or compatible) release, only the major
+// version is considered since minor releases are binary compatible e.g. '9.3'.
+func newEnterpriseLinuxPlatform(release string) (Platform, error) {
+	major, _, _ := strings.Cut(release, ".")
+
+	switch major {
+	case "8":
+		return PlatformEnterpriseLinux8, nil
+	case "9":
+		return PlatformEnterpriseLinux9, nil
+	}
+
+	return "", errors.Errorf("unsupported enterprise linux release '%s'", release)
+}
+
 // PackageExtension returns the extension used by this platforms package manager.
 func (p Platform) PackageExtension() string {
 	switch p {
-	case PlatformUbuntu20_04:
+	case PlatformUbuntu20_04, PlatformDebian11, PlatformDebian12:
 		return "deb"
-	case PlatformAmazonLinux2:
+	case PlatformAmazonLinux2, PlatformEnterpriseLinux8, PlatformEnterpriseLinux9:
 		return "rpm"
 	}
 
@@ -52,8 +151,29 @@ func (p Platform) Dependencies() []string {
 	switch p {
 	case PlatformUbuntu20_04:
 		return []string{"awscli", "libtinfo5"}
+	case PlatformDebian11, PlatformDebian12:
+		// Unlike Ubuntu, minimal Debian installs don't include 'curl' which is used to query the instance metadata
+		return []string{"awscli", "curl", "libtinfo5"}
 	case PlatformAmazonLinux2:
 		return []string{"awscli", "ncurses-compat-libs"}
+	case PlatformEnterpriseLinux8:
+		// Minimal installs don't include 'tar', which is used to archive the iteration logs
+		return []string{"awscli", "ncurses-compat-libs", "tar"}
+	case PlatformEnterpriseLinux9:
+		// The AWS CLI is packaged as 'awscli2' in EPEL 9, and 'libtinfo.so.5' is no longer required/available
+		return []string{"awscli2", "tar"}
+	}
+
+	panic(fmt.Sprintf("unsupported platform '%s'", p))
+}
+
+// NFSDependencies returns the packages required to mount NFS/EFS exports on the platform.
+func (p Platform) NFSDependencies() []string {
+	switch p {
+	case PlatformUbuntu20_04, PlatformDebian11, PlatformDebian12:
+		return []string{"nfs-common"}
+	case PlatformAmazonLinux2, PlatformEnterpriseLinux8, PlatformEnterpriseLinux9:
+		return []string{"nfs-utils"}
 	}
 
 	panic(fmt.Sprintf("unsupported platform '%s'", p))
@@ -62,10 +182,12 @@ func (p Platform) Dependencies() []string {
 // CommandInstallPackageAt returns a command which can be used to install the package at the provided path.
 func (p Platform) CommandInstallPackageAt(path string) Command {
 	switch p {
-	case PlatformUbuntu20_04:
+	case PlatformUbuntu20_04, PlatformDebian11, PlatformDebian12:
 		return NewCommand("dpkg -i %s", path)
 	case PlatformAmazonLinux2:
 		return NewCommand("yum install -y %s", path)
+	case PlatformEnterpriseLinux8, PlatformEnterpriseLinux9:
+		return NewCommand("dnf install -y %s", path)
 	}
 
 	panic(fmt.Sprintf("unsupported platform '%s'", p))
@@ -76,8 +198,41 @@ func (p Platform) CommandInstallPackages(packages ...string) Command {
 	switch p {
 	case PlatformUbuntu20_04:
 		return NewCommand("apt update && apt install -y %s", strings.Join(packages, " "))
+	case PlatformDebian11, PlatformDebian12:
+		// Debian may prompt (e.g. when configuring packages) unless explicitly told not to
+		return NewCommand("apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install -y %s",
+			strings.Join(packages, " "))
 	case PlatformAmazonLinux2:
 		return NewCommand("yum update -y && yum install -y %s", strings.Join(packages, " "))
+	case PlatformEnterpriseLinux8, PlatformEnterpriseLinux9:
+		return NewCommand("dnf makecache && dnf install -y %s", strings.Join(packages, " "))
+	}
+
+	panic(fmt.Sprintf("unsupported platform '%s'", p))
+}
+
+// CommandInstallPackagesFrom returns a command which can be used to install all the packages in the given directory
+// without using any remote repositories, allowing provisioning machines without internet access.
+func (p Platform) CommandInstallPackagesFrom(dir string) Command {
+	switch p {
+	case PlatformUbuntu20_04, PlatformDebian11, PlatformDebian12:
+		return NewCommand("dpkg -i %s/*.deb", dir)
+	case PlatformAmazonLinux2:
+		return NewCommand("yum install -y --disablerepo='*' %s/*.rpm", dir)
+	case PlatformEnterpriseLinux8, PlatformEnterpriseLinux9:
+		return NewCommand("dnf install -y --disablerepo='*' %s/*.rpm", dir)
+	}
+
+	panic(fmt.Sprintf("unsupported platform '%s'", p))
+}
+
+// CommandPackageInstalled returns a command which will succeed only if the package with the given name is installed.
+func (p Platform) CommandPackageInstalled(name string) Command {
+	switch p {
+	case PlatformUbuntu20_04, PlatformDebian11, PlatformDebian12:
+		return NewCommand("dpkg-query -W -f='${Status}' %s | grep -q 'install ok installed'", name)
+	case PlatformAmazonLinux2, PlatformEnterpriseLinux8, PlatformEnterpriseLinux9:
+		return NewCommand("rpm -q %s", name)
 	}
 
 	panic(fmt.Sprintf("unsupported platform '%s'", p))
@@ -86,10 +241,12 @@ func (p Platform) CommandInstallPackages(packages ...string) Command {
 // CommandUninstallPackages returns a command which can be used to uninstall the provided list of package by name.
 func (p Platform) CommandUninstallPackages(packages ...string) Command {
 	switch p {
-	case PlatformUbuntu20_04:
+	case PlatformUbuntu20_04, PlatformDebian11, PlatformDebian12:
 		return NewCommand("dpkg --purge %s", strings.Join(packages, " "))
 	case PlatformAmazonLinux2:
 		return NewCommand("yum autoremove -y %s", strings.Join(packages, " "))
+	case PlatformEnterpriseLinux8, PlatformEnterpriseLinux9:
+		return NewCommand("dnf remove -y %s", strings.Join(packages, " "))
 	}
 
 	panic(fmt.Sprintf("unsupported platform '%s'", p))
@@ -98,7 +255,8 @@ func (p Platform) CommandUninstallPackages(packages ...string) Command {
 // CommandDisableCouchbase returns a command which when executed on the remote machine will disable Couchbase Server.
 func (p Platform) CommandDisableCouchbase() Command {
 	switch p {
-	case PlatformUbuntu20_04, PlatformAmazonLinux2:
+	case PlatformUbuntu20_04, PlatformDebian11, PlatformDebian12, PlatformAmazonLinux2, PlatformEnterpriseLinux8,
+		PlatformEnterpriseLinux9:
 		return NewCommand("systemctl disable --now couchbase-server")
 	}
 
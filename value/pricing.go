@@ -0,0 +1,44 @@
+// Copyright 2022 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+// bytesPerGiB is the number of bytes in a gibibyte, object storage is typically priced per GiB stored.
+const bytesPerGiB = 1 << 30
+
+// CloudPricing encapsulates the object store pricing used to estimate the cost of each benchmark iteration, all prices
+// are in the same (arbitrary) currency.
+type CloudPricing struct {
+	// PutPer1000 is the price of 1000 PUT (write) requests.
+	PutPer1000 float64 `json:"put_per_1000,omitempty" yaml:"put_per_1000,omitempty"`
+
+	// GetPer1000 is the price of 1000 GET (read) requests.
+	GetPer1000 float64 `json:"get_per_1000,omitempty" yaml:"get_per_1000,omitempty"`
+
+	// StoragePerGiBMonth is the price of storing a GiB of data for a month.
+	StoragePerGiBMonth float64 `json:"storage_per_gib_month,omitempty" yaml:"storage_per_gib_month,omitempty"`
+}
+
+// RequestCost returns the estimated cost of the requests made to the object store by the given benchmark, LIST requests
+// are charged at the same price as PUT requests (as they are for S3).
+func (c *CloudPricing) RequestCost(result *BenchmarkResult) float64 {
+	return float64(result.PutRequests+result.ListRequests)/1000*c.PutPer1000 +
+		float64(result.GetRequests)/1000*c.GetPer1000
+}
+
+// StorageCost returns the estimated monthly cost of storing the data written to the object store by the given
+// benchmark.
+func (c *CloudPricing) StorageCost(result *BenchmarkResult) float64 {
+	return float64(result.DiskSize) / bytesPerGiB * c.StoragePerGiBMonth
+}
@@ -21,3 +21,26 @@ package value
 // Group 1: 7.0.0
 // Group 2: 4259
 const RegexBuildID = `(\d+\.\d+\.\d+)-(\d+)`
+
+// RegexPingRTT is an uncompiled regular expression which may be used to extract the average round trip time from the
+// summary output by 'ping'.
+//
+// Full match: = 0.030/0.045/0.061/0.012 ms
+// Group 1: 0.045
+const RegexPingRTT = `= [\d.]+/([\d.]+)/[\d.]+/[\d.]+ ms`
+
+// RegexS3Summary is an uncompiled regular expression which may be used to extract the object count/size from the
+// summary output by 'aws s3 ls --summarize'.
+//
+// Full match: Total Objects: 1024\n   Total Size: 1073741824
+// Group 1: 1024
+// Group 2: 1073741824
+const RegexS3Summary = `Total Objects: (\d+)\s+Total Size: (\d+)`
+
+// RegexS3Request is an uncompiled regular expression which may be used to extract the number of requests made for an
+// S3 operation from the counted debug logs of the S3 SDK.
+//
+// Full match: 42 Request s3/PutObject
+// Group 1: 42
+// Group 2: PutObject
+const RegexS3Request = `(\d+) Request s3/(\w+)`
@@ -0,0 +1,92 @@
+// Copyright 2022 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import "slices"
+
+// S3LogLevelDebug is the S3 SDK log level which logs each request made to the object store.
+const S3LogLevelDebug = "debug"
+
+var (
+	// s3PutOperations are the S3 operations which are billed as PUT requests.
+	s3PutOperations = []string{
+		"PutObject", "UploadPart", "CreateMultipartUpload", "CompleteMultipartUpload", "CopyObject", "DeleteObjects",
+	}
+
+	// s3GetOperations are the S3 operations which are billed as GET requests.
+	s3GetOperations = []string{"GetObject", "HeadObject"}
+
+	// s3ListOperations are the S3 operations which list the contents of a bucket.
+	s3ListOperations = []string{"ListObjects", "ListObjectsV2", "ListMultipartUploads", "ListParts"}
+)
+
+// S3Requests is the number of requests made to the object store for each S3 operation e.g. 'PutObject'.
+type S3Requests map[string]uint64
+
+// Sub returns the requests which were made since the given snapshot was taken. Logs may be rotated between snapshots,
+// in which case the current count is used as is.
+func (s S3Requests) Sub(before S3Requests) S3Requests {
+	diff := make(S3Requests, len(s))
+
+	for operation, count := range s {
+		if count >= before[operation] {
+			diff[operation] = count - before[operation]
+		} else {
+			diff[operation] = count
+		}
+	}
+
+	return diff
+}
+
+// Put returns the number of requests made using operations which are billed as PUT requests.
+func (s S3Requests) Put() uint64 {
+	return s.count(s3PutOperations)
+}
+
+// Get returns the number of requests made using operations which are billed as GET requests.
+func (s S3Requests) Get() uint64 {
+	return s.count(s3GetOperations)
+}
+
+// List returns the number of requests made using operations which list the contents of a bucket.
+func (s S3Requests) List() uint64 {
+	return s.count(s3ListOperations)
+}
+
+// Other returns the number of requests made using any other operation.
+func (s S3Requests) Other() uint64 {
+	var total uint64
+
+	for operation, count := range s {
+		if !slices.Contains(s3PutOperations, operation) && !slices.Contains(s3GetOperations, operation) &&
+			!slices.Contains(s3ListOperations, operation) {
+			total += count
+		}
+	}
+
+	return total
+}
+
+// count returns the total number of requests made using the given operations.
+func (s S3Requests) count(operations []string) uint64 {
+	var total uint64
+
+	for _, operation := range operations {
+		total += s[operation]
+	}
+
+	return total
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import "time"
+
+const (
+	// DefaultTLSManagementPort is the port used by the cluster manager (ns_server) REST API when using TLS and no port
+	// is configured.
+	DefaultTLSManagementPort = 18091
+
+	// DefaultRESTTimeout is the timeout for requests to the REST API when no timeout is configured.
+	DefaultRESTTimeout = time.Minute
+
+	// DefaultRESTRetries is the number of times a failed request to the REST API is retried when not configured.
+	DefaultRESTRetries = 3
+)
+
+// RESTConfig configures how requests are made to the cluster manager (ns_server) REST API.
+type RESTConfig struct {
+	// TLS indicates that requests should be made using HTTPS, note that the certificate presented by the cluster isn't
+	// verified.
+	TLS bool `yaml:"tls,omitempty"`
+
+	// Port is the port used to make requests to the REST API, when unset the default port for the scheme is used. The
+	// port of an unmanaged node takes precedence.
+	Port int `yaml:"port,omitempty"`
+
+	// Timeout is the number of seconds after which a request will be aborted, when unset a minute is used.
+	Timeout int `yaml:"timeout,omitempty"`
+
+	// Retries is the number of times an idempotent (i.e. non-POST) request will be retried after a network error or
+	// server error, when unset requests are retried three times. A negative value disables retries.
+	Retries int `yaml:"retries,omitempty"`
+}
+
+// Scheme returns the scheme which should be used when making requests to the REST API.
+func (r *RESTConfig) Scheme() string {
+	if r != nil && r.TLS {
+		return "https"
+	}
+
+	return "http"
+}
+
+// GetPort returns the port which should be used when making requests to the REST API.
+func (r *RESTConfig) GetPort() int {
+	switch {
+	case r != nil && r.Port != 0:
+		return r.Port
+	case r != nil && r.TLS:
+		return DefaultTLSManagementPort
+	default:
+		return DefaultManagementPort
+	}
+}
+
+// GetTimeout returns the timeout for requests to the REST API.
+func (r *RESTConfig) GetTimeout() time.Duration {
+	if r == nil || r.Timeout == 0 {
+		return DefaultRESTTimeout
+	}
+
+	return time.Duration(r.Timeout) * time.Second
+}
+
+// GetRetries returns the number of times a failed request to the REST API should be retried.
+func (r *RESTConfig) GetRetries() int {
+	switch {
+	case r == nil || r.Retries == 0:
+		return DefaultRESTRetries
+	case r.Retries < 0:
+		return 0
+	default:
+		return r.Retries
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+// ScheduleConfig encapsulates the configuration used by the 'schedule' sub-command to run recurring benchmarks.
+type ScheduleConfig struct {
+	// HistoryPath is the directory which the JSON report for each scheduled run will be stored in.
+	HistoryPath string `yaml:"history_path,omitempty"`
+
+	// NotifyURL is an endpoint which will be sent a POST request containing the outcome of each scheduled run.
+	NotifyURL string `yaml:"notify_url,omitempty"`
+
+	// Suites is the list of benchmark suites which will be run on a schedule.
+	Suites []*SuiteSchedule `yaml:"suites,omitempty"`
+}
+
+// SuiteSchedule describes a benchmark suite which will be run whenever the cron expression matches.
+type SuiteSchedule struct {
+	// Name is a unique name for the suite, used to identify the suite in the logs, history and notifications.
+	Name string `json:"name" yaml:"name,omitempty"`
+
+	// Cron is a standard five field cron expression e.g. '0 2 * * *' to run nightly at 02:00.
+	Cron string `json:"cron" yaml:"cron,omitempty"`
+
+	// Benchmark is the benchmark which will be run i.e. backup/restore.
+	Benchmark string `json:"benchmark" yaml:"benchmark,omitempty"`
+
+	// Steps is an ordered list of benchmarks/topology changes which will be run in place of the benchmark above,
+	// allowing the cluster topology to be changed between benchmarks without reprovisioning.
+	Steps []*SuiteStep `json:"steps,omitempty" yaml:"steps,omitempty"`
+}
+
+// Entries returns the steps which should be run for the suite, a suite without any steps runs its benchmark.
+func (s *SuiteSchedule) Entries() []*SuiteStep {
+	if len(s.Steps) == 0 {
+		return []*SuiteStep{{Benchmark: s.Benchmark}}
+	}
+
+	return s.Steps
+}
+
+// SuiteStep is a single step in a benchmark suite, which either runs a benchmark or changes the cluster topology.
+type SuiteStep struct {
+	// Name is used to label the results of a benchmark step in the report, defaults to the position of the step.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Benchmark is the benchmark which will be run i.e. backup/restore.
+	Benchmark string `json:"benchmark,omitempty" yaml:"benchmark,omitempty"`
+
+	// Topology is the change which will be made to the cluster topology.
+	Topology *TopologyChange `json:"topology,omitempty" yaml:"topology,omitempty"`
+}
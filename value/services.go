@@ -0,0 +1,131 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ServiceData is the data (KV) service, which hosts the buckets.
+	ServiceData = "data"
+
+	// ServiceIndex is the index service.
+	ServiceIndex = "index"
+
+	// ServiceQuery is the query service.
+	ServiceQuery = "query"
+
+	// ServiceFTS is the full text search service.
+	ServiceFTS = "fts"
+
+	// ServiceEventing is the eventing service.
+	ServiceEventing = "eventing"
+
+	// ServiceAnalytics is the analytics service.
+	ServiceAnalytics = "analytics"
+
+	// ServiceBackup is the backup service.
+	ServiceBackup = "backup"
+)
+
+// Services is the list of services which may be run on the cluster nodes.
+var Services = []string{
+	ServiceData, ServiceIndex, ServiceQuery, ServiceFTS, ServiceEventing, ServiceAnalytics, ServiceBackup,
+}
+
+// ServiceQuotas are the memory quotas in megabytes for the services other than the data service (whose quota is
+// configured using 'ram_quota_mb'/'ram_quota_percentage'), zero values use the Couchbase Server defaults.
+type ServiceQuotas struct {
+	IndexMB     uint64 `json:"index_mb,omitempty" yaml:"index_mb,omitempty"`
+	QueryMB     uint64 `json:"query_mb,omitempty" yaml:"query_mb,omitempty"`
+	FTSMB       uint64 `json:"fts_mb,omitempty" yaml:"fts_mb,omitempty"`
+	EventingMB  uint64 `json:"eventing_mb,omitempty" yaml:"eventing_mb,omitempty"`
+	AnalyticsMB uint64 `json:"analytics_mb,omitempty" yaml:"analytics_mb,omitempty"`
+}
+
+// Flags returns the 'cluster-init' flags which set the configured service quotas.
+//
+// NOTE: The query service quota is only supported by Couchbase Server 7.6+.
+func (s *ServiceQuotas) Flags() string {
+	if s == nil {
+		return ""
+	}
+
+	var flags string
+
+	for _, quota := range []struct {
+		flag string
+		mb   uint64
+	}{
+		{flag: "--cluster-index-ramsize", mb: s.IndexMB},
+		{flag: "--cluster-query-ramsize", mb: s.QueryMB},
+		{flag: "--cluster-fts-ramsize", mb: s.FTSMB},
+		{flag: "--cluster-eventing-ramsize", mb: s.EventingMB},
+		{flag: "--cluster-analytics-ramsize", mb: s.AnalyticsMB},
+	} {
+		if quota.mb != 0 {
+			flags += fmt.Sprintf(" %s %d", quota.flag, quota.mb)
+		}
+	}
+
+	return flags
+}
+
+// GetServices returns the services which will be run on the node, by default only the data service is run.
+func (n *NodeBlueprint) GetServices() []string {
+	if len(n.Services) == 0 {
+		return []string{ServiceData}
+	}
+
+	return n.Services
+}
+
+// HasService returns a boolean indicating whether the given service will be run on the node.
+func (n *NodeBlueprint) HasService(service string) bool {
+	return slices.Contains(n.GetServices(), service)
+}
+
+// ServicesFlag returns the comma separated list of services in the format expected by 'couchbase-cli'.
+func (n *NodeBlueprint) ServicesFlag() string {
+	return strings.Join(n.GetServices(), ",")
+}
+
+// ValidateServices returns an error if any of the nodes are configured to run an unknown service, or if none of the
+// nodes are running the data service (which is required to host the benchmarking bucket).
+func ValidateServices(nodes []*NodeBlueprint) error {
+	var data bool
+
+	for _, node := range nodes {
+		for _, service := range node.GetServices() {
+			if !slices.Contains(Services, service) {
+				return errors.Errorf("node '%s' has unknown service '%s', expected one of %s", node.Host, service,
+					strings.Join(Services, "/"))
+			}
+		}
+
+		data = data || node.HasService(ServiceData)
+	}
+
+	if !data {
+		return errors.New("at least one node must run the data service")
+	}
+
+	return nil
+}
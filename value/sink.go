@@ -0,0 +1,51 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+// SinkType represents a destination which a benchmarking report may be written to.
+type SinkType string
+
+const (
+	// SinkStdout writes the report to stdout, either in a human readable form or as JSON.
+	SinkStdout SinkType = "stdout"
+
+	// SinkJSON writes the JSON report to a file on the local machine.
+	SinkJSON SinkType = "json"
+
+	// SinkHTML writes a standalone HTML version of the report to a file on the local machine.
+	SinkHTML SinkType = "html"
+
+	// SinkPrometheus writes the report in the Prometheus text exposition format, this is intended to be picked up by
+	// the node exporter textfile collector.
+	SinkPrometheus SinkType = "prometheus"
+
+	// SinkWebhook sends the JSON report to a remote endpoint using a POST request.
+	SinkWebhook SinkType = "webhook"
+)
+
+// SinkConfig encapsulates the configuration for a single report sink.
+type SinkConfig struct {
+	// Type is the type of sink, see the 'SinkType' constants for the supported values.
+	Type SinkType `yaml:"type,omitempty"`
+
+	// Path is the path to the file which will be written by file based sinks.
+	Path string `yaml:"path,omitempty"`
+
+	// URL is the endpoint which the webhook sink will POST the report to.
+	URL string `yaml:"url,omitempty"`
+
+	// JSON indicates whether the stdout sink should output the report in JSON format.
+	JSON bool `yaml:"json,omitempty"`
+}
@@ -15,9 +15,62 @@
 package value
 
 // SSHConfig encapsulates the SSH config accepted by 'cbtools-autobench'. This will be used when connecting to remote
-// hosts. The same config will be used to connect to each server.
+// hosts. The same config will be used to connect to each server, unless overridden by an individual node/backup client.
 type SSHConfig struct {
 	Username             string `yaml:"username,omitempty"`
 	PrivateKey           string `yaml:"private_key,omitempty"`
 	PrivateKeyPassphrase string `yaml:"private_key_passphrase,omitempty"`
+
+	// Port is the port used when connecting to remote hosts, when omitted the default ssh port is used.
+	Port int `yaml:"port,omitempty"`
+}
+
+// GetPort returns the port which should be used when connecting to remote hosts.
+func (s *SSHConfig) GetPort() int {
+	if s.Port == 0 {
+		return 22
+	}
+
+	return s.Port
+}
+
+// Override returns a copy of the config where any fields set in the provided override take precedence, allowing nodes
+// to be configured individually (e.g. in labs where nodes use different images).
+func (s *SSHConfig) Override(override *SSHConfig) *SSHConfig {
+	var config SSHConfig
+	if s != nil {
+		config = *s
+	}
+
+	if override == nil {
+		return &config
+	}
+
+	if override.Username != "" {
+		config.Username = override.Username
+	}
+
+	// The passphrase belongs to the key, so it's overridden alongside it
+	if override.PrivateKey != "" {
+		config.PrivateKey = override.PrivateKey
+		config.PrivateKeyPassphrase = override.PrivateKeyPassphrase
+	}
+
+	if override.Port != 0 {
+		config.Port = override.Port
+	}
+
+	return &config
+}
+
+// Redacted returns a copy of the config where the private key passphrase has been redacted.
+func (s *SSHConfig) Redacted() *SSHConfig {
+	if s == nil {
+		return nil
+	}
+
+	config := *s
+	config.PrivateKeyPassphrase = redact(config.PrivateKeyPassphrase)
+
+	return &config
 }
@@ -0,0 +1,35 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+// RunState is the state of a run which is persisted to disk, allowing an interrupted run to be resumed from the last
+// completed step/iteration rather than starting from scratch.
+type RunState struct {
+	// ConfigHash is the hash of the config used for the run, a run may only be resumed using the same config.
+	ConfigHash string `json:"config_hash"`
+
+	// Mode is the benchmark which was being run, empty when only provisioning.
+	Mode string `json:"mode,omitempty"`
+
+	// Provisioned/Loaded indicate whether the cluster/backup client have been provisioned and the dataset loaded.
+	Provisioned bool `json:"provisioned,omitempty"`
+	Loaded      bool `json:"loaded,omitempty"`
+
+	// Deleted indicates whether the configured fraction of the dataset has been deleted prior to benchmarking.
+	Deleted bool `json:"deleted,omitempty"`
+
+	// Results contains the results for each of the completed benchmark iterations.
+	Results BenchmarkResults `json:"results,omitempty"`
+}
@@ -34,17 +34,38 @@ type Stats struct {
 	DiskUsed               uint64 `json:"diskUsed"`
 	MemUsed                uint64 `json:"memUsed"`
 	VBActiveNumNonResident uint64 `json:"vbActiveNumNonResident"`
+
+	// RAMQuota is the actual quota of the bucket across the whole cluster, this isn't part of the basic stats so is
+	// populated separately.
+	RAMQuota uint64 `json:"-"`
+
+	// VBuckets is the effective number of vBuckets in the bucket, this isn't part of the basic stats so is populated
+	// separately.
+	VBuckets int `json:"-"`
+}
+
+// statsJSON is the JSON representation of the stats which is included in the report.
+type statsJSON struct {
+	ItemCount      uint64 `json:"item_count,omitempty"`
+	MemoryUsed     string `json:"memory_used,omitempty"`
+	DiskUsed       string `json:"disk_used,omitempty"`
+	ResidencyRatio uint64 `json:"residency_ratio,omitempty"`
+	RAMQuota       string `json:"ram_quota,omitempty"`
+	VBuckets       int    `json:"vbuckets,omitempty"`
+}
+
+// JSONShape returns a value with the same shape as the JSON representation of the stats, used to generate the schema
+// of the report.
+func (b *Stats) JSONShape() any {
+	return statsJSON{}
 }
 
 // MarshalJSON returns a JSON representation of the stats with raw values converted into human readable strings.
 func (b *Stats) MarshalJSON() ([]byte, error) {
-	return json.Marshal(struct {
-		ItemCount      uint64 `json:"item_count,omitempty"`
-		MemoryUsed     string `json:"memory_used,omitempty"`
-		DiskUsed       string `json:"disk_used,omitempty"`
-		ResidencyRatio uint64 `json:"residency_ratio,omitempty"`
-	}{
+	return json.Marshal(statsJSON{
 		ItemCount:      b.ItemCount,
+		VBuckets:       b.VBuckets,
+		RAMQuota:       format.Bytes(b.RAMQuota),
 		MemoryUsed:     format.Bytes(b.MemUsed),
 		DiskUsed:       format.Bytes(b.DiskUsed),
 		ResidencyRatio: residencyRatio(b.ItemCount, b.VBActiveNumNonResident),
@@ -59,9 +80,11 @@ func (b *Stats) String() string {
 	)
 
 	fmt.Fprintln(buffer, "| Stats\n| -----")
-	fmt.Fprintf(writer, "| Item Count\t Memory Used\t Disk Used\t Residency Ratio\t\n")
-	fmt.Fprintf(writer, "| %s\t %s\t %s\t %d%%\t\n",
+	fmt.Fprintf(writer, "| Item Count\t vBuckets\t RAM Quota\t Memory Used\t Disk Used\t Residency Ratio\t\n")
+	fmt.Fprintf(writer, "| %s\t %d\t %s\t %s\t %s\t %d%%\t\n",
 		message.NewPrinter(language.English).Sprintf("%d", b.ItemCount),
+		b.VBuckets,
+		format.Bytes(b.RAMQuota),
 		format.Bytes(b.MemUsed),
 		format.Bytes(b.DiskUsed),
 		residencyRatio(b.ItemCount, b.VBActiveNumNonResident))
@@ -71,6 +94,60 @@ func (b *Stats) String() string {
 	return strings.TrimSpace(buffer.String())
 }
 
+// BucketStats encapsulates the stats for a single bucket in the cluster, along with the breakdown for each node.
+type BucketStats struct {
+	Name  string       `json:"name"`
+	Stats *Stats       `json:"stats"`
+	Nodes []*NodeStats `json:"nodes,omitempty"`
+}
+
+// NodeStats encapsulates the stats for the portion of a bucket which is hosted on a single node.
+type NodeStats struct {
+	Host  string `json:"host"`
+	Stats *Stats `json:"stats"`
+}
+
+// ClusterStats is the list of stats for each bucket in the cluster.
+type ClusterStats []*BucketStats
+
+// String returns a string representation of the stats for each bucket, where each bucket is followed by the breakdown
+// for each node.
+func (c ClusterStats) String() string {
+	var (
+		buffer  = &bytes.Buffer{}
+		writer  = tabwriter.NewWriter(buffer, 4, 0, 1, ' ', tabwriter.Debug)
+		printer = message.NewPrinter(language.English)
+	)
+
+	fmt.Fprintln(buffer, "| Stats\n| -----")
+	fmt.Fprintf(writer, "| Bucket\t Node\t Item Count\t vBuckets\t RAM Quota\t Memory Used\t Disk Used\t "+
+		"Residency Ratio\t\n")
+
+	for _, bucket := range c {
+		fmt.Fprintf(writer, "| %s\t \t %s\t %d\t %s\t %s\t %s\t %d%%\t\n",
+			bucket.Name,
+			printer.Sprintf("%d", bucket.Stats.ItemCount),
+			bucket.Stats.VBuckets,
+			format.Bytes(bucket.Stats.RAMQuota),
+			format.Bytes(bucket.Stats.MemUsed),
+			format.Bytes(bucket.Stats.DiskUsed),
+			residencyRatio(bucket.Stats.ItemCount, bucket.Stats.VBActiveNumNonResident))
+
+		for _, node := range bucket.Nodes {
+			fmt.Fprintf(writer, "| \t %s\t %s\t \t \t %s\t %s\t %d%%\t\n",
+				node.Host,
+				printer.Sprintf("%d", node.Stats.ItemCount),
+				format.Bytes(node.Stats.MemUsed),
+				format.Bytes(node.Stats.DiskUsed),
+				residencyRatio(node.Stats.ItemCount, node.Stats.VBActiveNumNonResident))
+		}
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSpace(buffer.String())
+}
+
 // residencyRatio returns the current residency ratio using the same method as in the Couchbase Server WebUI.
 func residencyRatio(items, nonResident uint64) uint64 {
 	if items == 0 {
@@ -0,0 +1,96 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// TLSConfig configures TLS for a managed cluster. During provisioning a certificate is generated for each node, signed
+// by the configured CA (or a generated self-signed CA), and the CA is uploaded as a trusted CA for the cluster.
+type TLSConfig struct {
+	// CACertPath/CAKeyPath are local paths to the PEM encoded CA certificate/private key which will be used to sign the
+	// node certificates. When unset a self-signed CA is generated, which is discarded once provisioning completes.
+	CACertPath string `json:"-" yaml:"ca_cert_path,omitempty"`
+	CAKeyPath  string `json:"-" yaml:"ca_key_path,omitempty"`
+
+	// ClientAuth enables client certificate authentication, allowing 'cbbackupmgr' to authenticate using a client
+	// certificate signed by the CA whose common name is the username. Authenticating using a certificate remains
+	// optional, since autobench itself authenticates using the cluster credentials.
+	ClientAuth bool `json:"-" yaml:"client_auth,omitempty"`
+}
+
+// Validate returns an error if the TLS config is invalid, a nil config (i.e. TLS disabled) is always valid.
+func (t *TLSConfig) Validate() error {
+	if t == nil {
+		return nil
+	}
+
+	if (t.CACertPath == "") != (t.CAKeyPath == "") {
+		return errors.New("both 'ca_cert_path' and 'ca_key_path' must be provided")
+	}
+
+	// There'd be no way to sign the client certificates, since the generated CA is discarded
+	if t.ClientAuth && t.GeneratedCA() {
+		return errors.New("client certificate authentication requires 'ca_cert_path'/'ca_key_path'")
+	}
+
+	return nil
+}
+
+// GeneratedCA returns a boolean indicating whether a self-signed CA will be generated to sign the node certificates.
+func (t *TLSConfig) GeneratedCA() bool {
+	return t.CACertPath == "" && t.CAKeyPath == ""
+}
+
+// TLSEnabled returns a boolean indicating whether the cluster should be interacted with using TLS i.e. whether the
+// 'couchbase-cli'/REST API interactions should use HTTPS.
+func (c *ClusterBlueprint) TLSEnabled() bool {
+	return c.TLS != nil || (c.REST != nil && c.REST.TLS)
+}
+
+// RESTConfig returns the config used to make requests to the REST API, which will use HTTPS when TLS is enabled.
+func (c *ClusterBlueprint) RESTConfig() *RESTConfig {
+	if !c.TLSEnabled() {
+		return c.REST
+	}
+
+	config := RESTConfig{}
+	if c.REST != nil {
+		config = *c.REST
+	}
+
+	config.TLS = true
+
+	return &config
+}
+
+// ManagementURL returns the address of the cluster manager for the node in the format expected by 'couchbase-cli',
+// using HTTPS (and the default TLS port, if no port is configured) when requested.
+func (n *NodeBlueprint) ManagementURL(tls bool) string {
+	if !tls {
+		return n.ManagementAddress()
+	}
+
+	port := n.Port
+	if port == 0 {
+		port = DefaultTLSManagementPort
+	}
+
+	return "https://" + net.JoinHostPort(n.Host, strconv.Itoa(port))
+}
@@ -0,0 +1,38 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+import "slices"
+
+// TopologyChange describes a change to the nodes in the cluster, which is applied using a single rebalance. Adding and
+// removing nodes in the same change performs a swap rebalance, whilst an empty change just rebalances the cluster.
+type TopologyChange struct {
+	// Add is the list of nodes which will be provisioned then added into the cluster using their configured
+	// services (data by default).
+	Add []*NodeBlueprint `json:"add,omitempty" yaml:"add,omitempty"`
+
+	// Remove is the list of hosts which will be removed from the cluster, the first node in the cluster can't be
+	// removed since it's used to orchestrate the cluster.
+	Remove []string `json:"remove,omitempty" yaml:"remove,omitempty"`
+}
+
+// Apply returns the nodes which will be in the cluster once the change has been applied to the given nodes.
+func (t *TopologyChange) Apply(nodes []*NodeBlueprint) []*NodeBlueprint {
+	applied := slices.DeleteFunc(slices.Clone(nodes), func(node *NodeBlueprint) bool {
+		return slices.Contains(t.Remove, node.Host)
+	})
+
+	return append(applied, t.Add...)
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 Couchbase Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package value
+
+// VolumeBlueprint describes the expected characteristics of the AWS EBS volume backing a path on a machine, the volume
+// will be validated (and optionally modified) during provisioning.
+type VolumeBlueprint struct {
+	// Path is a path on the machine which is backed by the volume e.g. the data path or archive.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Type is the expected volume type e.g. gp3/io2, an empty value will accept any type.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// IOPS is the expected number of provisioned IOPS, a zero value will accept any number of IOPS.
+	IOPS uint64 `json:"iops,omitempty" yaml:"iops,omitempty"`
+
+	// Throughput is the expected provisioned throughput in MiB/s, a zero value will accept any throughput.
+	Throughput uint64 `json:"throughput,omitempty" yaml:"throughput,omitempty"`
+
+	// Modify indicates that volumes which don't match the blueprint should be modified, rather than failing
+	// provisioning.
+	Modify bool `json:"modify,omitempty" yaml:"modify,omitempty"`
+}
+
+// Matches returns a boolean indicating whether the given volume matches the blueprint.
+func (v *VolumeBlueprint) Matches(volume *Volume) bool {
+	return (v.Type == "" || v.Type == volume.Type) &&
+		(v.IOPS == 0 || v.IOPS == volume.IOPS) &&
+		(v.Throughput == 0 || v.Throughput == volume.Throughput)
+}
+
+// Volume encapsulates the characteristics of the AWS EBS volume backing a path on a machine.
+type Volume struct {
+	Host       string `json:"host"`
+	Path       string `json:"path"`
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Size       uint64 `json:"size_gib"`
+	IOPS       uint64 `json:"iops,omitempty"`
+	Throughput uint64 `json:"throughput_mibs,omitempty"`
+}